@@ -1,16 +1,24 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/sirupsen/logrus"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
 	_ "simple-product-api/docs"
-	"simple-product-api/pkg/common"
 	"simple-product-api/pkg/config"
 	"simple-product-api/pkg/di"
+	"simple-product-api/pkg/metrics"
 	middleware "simple-product-api/pkg/midlleware"
-	"time"
 )
 
 // @title Simple Product API
@@ -19,38 +27,113 @@ import (
 // @host localhost:8080
 // @BasePath /
 // @schemes http
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT or API key bearer token, sent as "Bearer <token>".
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key for machine clients.
 
 func main() {
-	cfg := config.Load()
-	logrus.Info("Server starting...")
+	args := os.Args[1:]
+	if len(args) >= 2 && args[0] == "config" && args[1] == "print" {
+		os.Exit(printConfig(args[2:]))
+	}
+
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		logrus.Fatalf("invalid configuration:\n%v", err)
+	}
 
-	handler, err := di.InitializeHandler(cfg)
+	deps, err := di.InitializeApp(cfg)
 	if err != nil {
-		logrus.Fatalf("failed to initialize product handler: %v", err)
+		// The injected logger may be what failed to build.
+		logrus.Fatalf("failed to initialize handlers: %v", err)
+	}
+	log := deps.Log
+	log.Info("server starting, handlers initialized")
+
+	if !deps.Verifier.Enabled() {
+		log.Warn("no JWT signing keys configured, only API keys will be accepted")
 	}
-	logrus.Info("intialize product handler successfully")
 
 	app := fiber.New(fiber.Config{
 		ErrorHandler: middleware.ErrorHandler,
+		ReadTimeout:  cfg.ServerReadTimeout,
+		WriteTimeout: cfg.ServerWriteTimeout,
+		IdleTimeout:  cfg.ServerIdleTimeout,
+		BodyLimit:    cfg.ServerBodyLimit,
 	})
-	logrus.Info("retry logic is set")
-	app.Use(middleware.RetryWithTimeout(3*time.Second, 2))
+	app.Use(middleware.RequestID())
+	app.Use(middleware.Tracing())
+	app.Use(middleware.Metrics())
+	log.Info("request timeouts are set")
+	app.Use(middleware.Timeout(deps.Timeouts))
+	log.Info("rate limiting is set")
+	app.Use(middleware.RateLimitIP(deps.Limiter, deps.Limits, deps.Log))
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
+	app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
+	deps.Health.Register(app)
+
+	api := app.Group("/api/v1",
+		middleware.Authenticate(deps.Verifier, deps.Keys),
+		middleware.ResolveTenant(),
+		middleware.RateLimit(deps.Limiter, deps.Limits, deps.Log),
+		middleware.Idempotency(deps.Idem, cfg.CacheWriteTimeout, deps.Log),
+	)
+	deps.Products.Register(api.Group("/products"))
+	deps.APIKeys.Register(api.Group("/admin/api-keys"))
+	deps.Products.RegisterCacheAdmin(api.Group("/admin/cache"))
+
+	listenErr := make(chan error, 1)
+	go func() { listenErr <- app.Listen(":" + cfg.ServerPort) }()
 
-	logrus.Info("rate limiting is set")
-	app.Use(limiter.New(limiter.Config{
-		Max:        20,
-		Expiration: 1 * time.Minute,
-		LimitReached: func(c *fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(common.Response{
-				Code:    fiber.StatusTooManyRequests,
-				Message: "Too many requests. Please try again later.",
-			})
-		},
-	}))
-
-	api := app.Group("/api/v1")
-	handler.Register(api.Group("/products"))
-
-	logrus.Fatal(app.Listen(":8080"))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go deps.Reloader.Watch(ctx)
+	select {
+	case err := <-listenErr:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+	// A second signal kills the process without waiting.
+	stop()
+
+	log.Info("shutting down, no longer ready")
+	deps.Checker.Drain()
+	time.Sleep(cfg.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		log.WithError(err).Warn("in-flight requests did not finish before the shutdown timeout")
+	}
+	if err := deps.Shutdown(ctx); err != nil {
+		log.WithError(err).Error("failed to shut down cleanly")
+	}
+	log.Info("server stopped")
+}
+
+// printConfig runs "config print": it prints the configuration the server
+// would start with, secrets redacted, and fails when it is not valid.
+func printConfig(args []string) int {
+	cfg, err := config.Parse(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := cfg.Print(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"errors"
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"simple-product-api/internal/apikey"
+	"simple-product-api/internal/apikey/usecase"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/breaker"
+	"simple-product-api/pkg/common"
+	middleware "simple-product-api/pkg/midlleware"
+	"simple-product-api/pkg/policy"
+	validatorPkg "simple-product-api/pkg/validator"
+)
+
+type Handler struct {
+	Usecase usecase.APIKeyUsecase
+	Log     *logrus.Logger
+}
+
+func NewHandler(uc usecase.APIKeyUsecase, log *logrus.Logger) *Handler {
+	return &Handler{Usecase: uc, Log: log}
+}
+
+func (h *Handler) Register(r fiber.Router) {
+	r.Use(middleware.RequireScopes(auth.ScopeAPIKeysAdmin))
+
+	r.Post("/", h.CreateKey)
+	r.Get("/", h.ListKeys)
+	r.Post("/:id/rotate", h.RotateKey)
+	r.Delete("/:id", h.RevokeKey)
+}
+
+// CreateKey godoc
+// @Summary Create API key
+// @Description Create an API key for a machine client. The key is only returned in this response. Callers can only grant scopes and roles they hold.
+// @Tags API Keys
+// @Accept  json
+// @Produce  json
+// @Param request body apikey.CreateRequest true "Key name, scopes and optional expiry"
+// @Success 201 {object} common.Response
+// @Failure 400 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Failure 422 {object} common.Response
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/admin/api-keys [post]
+func (h *Handler) CreateKey(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request create api key")
+
+	var req apikey.CreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return common.BadRequest(c, err)
+	}
+	if err := validatorPkg.Validate.Struct(&req); err != nil {
+		return common.Error(c, fiber.StatusUnprocessableEntity, err)
+	}
+
+	issued, err := h.Usecase.CreateKey(c.UserContext(), req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return common.Created(c, issued, "api key created successfully")
+}
+
+// ListKeys godoc
+// @Summary List API keys
+// @Description List API keys with their prefixes and usage. Secrets are never returned.
+// @Tags API Keys
+// @Produce  json
+// @Success 200 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/admin/api-keys [get]
+func (h *Handler) ListKeys(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request list api keys")
+
+	keys, err := h.Usecase.ListKeys(c.UserContext())
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return common.Success(c, keys, "api keys fetched successfully")
+}
+
+// RotateKey godoc
+// @Summary Rotate API key
+// @Description Issue a new secret for an API key. The previous secret stops working immediately.
+// @Tags API Keys
+// @Produce  json
+// @Param id path string true "API key ID"
+// @Success 200 {object} common.Response
+// @Failure 400 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Failure 404 {object} common.Response
+// @Failure 409 {object} common.Response
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/admin/api-keys/{id}/rotate [post]
+func (h *Handler) RotateKey(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request rotate api key")
+
+	issued, err := h.Usecase.RotateKey(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return common.Success(c, issued, "api key rotated successfully")
+}
+
+// RevokeKey godoc
+// @Summary Revoke API key
+// @Tags API Keys
+// @Produce  json
+// @Param id path string true "API key ID"
+// @Success 200 {object} common.Response
+// @Failure 400 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Failure 404 {object} common.Response
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/admin/api-keys/{id} [delete]
+func (h *Handler) RevokeKey(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request revoke api key")
+
+	if err := h.Usecase.RevokeKey(c.UserContext(), c.Params("id")); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return common.Success(c, nil, "api key revoked successfully")
+}
+
+func errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return common.Error(c, fiber.StatusGatewayTimeout, err)
+	case errors.Is(err, breaker.ErrOpen):
+		return common.Error(c, fiber.StatusServiceUnavailable, err)
+	case errors.Is(err, policy.ErrForbidden):
+		return common.Error(c, fiber.StatusForbidden, err)
+	case errors.Is(err, apikey.ErrInvalidID):
+		return common.BadRequest(c, err)
+	case errors.Is(err, apikey.ErrNotFound):
+		return common.NotFound(c, err)
+	case errors.Is(err, apikey.ErrRevoked):
+		return common.Error(c, fiber.StatusConflict, err)
+	}
+	return common.Error(c, fiber.StatusInternalServerError, err)
+}
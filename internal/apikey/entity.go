@@ -0,0 +1,42 @@
+package apikey
+
+import "time"
+
+// APIKey is a long lived credential for machine clients such as POS
+// integrations. Only the hash of the key is stored; the plain key is shown
+// once, when it is created or rotated.
+type APIKey struct {
+	ID         string     `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	Roles      []string   `json:"roles"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Active reports whether the key may still be used at the given time.
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+type CreateRequest struct {
+	Name      string     `json:"name" validate:"required"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	Roles     []string   `json:"roles" validate:"dive,required"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Issued is returned from create and rotate and is the only time the plain
+// key leaves the server.
+type Issued struct {
+	*APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,10 @@
+package apikey
+
+import "errors"
+
+var (
+	ErrNotFound   = errors.New("api key not found")
+	ErrInvalidID  = errors.New("invalid api key id")
+	ErrInvalidKey = errors.New("invalid api key")
+	ErrRevoked    = errors.New("api key revoked or expired")
+)
@@ -0,0 +1,54 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// KeyPrefix marks a bearer token as an API key rather than a JWT.
+const KeyPrefix = "spk_"
+
+const (
+	lookupBytes = 6
+	secretBytes = 24
+)
+
+// Generate returns a new plain key of the form spk_<lookup>_<secret>
+// together with its lookup prefix and hash.
+func Generate() (key, prefix, hash string, err error) {
+	lookup := make([]byte, lookupBytes)
+	secret := make([]byte, secretBytes)
+	if _, err = rand.Read(lookup); err != nil {
+		return "", "", "", err
+	}
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = KeyPrefix + hex.EncodeToString(lookup)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, Hash(key), nil
+}
+
+// Split returns the lookup prefix of a plain key.
+func Split(key string) (prefix string, ok bool) {
+	n := len(KeyPrefix) + 2*lookupBytes
+	if !strings.HasPrefix(key, KeyPrefix) || len(key) <= n+1 || key[n] != '_' {
+		return "", false
+	}
+	return key[:n], true
+}
+
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches compares key against a stored hash in constant time.
+func Matches(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(key)), []byte(hash)) == 1
+}
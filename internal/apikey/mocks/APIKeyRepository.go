@@ -0,0 +1,407 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	apikey "simple-product-api/internal/apikey"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// APIKeyRepository is an autogenerated mock type for the APIKeyRepository type
+type APIKeyRepository struct {
+	mock.Mock
+}
+
+type APIKeyRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *APIKeyRepository) EXPECT() *APIKeyRepository_Expecter {
+	return &APIKeyRepository_Expecter{mock: &_m.Mock}
+}
+
+// FindKeyByID provides a mock function with given fields: ctx, id
+func (_m *APIKeyRepository) FindKeyByID(ctx context.Context, id string) (*apikey.APIKey, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindKeyByID")
+	}
+
+	var r0 *apikey.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*apikey.APIKey, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *apikey.APIKey); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*apikey.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKeyRepository_FindKeyByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindKeyByID'
+type APIKeyRepository_FindKeyByID_Call struct {
+	*mock.Call
+}
+
+// FindKeyByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *APIKeyRepository_Expecter) FindKeyByID(ctx interface{}, id interface{}) *APIKeyRepository_FindKeyByID_Call {
+	return &APIKeyRepository_FindKeyByID_Call{Call: _e.mock.On("FindKeyByID", ctx, id)}
+}
+
+func (_c *APIKeyRepository_FindKeyByID_Call) Run(run func(ctx context.Context, id string)) *APIKeyRepository_FindKeyByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APIKeyRepository_FindKeyByID_Call) Return(_a0 *apikey.APIKey, _a1 error) *APIKeyRepository_FindKeyByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKeyRepository_FindKeyByID_Call) RunAndReturn(run func(context.Context, string) (*apikey.APIKey, error)) *APIKeyRepository_FindKeyByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindKeyByPrefix provides a mock function with given fields: ctx, prefix
+func (_m *APIKeyRepository) FindKeyByPrefix(ctx context.Context, prefix string) (*apikey.APIKey, error) {
+	ret := _m.Called(ctx, prefix)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindKeyByPrefix")
+	}
+
+	var r0 *apikey.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*apikey.APIKey, error)); ok {
+		return rf(ctx, prefix)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *apikey.APIKey); ok {
+		r0 = rf(ctx, prefix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*apikey.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKeyRepository_FindKeyByPrefix_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindKeyByPrefix'
+type APIKeyRepository_FindKeyByPrefix_Call struct {
+	*mock.Call
+}
+
+// FindKeyByPrefix is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prefix string
+func (_e *APIKeyRepository_Expecter) FindKeyByPrefix(ctx interface{}, prefix interface{}) *APIKeyRepository_FindKeyByPrefix_Call {
+	return &APIKeyRepository_FindKeyByPrefix_Call{Call: _e.mock.On("FindKeyByPrefix", ctx, prefix)}
+}
+
+func (_c *APIKeyRepository_FindKeyByPrefix_Call) Run(run func(ctx context.Context, prefix string)) *APIKeyRepository_FindKeyByPrefix_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APIKeyRepository_FindKeyByPrefix_Call) Return(_a0 *apikey.APIKey, _a1 error) *APIKeyRepository_FindKeyByPrefix_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKeyRepository_FindKeyByPrefix_Call) RunAndReturn(run func(context.Context, string) (*apikey.APIKey, error)) *APIKeyRepository_FindKeyByPrefix_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindKeys provides a mock function with given fields: ctx
+func (_m *APIKeyRepository) FindKeys(ctx context.Context) ([]apikey.APIKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindKeys")
+	}
+
+	var r0 []apikey.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]apikey.APIKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []apikey.APIKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]apikey.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKeyRepository_FindKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindKeys'
+type APIKeyRepository_FindKeys_Call struct {
+	*mock.Call
+}
+
+// FindKeys is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *APIKeyRepository_Expecter) FindKeys(ctx interface{}) *APIKeyRepository_FindKeys_Call {
+	return &APIKeyRepository_FindKeys_Call{Call: _e.mock.On("FindKeys", ctx)}
+}
+
+func (_c *APIKeyRepository_FindKeys_Call) Run(run func(ctx context.Context)) *APIKeyRepository_FindKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *APIKeyRepository_FindKeys_Call) Return(_a0 []apikey.APIKey, _a1 error) *APIKeyRepository_FindKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKeyRepository_FindKeys_Call) RunAndReturn(run func(context.Context) ([]apikey.APIKey, error)) *APIKeyRepository_FindKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeKey provides a mock function with given fields: ctx, id, at
+func (_m *APIKeyRepository) RevokeKey(ctx context.Context, id string, at time.Time) error {
+	ret := _m.Called(ctx, id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APIKeyRepository_RevokeKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeKey'
+type APIKeyRepository_RevokeKey_Call struct {
+	*mock.Call
+}
+
+// RevokeKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - at time.Time
+func (_e *APIKeyRepository_Expecter) RevokeKey(ctx interface{}, id interface{}, at interface{}) *APIKeyRepository_RevokeKey_Call {
+	return &APIKeyRepository_RevokeKey_Call{Call: _e.mock.On("RevokeKey", ctx, id, at)}
+}
+
+func (_c *APIKeyRepository_RevokeKey_Call) Run(run func(ctx context.Context, id string, at time.Time)) *APIKeyRepository_RevokeKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *APIKeyRepository_RevokeKey_Call) Return(_a0 error) *APIKeyRepository_RevokeKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APIKeyRepository_RevokeKey_Call) RunAndReturn(run func(context.Context, string, time.Time) error) *APIKeyRepository_RevokeKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RotateKey provides a mock function with given fields: ctx, id, prefix, hash
+func (_m *APIKeyRepository) RotateKey(ctx context.Context, id string, prefix string, hash string) error {
+	ret := _m.Called(ctx, id, prefix, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, id, prefix, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APIKeyRepository_RotateKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateKey'
+type APIKeyRepository_RotateKey_Call struct {
+	*mock.Call
+}
+
+// RotateKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - prefix string
+//   - hash string
+func (_e *APIKeyRepository_Expecter) RotateKey(ctx interface{}, id interface{}, prefix interface{}, hash interface{}) *APIKeyRepository_RotateKey_Call {
+	return &APIKeyRepository_RotateKey_Call{Call: _e.mock.On("RotateKey", ctx, id, prefix, hash)}
+}
+
+func (_c *APIKeyRepository_RotateKey_Call) Run(run func(ctx context.Context, id string, prefix string, hash string)) *APIKeyRepository_RotateKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *APIKeyRepository_RotateKey_Call) Return(_a0 error) *APIKeyRepository_RotateKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APIKeyRepository_RotateKey_Call) RunAndReturn(run func(context.Context, string, string, string) error) *APIKeyRepository_RotateKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveKey provides a mock function with given fields: ctx, k
+func (_m *APIKeyRepository) SaveKey(ctx context.Context, k *apikey.APIKey) error {
+	ret := _m.Called(ctx, k)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *apikey.APIKey) error); ok {
+		r0 = rf(ctx, k)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APIKeyRepository_SaveKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveKey'
+type APIKeyRepository_SaveKey_Call struct {
+	*mock.Call
+}
+
+// SaveKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - k *apikey.APIKey
+func (_e *APIKeyRepository_Expecter) SaveKey(ctx interface{}, k interface{}) *APIKeyRepository_SaveKey_Call {
+	return &APIKeyRepository_SaveKey_Call{Call: _e.mock.On("SaveKey", ctx, k)}
+}
+
+func (_c *APIKeyRepository_SaveKey_Call) Run(run func(ctx context.Context, k *apikey.APIKey)) *APIKeyRepository_SaveKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*apikey.APIKey))
+	})
+	return _c
+}
+
+func (_c *APIKeyRepository_SaveKey_Call) Return(_a0 error) *APIKeyRepository_SaveKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APIKeyRepository_SaveKey_Call) RunAndReturn(run func(context.Context, *apikey.APIKey) error) *APIKeyRepository_SaveKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TouchKey provides a mock function with given fields: ctx, id, at
+func (_m *APIKeyRepository) TouchKey(ctx context.Context, id string, at time.Time) error {
+	ret := _m.Called(ctx, id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APIKeyRepository_TouchKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchKey'
+type APIKeyRepository_TouchKey_Call struct {
+	*mock.Call
+}
+
+// TouchKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - at time.Time
+func (_e *APIKeyRepository_Expecter) TouchKey(ctx interface{}, id interface{}, at interface{}) *APIKeyRepository_TouchKey_Call {
+	return &APIKeyRepository_TouchKey_Call{Call: _e.mock.On("TouchKey", ctx, id, at)}
+}
+
+func (_c *APIKeyRepository_TouchKey_Call) Run(run func(ctx context.Context, id string, at time.Time)) *APIKeyRepository_TouchKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *APIKeyRepository_TouchKey_Call) Return(_a0 error) *APIKeyRepository_TouchKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APIKeyRepository_TouchKey_Call) RunAndReturn(run func(context.Context, string, time.Time) error) *APIKeyRepository_TouchKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewAPIKeyRepository creates a new instance of APIKeyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAPIKeyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *APIKeyRepository {
+	mock := &APIKeyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
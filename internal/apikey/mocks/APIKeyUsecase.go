@@ -0,0 +1,321 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	apikey "simple-product-api/internal/apikey"
+	auth "simple-product-api/pkg/auth"
+
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// APIKeyUsecase is an autogenerated mock type for the APIKeyUsecase type
+type APIKeyUsecase struct {
+	mock.Mock
+}
+
+type APIKeyUsecase_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *APIKeyUsecase) EXPECT() *APIKeyUsecase_Expecter {
+	return &APIKeyUsecase_Expecter{mock: &_m.Mock}
+}
+
+// CreateKey provides a mock function with given fields: ctx, req
+func (_m *APIKeyUsecase) CreateKey(ctx context.Context, req apikey.CreateRequest) (*apikey.Issued, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateKey")
+	}
+
+	var r0 *apikey.Issued
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, apikey.CreateRequest) (*apikey.Issued, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, apikey.CreateRequest) *apikey.Issued); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*apikey.Issued)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, apikey.CreateRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKeyUsecase_CreateKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateKey'
+type APIKeyUsecase_CreateKey_Call struct {
+	*mock.Call
+}
+
+// CreateKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req apikey.CreateRequest
+func (_e *APIKeyUsecase_Expecter) CreateKey(ctx interface{}, req interface{}) *APIKeyUsecase_CreateKey_Call {
+	return &APIKeyUsecase_CreateKey_Call{Call: _e.mock.On("CreateKey", ctx, req)}
+}
+
+func (_c *APIKeyUsecase_CreateKey_Call) Run(run func(ctx context.Context, req apikey.CreateRequest)) *APIKeyUsecase_CreateKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(apikey.CreateRequest))
+	})
+	return _c
+}
+
+func (_c *APIKeyUsecase_CreateKey_Call) Return(_a0 *apikey.Issued, _a1 error) *APIKeyUsecase_CreateKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKeyUsecase_CreateKey_Call) RunAndReturn(run func(context.Context, apikey.CreateRequest) (*apikey.Issued, error)) *APIKeyUsecase_CreateKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListKeys provides a mock function with given fields: ctx
+func (_m *APIKeyUsecase) ListKeys(ctx context.Context) ([]apikey.APIKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListKeys")
+	}
+
+	var r0 []apikey.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]apikey.APIKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []apikey.APIKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]apikey.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKeyUsecase_ListKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListKeys'
+type APIKeyUsecase_ListKeys_Call struct {
+	*mock.Call
+}
+
+// ListKeys is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *APIKeyUsecase_Expecter) ListKeys(ctx interface{}) *APIKeyUsecase_ListKeys_Call {
+	return &APIKeyUsecase_ListKeys_Call{Call: _e.mock.On("ListKeys", ctx)}
+}
+
+func (_c *APIKeyUsecase_ListKeys_Call) Run(run func(ctx context.Context)) *APIKeyUsecase_ListKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *APIKeyUsecase_ListKeys_Call) Return(_a0 []apikey.APIKey, _a1 error) *APIKeyUsecase_ListKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKeyUsecase_ListKeys_Call) RunAndReturn(run func(context.Context) ([]apikey.APIKey, error)) *APIKeyUsecase_ListKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResolveKey provides a mock function with given fields: ctx, key
+func (_m *APIKeyUsecase) ResolveKey(ctx context.Context, key string) (*auth.Principal, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveKey")
+	}
+
+	var r0 *auth.Principal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*auth.Principal, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *auth.Principal); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*auth.Principal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKeyUsecase_ResolveKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResolveKey'
+type APIKeyUsecase_ResolveKey_Call struct {
+	*mock.Call
+}
+
+// ResolveKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *APIKeyUsecase_Expecter) ResolveKey(ctx interface{}, key interface{}) *APIKeyUsecase_ResolveKey_Call {
+	return &APIKeyUsecase_ResolveKey_Call{Call: _e.mock.On("ResolveKey", ctx, key)}
+}
+
+func (_c *APIKeyUsecase_ResolveKey_Call) Run(run func(ctx context.Context, key string)) *APIKeyUsecase_ResolveKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APIKeyUsecase_ResolveKey_Call) Return(_a0 *auth.Principal, _a1 error) *APIKeyUsecase_ResolveKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKeyUsecase_ResolveKey_Call) RunAndReturn(run func(context.Context, string) (*auth.Principal, error)) *APIKeyUsecase_ResolveKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeKey provides a mock function with given fields: ctx, id
+func (_m *APIKeyUsecase) RevokeKey(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APIKeyUsecase_RevokeKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeKey'
+type APIKeyUsecase_RevokeKey_Call struct {
+	*mock.Call
+}
+
+// RevokeKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *APIKeyUsecase_Expecter) RevokeKey(ctx interface{}, id interface{}) *APIKeyUsecase_RevokeKey_Call {
+	return &APIKeyUsecase_RevokeKey_Call{Call: _e.mock.On("RevokeKey", ctx, id)}
+}
+
+func (_c *APIKeyUsecase_RevokeKey_Call) Run(run func(ctx context.Context, id string)) *APIKeyUsecase_RevokeKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APIKeyUsecase_RevokeKey_Call) Return(_a0 error) *APIKeyUsecase_RevokeKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APIKeyUsecase_RevokeKey_Call) RunAndReturn(run func(context.Context, string) error) *APIKeyUsecase_RevokeKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RotateKey provides a mock function with given fields: ctx, id
+func (_m *APIKeyUsecase) RotateKey(ctx context.Context, id string) (*apikey.Issued, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateKey")
+	}
+
+	var r0 *apikey.Issued
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*apikey.Issued, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *apikey.Issued); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*apikey.Issued)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKeyUsecase_RotateKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateKey'
+type APIKeyUsecase_RotateKey_Call struct {
+	*mock.Call
+}
+
+// RotateKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *APIKeyUsecase_Expecter) RotateKey(ctx interface{}, id interface{}) *APIKeyUsecase_RotateKey_Call {
+	return &APIKeyUsecase_RotateKey_Call{Call: _e.mock.On("RotateKey", ctx, id)}
+}
+
+func (_c *APIKeyUsecase_RotateKey_Call) Run(run func(ctx context.Context, id string)) *APIKeyUsecase_RotateKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APIKeyUsecase_RotateKey_Call) Return(_a0 *apikey.Issued, _a1 error) *APIKeyUsecase_RotateKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKeyUsecase_RotateKey_Call) RunAndReturn(run func(context.Context, string) (*apikey.Issued, error)) *APIKeyUsecase_RotateKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewAPIKeyUsecase creates a new instance of APIKeyUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAPIKeyUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *APIKeyUsecase {
+	mock := &APIKeyUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
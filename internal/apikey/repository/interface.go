@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	model "simple-product-api/internal/apikey"
+	"time"
+)
+
+type APIKeyRepository interface {
+	SaveKey(ctx context.Context, k *model.APIKey) error
+	FindKeys(ctx context.Context) ([]model.APIKey, error)
+	FindKeyByID(ctx context.Context, id string) (*model.APIKey, error)
+	FindKeyByPrefix(ctx context.Context, prefix string) (*model.APIKey, error)
+	RotateKey(ctx context.Context, id, prefix, hash string) error
+	RevokeKey(ctx context.Context, id string, at time.Time) error
+	TouchKey(ctx context.Context, id string, at time.Time) error
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"simple-product-api/internal/apikey"
+	"simple-product-api/pkg/breaker"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/deadline"
+	"simple-product-api/pkg/metrics"
+	"simple-product-api/pkg/retry"
+	"simple-product-api/pkg/tenant"
+	"simple-product-api/pkg/tracing"
+	"time"
+)
+
+const keyColumns = `id, tenant_id, name, prefix, key_hash, scopes, roles, expires_at, last_used_at, revoked_at, created_at`
+
+type RepositoryPostgre struct {
+	db      *sql.DB
+	Log     *logrus.Logger
+	retry   *retry.Retrier
+	breaker *breaker.Breaker
+
+	// timeout bounds each repository call, including its retries.
+	timeout time.Duration
+}
+
+func NewPostgresRepo(db *sql.DB, log *logrus.Logger, retrier *retry.Retrier, cb *breaker.Breaker, cfg *config.Config) *RepositoryPostgre {
+	return &RepositoryPostgre{db: db, Log: log, retry: retrier, breaker: cb, timeout: cfg.DBTimeout}
+}
+
+// call runs fn through the circuit breaker, which fails it fast with
+// breaker.ErrOpen while Postgres is down or too slow to answer, and retries
+// it on errors that classify accepts.
+func (r *RepositoryPostgre) call(ctx context.Context, classify retry.Classifier, fn func() error) error {
+	return r.breaker.Do(retry.Outage(ctx, retry.PostgresRead), func() error {
+		return r.retry.Do(ctx, classify, fn)
+	})
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanKey(s scanner) (*apikey.APIKey, error) {
+	var k apikey.APIKey
+	err := s.Scan(&k.ID, &k.TenantID, &k.Name, &k.Prefix, &k.Hash, pq.Array(&k.Scopes), pq.Array(&k.Roles), &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *RepositoryPostgre) SaveKey(ctx context.Context, k *apikey.APIKey) error {
+	defer metrics.ObserveQuery("api_keys", "SaveKey", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	query := `INSERT INTO api_keys (id, tenant_id, name, prefix, key_hash, scopes, roles, expires_at, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err := r.exec(ctx, query, k.ID, k.TenantID, k.Name, k.Prefix, k.Hash, pq.Array(k.Scopes), pq.Array(k.Roles), k.ExpiresAt, k.CreatedAt)
+	if err != nil {
+		r.Log.WithContext(ctx).WithError(err).Error("error inserting api key")
+	}
+	return err
+}
+
+func (r *RepositoryPostgre) FindKeys(ctx context.Context) ([]apikey.APIKey, error) {
+	defer metrics.ObserveQuery("api_keys", "FindKeys", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.query(ctx, `SELECT `+keyColumns+` FROM api_keys WHERE tenant_id = $1 ORDER BY created_at DESC`,
+		tenant.FromContext(ctx))
+	if err != nil {
+		r.Log.WithContext(ctx).WithError(err).Error("error listing api keys")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []apikey.APIKey
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *RepositoryPostgre) FindKeyByID(ctx context.Context, id string) (*apikey.APIKey, error) {
+	defer metrics.ObserveQuery("api_keys", "FindKeyByID", time.Now())
+	return r.findKey(ctx, `id = $1 AND tenant_id = $2`, id, tenant.FromContext(ctx))
+}
+
+// FindKeyByPrefix is the one lookup that is not scoped to a tenant: it runs
+// before the tenant is known, and the key it finds decides the tenant.
+func (r *RepositoryPostgre) FindKeyByPrefix(ctx context.Context, prefix string) (*apikey.APIKey, error) {
+	defer metrics.ObserveQuery("api_keys", "FindKeyByPrefix", time.Now())
+	return r.findKey(ctx, `prefix = $1`, prefix)
+}
+
+func (r *RepositoryPostgre) findKey(ctx context.Context, where string, args ...interface{}) (*apikey.APIKey, error) {
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	var k *apikey.APIKey
+	err := r.call(ctx, retry.PostgresRead, func() (err error) {
+		query := `SELECT ` + keyColumns + ` FROM api_keys WHERE ` + where
+		ctx, span := tracing.Query(ctx, query)
+		k, err = scanKey(r.db.QueryRowContext(ctx, query, args...))
+		tracing.End(span, err)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %w", apikey.ErrNotFound, err)
+		}
+		r.Log.WithContext(ctx).WithError(err).Error("error finding api key")
+		return nil, err
+	}
+	return k, nil
+}
+
+// RotateKey replaces the prefix and hash of a key that has not been revoked.
+func (r *RepositoryPostgre) RotateKey(ctx context.Context, id, prefix, hash string) error {
+	defer metrics.ObserveQuery("api_keys", "RotateKey", time.Now())
+	query := `UPDATE api_keys SET prefix = $2, key_hash = $3, last_used_at = NULL WHERE id = $1 AND revoked_at IS NULL AND tenant_id = $4`
+	return r.updateKey(ctx, query, id, prefix, hash, tenant.FromContext(ctx))
+}
+
+func (r *RepositoryPostgre) RevokeKey(ctx context.Context, id string, at time.Time) error {
+	defer metrics.ObserveQuery("api_keys", "RevokeKey", time.Now())
+	query := `UPDATE api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL AND tenant_id = $3`
+	return r.updateKey(ctx, query, id, at, tenant.FromContext(ctx))
+}
+
+func (r *RepositoryPostgre) TouchKey(ctx context.Context, id string, at time.Time) error {
+	defer metrics.ObserveQuery("api_keys", "TouchKey", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.exec(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, at)
+	if err != nil {
+		r.Log.WithContext(ctx).WithError(err).Warn("error updating api key last used time")
+	}
+	return err
+}
+
+func (r *RepositoryPostgre) updateKey(ctx context.Context, query string, args ...interface{}) error {
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	res, err := r.exec(ctx, query, args...)
+	if err != nil {
+		r.Log.WithContext(ctx).WithError(err).Error("error updating api key")
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apikey.ErrNotFound
+	}
+	return nil
+}
+
+func (r *RepositoryPostgre) exec(ctx context.Context, query string, args ...interface{}) (res sql.Result, err error) {
+	err = r.call(ctx, retry.PostgresWrite, func() error {
+		ctx, span := tracing.Query(ctx, query)
+		res, err = r.db.ExecContext(ctx, query, args...)
+		tracing.End(span, err)
+		return err
+	})
+	return res, err
+}
+
+func (r *RepositoryPostgre) query(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	err = r.call(ctx, retry.PostgresRead, func() error {
+		ctx, span := tracing.Query(ctx, query)
+		rows, err = r.db.QueryContext(ctx, query, args...)
+		tracing.End(span, err)
+		return err
+	})
+	return rows, err
+}
@@ -0,0 +1,94 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"simple-product-api/internal/apikey"
+	"simple-product-api/internal/apikey/repository"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/tenant"
+)
+
+const selectKeys = `SELECT id, tenant_id, name, prefix, key_hash, scopes, roles, expires_at, last_used_at, revoked_at, created_at FROM api_keys`
+
+func newKeyRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "tenant_id", "name", "prefix", "key_hash", "scopes", "roles", "expires_at", "last_used_at", "revoked_at", "created_at"})
+}
+
+func TestRepo_SaveKey(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := repository.NewPostgresRepo(db, logrus.New(), nil, nil, &config.Config{})
+
+	k := &apikey.APIKey{ID: "k1", TenantID: "store-1", Name: "pos", Prefix: "spk_abc", Hash: "h", Scopes: []string{"products:write"}, CreatedAt: time.Now()}
+	mock.ExpectExec(`INSERT INTO api_keys`).
+		WithArgs(k.ID, k.TenantID, k.Name, k.Prefix, k.Hash, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, k.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.SaveKey(context.Background(), k))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_FindKeyByPrefix(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := repository.NewPostgresRepo(db, logrus.New(), nil, nil, &config.Config{})
+
+	created := time.Now()
+	mock.ExpectQuery(selectKeys + ` WHERE prefix = \$1`).
+		WithArgs("spk_abc").
+		WillReturnRows(newKeyRows().AddRow("k1", "store-1", "pos", "spk_abc", "h", "{products:write,products:publish}", "{editor}", nil, nil, nil, created))
+
+	k, err := repo.FindKeyByPrefix(context.Background(), "spk_abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "k1", k.ID)
+	assert.Equal(t, "store-1", k.TenantID)
+	assert.Equal(t, []string{"products:write", "products:publish"}, k.Scopes)
+	assert.Equal(t, []string{"editor"}, k.Roles)
+	assert.Nil(t, k.RevokedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_FindKeyByPrefix_NotFound(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := repository.NewPostgresRepo(db, logrus.New(), nil, nil, &config.Config{})
+
+	mock.ExpectQuery(selectKeys + ` WHERE prefix = \$1`).WithArgs("spk_abc").WillReturnRows(newKeyRows())
+
+	k, err := repo.FindKeyByPrefix(context.Background(), "spk_abc")
+	assert.Nil(t, k)
+	assert.ErrorIs(t, err, apikey.ErrNotFound)
+}
+
+func TestRepo_RevokeKey_NotFound(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := repository.NewPostgresRepo(db, logrus.New(), nil, nil, &config.Config{})
+
+	now := time.Now()
+	mock.ExpectExec(`UPDATE api_keys SET revoked_at = \$2 WHERE id = \$1 AND revoked_at IS NULL AND tenant_id = \$3`).
+		WithArgs("k1", now, tenant.Default).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.ErrorIs(t, repo.RevokeKey(context.Background(), "k1", now), apikey.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_RotateKey(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := repository.NewPostgresRepo(db, logrus.New(), nil, nil, &config.Config{})
+
+	mock.ExpectExec(`UPDATE api_keys SET prefix = \$2, key_hash = \$3, last_used_at = NULL WHERE id = \$1 AND revoked_at IS NULL AND tenant_id = \$4`).
+		WithArgs("k1", "spk_new", "h2", tenant.Default).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.RotateKey(context.Background(), "k1", "spk_new", "h2"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"context"
+	model "simple-product-api/internal/apikey"
+	"simple-product-api/pkg/auth"
+)
+
+type APIKeyUsecase interface {
+	CreateKey(ctx context.Context, req model.CreateRequest) (*model.Issued, error)
+	ListKeys(ctx context.Context) ([]model.APIKey, error)
+	RotateKey(ctx context.Context, id string) (*model.Issued, error)
+	RevokeKey(ctx context.Context, id string) error
+	ResolveKey(ctx context.Context, key string) (*auth.Principal, error)
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	model "simple-product-api/internal/apikey"
+	"simple-product-api/internal/apikey/repository"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/policy"
+	"simple-product-api/pkg/tenant"
+	"time"
+)
+
+// touchInterval limits last_used_at writes to one per key per interval so a
+// busy integration does not turn every request into an UPDATE.
+const touchInterval = time.Minute
+
+type Usecase struct {
+	Repo repository.APIKeyRepository
+	Log  *logrus.Logger
+	now  func() time.Time
+}
+
+func NewUsecase(repo repository.APIKeyRepository, log *logrus.Logger) *Usecase {
+	return &Usecase{Repo: repo, Log: log, now: time.Now}
+}
+
+// CreateKey issues a new key. Callers can only hand out scopes and roles
+// they hold themselves, so that a key never grants more than its creator.
+func (uc *Usecase) CreateKey(ctx context.Context, req model.CreateRequest) (*model.Issued, error) {
+	caller := auth.FromContext(ctx)
+	for _, scope := range req.Scopes {
+		if !caller.HasScope(scope) {
+			return nil, fmt.Errorf("%w: cannot grant scope %s", policy.ErrForbidden, scope)
+		}
+	}
+	for _, role := range req.Roles {
+		if !caller.HasRole(role) {
+			return nil, fmt.Errorf("%w: cannot grant role %s", policy.ErrForbidden, role)
+		}
+	}
+
+	key, prefix, hash, err := model.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	k := &model.APIKey{
+		ID:        uuid.New().String(),
+		TenantID:  tenant.FromContext(ctx),
+		Name:      req.Name,
+		Prefix:    prefix,
+		Hash:      hash,
+		Scopes:    req.Scopes,
+		Roles:     req.Roles,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: uc.now(),
+	}
+	if err := uc.Repo.SaveKey(ctx, k); err != nil {
+		return nil, err
+	}
+
+	uc.Log.WithContext(ctx).WithFields(logrus.Fields{"id": k.ID, "prefix": k.Prefix}).Info("api key created")
+	return &model.Issued{APIKey: k, Key: key}, nil
+}
+
+func (uc *Usecase) ListKeys(ctx context.Context) ([]model.APIKey, error) {
+	return uc.Repo.FindKeys(ctx)
+}
+
+// RotateKey issues a new secret for an existing key, keeping its name,
+// scopes and expiry. The old secret stops working immediately.
+func (uc *Usecase) RotateKey(ctx context.Context, id string) (*model.Issued, error) {
+	if err := checkID(id); err != nil {
+		return nil, err
+	}
+	k, err := uc.Repo.FindKeyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !k.Active(uc.now()) {
+		return nil, model.ErrRevoked
+	}
+
+	key, prefix, hash, err := model.Generate()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.Repo.RotateKey(ctx, id, prefix, hash); err != nil {
+		return nil, err
+	}
+
+	k.Prefix, k.Hash, k.LastUsedAt = prefix, hash, nil
+	uc.Log.WithContext(ctx).WithFields(logrus.Fields{"id": k.ID, "prefix": k.Prefix}).Info("api key rotated")
+	return &model.Issued{APIKey: k, Key: key}, nil
+}
+
+func (uc *Usecase) RevokeKey(ctx context.Context, id string) error {
+	if err := checkID(id); err != nil {
+		return err
+	}
+	if err := uc.Repo.RevokeKey(ctx, id, uc.now()); err != nil {
+		return err
+	}
+	uc.Log.WithContext(ctx).WithField("id", id).Info("api key revoked")
+	return nil
+}
+
+// checkID rejects IDs that are not UUIDs, which no key has.
+func checkID(id string) error {
+	if err := uuid.Validate(id); err != nil {
+		return fmt.Errorf("%w: %s", model.ErrInvalidID, id)
+	}
+	return nil
+}
+
+// ResolveKey turns a plain key into the principal it authenticates as.
+// Rejected keys are reported as auth.ErrInvalidToken so that callers can
+// tell them apart from lookup failures.
+func (uc *Usecase) ResolveKey(ctx context.Context, key string) (*auth.Principal, error) {
+	prefix, ok := model.Split(key)
+	if !ok {
+		return nil, fmt.Errorf("%w: %w", auth.ErrInvalidToken, model.ErrInvalidKey)
+	}
+
+	k, err := uc.Repo.FindKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %w", auth.ErrInvalidToken, model.ErrInvalidKey)
+		}
+		return nil, err
+	}
+	if !model.Matches(key, k.Hash) {
+		return nil, fmt.Errorf("%w: %w", auth.ErrInvalidToken, model.ErrInvalidKey)
+	}
+
+	now := uc.now()
+	if !k.Active(now) {
+		return nil, fmt.Errorf("%w: %w", auth.ErrInvalidToken, model.ErrRevoked)
+	}
+
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= touchInterval {
+		// Failing to record usage must not fail the request.
+		_ = uc.Repo.TouchKey(ctx, k.ID, now)
+	}
+
+	return &auth.Principal{
+		Subject:  fmt.Sprintf("apikey:%s", k.ID),
+		TenantID: k.TenantID,
+		Scopes:   k.Scopes,
+		Roles:    k.Roles,
+	}, nil
+}
@@ -0,0 +1,157 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"simple-product-api/internal/apikey"
+	mockRepo "simple-product-api/internal/apikey/mocks"
+	"simple-product-api/internal/apikey/usecase"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/policy"
+)
+
+type UsecaseAPIKeyTestSuite struct {
+	suite.Suite
+	usecase  *usecase.Usecase
+	mockRepo *mockRepo.APIKeyRepository
+}
+
+func (s *UsecaseAPIKeyTestSuite) SetupTest() {
+	s.mockRepo = mockRepo.NewAPIKeyRepository(s.T())
+	s.usecase = usecase.NewUsecase(s.mockRepo, logrus.New())
+}
+
+const keyID = "00000000-0000-4000-8000-0000000000e1"
+
+// asPrincipal returns a context authenticated with the given scopes and
+// roles.
+func asPrincipal(scopes []string, roles ...string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "u1", Scopes: scopes, Roles: roles})
+}
+
+func TestUsecaseAPIKeyTestSuite(t *testing.T) {
+	suite.Run(t, new(UsecaseAPIKeyTestSuite))
+}
+
+func (s *UsecaseAPIKeyTestSuite) TestCreateStoresOnlyHash() {
+	var saved *apikey.APIKey
+	s.mockRepo.EXPECT().SaveKey(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, k *apikey.APIKey) { saved = k }).
+		Return(nil)
+
+	ctx := asPrincipal([]string{auth.ScopeAPIKeysAdmin, auth.ScopeProductsWrite})
+	issued, err := s.usecase.CreateKey(ctx, apikey.CreateRequest{Name: "pos", Scopes: []string{auth.ScopeProductsWrite}})
+	s.NoError(err)
+	s.True(len(issued.Key) > len(saved.Prefix))
+	s.Equal(saved.Prefix, issued.Key[:len(saved.Prefix)])
+	s.Equal(apikey.Hash(issued.Key), saved.Hash)
+	s.NotContains(saved.Hash, issued.Key)
+}
+
+func (s *UsecaseAPIKeyTestSuite) TestCreateCannotEscalate() {
+	ctx := asPrincipal([]string{auth.ScopeAPIKeysAdmin, auth.ScopeProductsWrite}, "editor")
+
+	for _, req := range []apikey.CreateRequest{
+		{Name: "pos", Scopes: []string{auth.ScopeProductsAdmin}},
+		{Name: "pos", Scopes: []string{auth.ScopeProductsWrite}, Roles: []string{"admin"}},
+	} {
+		_, err := s.usecase.CreateKey(ctx, req)
+		s.ErrorIs(err, policy.ErrForbidden)
+	}
+	s.mockRepo.AssertNotCalled(s.T(), "SaveKey", mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseAPIKeyTestSuite) TestRotateAndRevokeRejectInvalidID() {
+	_, err := s.usecase.RotateKey(context.Background(), "k1")
+	s.ErrorIs(err, apikey.ErrInvalidID)
+	s.ErrorIs(s.usecase.RevokeKey(context.Background(), "k1"), apikey.ErrInvalidID)
+}
+
+func (s *UsecaseAPIKeyTestSuite) TestResolveKeySuccess() {
+	key, prefix, hash, err := apikey.Generate()
+	s.Require().NoError(err)
+
+	stored := &apikey.APIKey{ID: keyID, TenantID: "store-1", Prefix: prefix, Hash: hash, Scopes: []string{auth.ScopeProductsWrite}, Roles: []string{"editor"}}
+	s.mockRepo.EXPECT().FindKeyByPrefix(mock.Anything, prefix).Return(stored, nil)
+	s.mockRepo.EXPECT().TouchKey(mock.Anything, keyID, mock.Anything).Return(nil)
+
+	p, err := s.usecase.ResolveKey(context.Background(), key)
+	s.NoError(err)
+	s.Equal("apikey:"+keyID, p.Subject)
+	s.True(p.HasScope(auth.ScopeProductsWrite))
+	s.Equal([]string{"editor"}, p.Roles)
+	s.Equal("store-1", p.TenantID)
+}
+
+func (s *UsecaseAPIKeyTestSuite) TestResolveKeySkipsRecentTouch() {
+	key, prefix, hash, _ := apikey.Generate()
+	recent := time.Now().Add(-time.Second)
+
+	s.mockRepo.EXPECT().FindKeyByPrefix(mock.Anything, prefix).
+		Return(&apikey.APIKey{ID: keyID, Prefix: prefix, Hash: hash, LastUsedAt: &recent}, nil)
+
+	_, err := s.usecase.ResolveKey(context.Background(), key)
+	s.NoError(err)
+	s.mockRepo.AssertNotCalled(s.T(), "TouchKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseAPIKeyTestSuite) TestResolveKeyWrongSecret() {
+	_, prefix, hash, _ := apikey.Generate()
+	s.mockRepo.EXPECT().FindKeyByPrefix(mock.Anything, prefix).
+		Return(&apikey.APIKey{ID: keyID, Prefix: prefix, Hash: hash}, nil)
+
+	_, err := s.usecase.ResolveKey(context.Background(), prefix+"_forged")
+	s.ErrorIs(err, auth.ErrInvalidToken)
+	s.ErrorIs(err, apikey.ErrInvalidKey)
+}
+
+func (s *UsecaseAPIKeyTestSuite) TestResolveKeyExpired() {
+	key, prefix, hash, _ := apikey.Generate()
+	expired := time.Now().Add(-time.Hour)
+	s.mockRepo.EXPECT().FindKeyByPrefix(mock.Anything, prefix).
+		Return(&apikey.APIKey{ID: keyID, Prefix: prefix, Hash: hash, ExpiresAt: &expired}, nil)
+
+	_, err := s.usecase.ResolveKey(context.Background(), key)
+	s.ErrorIs(err, auth.ErrInvalidToken)
+	s.ErrorIs(err, apikey.ErrRevoked)
+}
+
+func (s *UsecaseAPIKeyTestSuite) TestResolveKeyMalformed() {
+	_, err := s.usecase.ResolveKey(context.Background(), "not-a-key")
+	s.ErrorIs(err, auth.ErrInvalidToken)
+}
+
+func (s *UsecaseAPIKeyTestSuite) TestResolveKeyLookupError() {
+	key, prefix, _, _ := apikey.Generate()
+	s.mockRepo.EXPECT().FindKeyByPrefix(mock.Anything, prefix).Return(nil, errors.New("db down"))
+
+	_, err := s.usecase.ResolveKey(context.Background(), key)
+	s.Error(err)
+	s.NotErrorIs(err, auth.ErrInvalidToken)
+}
+
+func (s *UsecaseAPIKeyTestSuite) TestRotateRevokedKey() {
+	revoked := time.Now().Add(-time.Minute)
+	s.mockRepo.EXPECT().FindKeyByID(mock.Anything, keyID).
+		Return(&apikey.APIKey{ID: keyID, RevokedAt: &revoked}, nil)
+
+	_, err := s.usecase.RotateKey(context.Background(), keyID)
+	s.ErrorIs(err, apikey.ErrRevoked)
+}
+
+func (s *UsecaseAPIKeyTestSuite) TestRotateIssuesNewSecret() {
+	s.mockRepo.EXPECT().FindKeyByID(mock.Anything, keyID).
+		Return(&apikey.APIKey{ID: keyID, Prefix: "spk_old", Hash: "old"}, nil)
+	s.mockRepo.EXPECT().RotateKey(mock.Anything, keyID, mock.Anything, mock.Anything).Return(nil)
+
+	issued, err := s.usecase.RotateKey(context.Background(), keyID)
+	s.NoError(err)
+	s.NotEqual("spk_old", issued.Prefix)
+	s.Equal(apikey.Hash(issued.Key), issued.Hash)
+}
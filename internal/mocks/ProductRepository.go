@@ -7,6 +7,8 @@ import (
 	product "simple-product-api/internal/product"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // ProductRepository is an autogenerated mock type for the ProductRepository type
@@ -14,6 +16,84 @@ type ProductRepository struct {
 	mock.Mock
 }
 
+// DeleteProduct provides a mock function with given fields: ctx, id
+func (_m *ProductRepository) DeleteProduct(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProduct")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindBundleIDsByComponent provides a mock function with given fields: ctx, componentID
+func (_m *ProductRepository) FindBundleIDsByComponent(ctx context.Context, componentID string) ([]string, error) {
+	ret := _m.Called(ctx, componentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindBundleIDsByComponent")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, componentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, componentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, componentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindComponents provides a mock function with given fields: ctx, bundleID
+func (_m *ProductRepository) FindComponents(ctx context.Context, bundleID string) ([]product.Component, error) {
+	ret := _m.Called(ctx, bundleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindComponents")
+	}
+
+	var r0 []product.Component
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]product.Component, error)); ok {
+		return rf(ctx, bundleID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []product.Component); ok {
+		r0 = rf(ctx, bundleID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]product.Component)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, bundleID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindProduct provides a mock function with given fields: ctx, filter
 func (_m *ProductRepository) FindProduct(ctx context.Context, filter product.ListFilter) ([]product.Product, int, error) {
 	ret := _m.Called(ctx, filter)
@@ -51,6 +131,36 @@ func (_m *ProductRepository) FindProduct(ctx context.Context, filter product.Lis
 	return r0, r1, r2
 }
 
+// FindProductByBarcode provides a mock function with given fields: ctx, code
+func (_m *ProductRepository) FindProductByBarcode(ctx context.Context, code string) (*product.Product, error) {
+	ret := _m.Called(ctx, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindProductByBarcode")
+	}
+
+	var r0 *product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*product.Product, error)); ok {
+		return rf(ctx, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *product.Product); ok {
+		r0 = rf(ctx, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindProductByID provides a mock function with given fields: ctx, id
 func (_m *ProductRepository) FindProductByID(ctx context.Context, id string) (*product.Product, error) {
 	ret := _m.Called(ctx, id)
@@ -111,6 +221,54 @@ func (_m *ProductRepository) FindProductByNameAndType(ctx context.Context, name
 	return r0, r1
 }
 
+// FindSimilarProducts provides a mock function with given fields: ctx, name, ptype, limit
+func (_m *ProductRepository) FindSimilarProducts(ctx context.Context, name string, ptype string, limit int) ([]product.Product, error) {
+	ret := _m.Called(ctx, name, ptype, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindSimilarProducts")
+	}
+
+	var r0 []product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) ([]product.Product, error)); ok {
+		return rf(ctx, name, ptype, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []product.Product); ok {
+		r0 = rf(ctx, name, ptype, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, name, ptype, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MergeProducts provides a mock function with given fields: ctx, targetID, sourceIDs
+func (_m *ProductRepository) MergeProducts(ctx context.Context, targetID string, sourceIDs []string) error {
+	ret := _m.Called(ctx, targetID, sourceIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergeProducts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) error); ok {
+		r0 = rf(ctx, targetID, sourceIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveProduct provides a mock function with given fields: ctx, p
 func (_m *ProductRepository) SaveProduct(ctx context.Context, p *product.Product) error {
 	ret := _m.Called(ctx, p)
@@ -129,6 +287,72 @@ func (_m *ProductRepository) SaveProduct(ctx context.Context, p *product.Product
 	return r0
 }
 
+// ScanProductIDs provides a mock function with given fields: ctx, fn
+func (_m *ProductRepository) ScanProductIDs(ctx context.Context, fn func(string)) error {
+	ret := _m.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScanProductIDs")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(string)) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateProduct provides a mock function with given fields: ctx, p
+func (_m *ProductRepository) UpdateProduct(ctx context.Context, p *product.Product) ([]product.Product, error) {
+	ret := _m.Called(ctx, p)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProduct")
+	}
+
+	var r0 []product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *product.Product) ([]product.Product, error)); ok {
+		return rf(ctx, p)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *product.Product) []product.Product); ok {
+		r0 = rf(ctx, p)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *product.Product) error); ok {
+		r1 = rf(ctx, p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, id, from, to, publishAt
+func (_m *ProductRepository) UpdateStatus(ctx context.Context, id string, from string, to string, publishAt *time.Time) error {
+	ret := _m.Called(ctx, id, from, to, publishAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, *time.Time) error); ok {
+		r0 = rf(ctx, id, from, to, publishAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewProductRepository creates a new instance of ProductRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewProductRepository(t interface {
@@ -7,6 +7,8 @@ import (
 	product "simple-product-api/internal/product"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // ProductUsecase is an autogenerated mock type for the ProductUsecase type
@@ -14,17 +16,97 @@ type ProductUsecase struct {
 	mock.Mock
 }
 
-// CreateProduct provides a mock function with given fields: ctx, p
-func (_m *ProductUsecase) CreateProduct(ctx context.Context, p *product.Product) error {
-	ret := _m.Called(ctx, p)
+// CacheKeys provides a mock function with given fields: ctx, pattern, limit
+func (_m *ProductUsecase) CacheKeys(ctx context.Context, pattern string, limit int) ([]product.CacheKey, error) {
+	ret := _m.Called(ctx, pattern, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CacheKeys")
+	}
+
+	var r0 []product.CacheKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]product.CacheKey, error)); ok {
+		return rf(ctx, pattern, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []product.CacheKey); ok {
+		r0 = rf(ctx, pattern, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]product.CacheKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, pattern, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CacheStats provides a mock function with no fields
+func (_m *ProductUsecase) CacheStats() map[string]product.CacheStats {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CacheStats")
+	}
+
+	var r0 map[string]product.CacheStats
+	if rf, ok := ret.Get(0).(func() map[string]product.CacheStats); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]product.CacheStats)
+		}
+	}
+
+	return r0
+}
+
+// ChangeStatus provides a mock function with given fields: ctx, id, status, publishAt
+func (_m *ProductUsecase) ChangeStatus(ctx context.Context, id string, status string, publishAt *time.Time) (*product.Product, error) {
+	ret := _m.Called(ctx, id, status, publishAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangeStatus")
+	}
+
+	var r0 *product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *time.Time) (*product.Product, error)); ok {
+		return rf(ctx, id, status, publishAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *time.Time) *product.Product); ok {
+		r0 = rf(ctx, id, status, publishAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *time.Time) error); ok {
+		r1 = rf(ctx, id, status, publishAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateProduct provides a mock function with given fields: ctx, p, opts
+func (_m *ProductUsecase) CreateProduct(ctx context.Context, p *product.Product, opts product.CreateOptions) error {
+	ret := _m.Called(ctx, p, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateProduct")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *product.Product) error); ok {
-		r0 = rf(ctx, p)
+	if rf, ok := ret.Get(0).(func(context.Context, *product.Product, product.CreateOptions) error); ok {
+		r0 = rf(ctx, p, opts)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -32,29 +114,105 @@ func (_m *ProductUsecase) CreateProduct(ctx context.Context, p *product.Product)
 	return r0
 }
 
-// GetProductByID provides a mock function with given fields: ctx, id
-func (_m *ProductUsecase) GetProductByID(ctx context.Context, id string) (*product.Product, error) {
+// DeleteProduct provides a mock function with given fields: ctx, id
+func (_m *ProductUsecase) DeleteProduct(ctx context.Context, id string) error {
 	ret := _m.Called(ctx, id)
 
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProduct")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FlushCache provides a mock function with given fields: ctx, pattern
+func (_m *ProductUsecase) FlushCache(ctx context.Context, pattern string) (int, error) {
+	ret := _m.Called(ctx, pattern)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FlushCache")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, pattern)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, pattern)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, pattern)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProductByBarcode provides a mock function with given fields: ctx, code, vis
+func (_m *ProductUsecase) GetProductByBarcode(ctx context.Context, code string, vis product.Visibility) (*product.Product, error) {
+	ret := _m.Called(ctx, code, vis)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProductByBarcode")
+	}
+
+	var r0 *product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, product.Visibility) (*product.Product, error)); ok {
+		return rf(ctx, code, vis)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, product.Visibility) *product.Product); ok {
+		r0 = rf(ctx, code, vis)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, product.Visibility) error); ok {
+		r1 = rf(ctx, code, vis)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProductByID provides a mock function with given fields: ctx, id, vis
+func (_m *ProductUsecase) GetProductByID(ctx context.Context, id string, vis product.Visibility) (*product.Product, error) {
+	ret := _m.Called(ctx, id, vis)
+
 	if len(ret) == 0 {
 		panic("no return value specified for GetProductByID")
 	}
 
 	var r0 *product.Product
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*product.Product, error)); ok {
-		return rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, product.Visibility) (*product.Product, error)); ok {
+		return rf(ctx, id, vis)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *product.Product); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, product.Visibility) *product.Product); ok {
+		r0 = rf(ctx, id, vis)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*product.Product)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, string, product.Visibility) error); ok {
+		r1 = rf(ctx, id, vis)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -99,6 +257,66 @@ func (_m *ProductUsecase) ListProduct(ctx context.Context, filter product.ListFi
 	return r0, r1, r2
 }
 
+// MergeProducts provides a mock function with given fields: ctx, targetID, sourceIDs
+func (_m *ProductUsecase) MergeProducts(ctx context.Context, targetID string, sourceIDs []string) (*product.Product, error) {
+	ret := _m.Called(ctx, targetID, sourceIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergeProducts")
+	}
+
+	var r0 *product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (*product.Product, error)); ok {
+		return rf(ctx, targetID, sourceIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *product.Product); ok {
+		r0 = rf(ctx, targetID, sourceIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, targetID, sourceIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateProduct provides a mock function with given fields: ctx, id, patch
+func (_m *ProductUsecase) UpdateProduct(ctx context.Context, id string, patch product.Patch) (*product.Product, error) {
+	ret := _m.Called(ctx, id, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProduct")
+	}
+
+	var r0 *product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, product.Patch) (*product.Product, error)); ok {
+		return rf(ctx, id, patch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, product.Patch) *product.Product); ok {
+		r0 = rf(ctx, id, patch)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, product.Patch) error); ok {
+		r1 = rf(ctx, id, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewProductUsecase creates a new instance of ProductUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewProductUsecase(t interface {
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"errors"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/common"
+	middleware "simple-product-api/pkg/midlleware"
+)
+
+const (
+	defaultKeyLimit = 100
+	maxKeyLimit     = 1000
+)
+
+// RegisterCacheAdmin mounts the endpoints for inspecting and flushing the
+// product cache.
+func (h *Handler) RegisterCacheAdmin(r fiber.Router) {
+	r.Use(middleware.RequireScopes(auth.ScopeProductsAdmin))
+
+	r.Get("/stats", h.CacheStats)
+	r.Get("/keys", h.CacheKeys)
+	r.Delete("/keys", h.FlushCache)
+}
+
+// CacheStats godoc
+// @Summary Cache hit ratios
+// @Description Cache hits, misses and errors by namespace, counted by the instance that serves the request since it started
+// @Tags Cache
+// @Produce  json
+// @Success 200 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/admin/cache/stats [get]
+func (h *Handler) CacheStats(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request cache stats")
+
+	return common.Success(c, h.Usecase.CacheStats(), "successfully fetched cache stats")
+}
+
+// CacheKeys godoc
+// @Summary List cached keys
+// @Description List the tenant's cached keys matching a glob pattern under products:, with their remaining TTL. Keys are found with SCAN.
+// @Tags Cache
+// @Produce  json
+// @Param pattern query string true "Glob pattern, e.g. products:id:*"
+// @Param limit query int false "Maximum number of keys (default 100, at most 1000)"
+// @Success 200 {object} common.Response
+// @Failure 400 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Failure 501 {object} common.Response
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/admin/cache/keys [get]
+func (h *Handler) CacheKeys(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request list cache keys")
+
+	limit := c.QueryInt("limit", defaultKeyLimit)
+	if limit < 1 || limit > maxKeyLimit {
+		return common.BadRequest(c, errors.New("limit must be between 1 and 1000"))
+	}
+
+	keys, err := h.Usecase.CacheKeys(c.UserContext(), c.Query("pattern"), limit)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return common.Success(c, keys, "successfully fetched cache keys")
+}
+
+// FlushCache godoc
+// @Summary Flush cached keys
+// @Description Delete the tenant's cached keys matching a glob pattern under products:. Keys are found with SCAN and deleted in batches, so Redis is never blocked.
+// @Tags Cache
+// @Produce  json
+// @Param pattern query string true "Glob pattern, e.g. products:all:*"
+// @Success 200 {object} common.Response
+// @Failure 400 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Failure 501 {object} common.Response
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/admin/cache/keys [delete]
+func (h *Handler) FlushCache(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request flush cache")
+
+	n, err := h.Usecase.FlushCache(c.UserContext(), c.Query("pattern"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return common.Success(c, fiber.Map{"deleted": n}, "cache flushed successfully")
+}
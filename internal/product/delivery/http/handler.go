@@ -1,13 +1,22 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"github.com/go-playground/validator/v10"
 	fiber "github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 	"simple-product-api/internal/product"
 	"simple-product-api/internal/product/usecase"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/barcode"
+	"simple-product-api/pkg/breaker"
 	"simple-product-api/pkg/common"
+	middleware "simple-product-api/pkg/midlleware"
+	"simple-product-api/pkg/policy"
 	validatorPkg "simple-product-api/pkg/validator"
+	"strings"
+	"time"
 )
 
 type Handler struct {
@@ -20,9 +29,17 @@ func NewHandler(uc usecase.ProductUsecase, log *logrus.Logger) *Handler {
 }
 
 func (h *Handler) Register(r fiber.Router) {
-	r.Post("/", h.CreateProduct)
+	write := middleware.RequireScopes(auth.ScopeProductsWrite)
+
+	r.Post("/", write, h.CreateProduct)
 	r.Post("/list", h.ListProduct)
+	r.Post("/merge", middleware.RequireScopes(auth.ScopeProductsAdmin), h.MergeProducts)
+	r.Get("/by-barcode/:code", h.GetProductByBarcode)
 	r.Get("/:id", h.GetProductById)
+	r.Get("/:id/barcode", h.GetProductBarcodeImage)
+	r.Patch("/:id", write, h.UpdateProduct)
+	r.Delete("/:id", write, h.DeleteProduct)
+	r.Post("/:id/status", middleware.RequireScopes(auth.ScopeProductsPublish), h.ChangeProductStatus)
 }
 
 // CreateProduct godoc
@@ -34,11 +51,17 @@ func (h *Handler) Register(r fiber.Router) {
 // @Param   name query string true "Product Name"
 // @Param   type query string true "Product Type"
 // @Param   price query int false "Product Price"
+// @Param   force query bool false "Create even if similarly named products exist"
 // @Success 201 {object} common.Response
 // @Failure 400 {object} common.Response
+// @Failure 409 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /api/v1/products [post]
 func (h *Handler) CreateProduct(c *fiber.Ctx) error {
-	h.Log.Info("received request to create products")
+	h.Log.WithContext(c.UserContext()).Info("received request to create products")
 
 	var p product.Product
 	if err := c.BodyParser(&p); err != nil {
@@ -57,8 +80,9 @@ func (h *Handler) CreateProduct(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.Usecase.CreateProduct(c.Context(), &p); err != nil {
-		return common.Error(c, fiber.StatusInternalServerError, err)
+	opts := product.CreateOptions{Force: c.QueryBool("force")}
+	if err := h.Usecase.CreateProduct(c.UserContext(), &p, opts); err != nil {
+		return errorResponse(c, err)
 	}
 
 	return common.Created(c, p, "product created successfully")
@@ -80,19 +104,20 @@ func (h *Handler) CreateProduct(c *fiber.Ctx) error {
 // @Failure 400 {object} common.Response
 // @Router /api/v1/products/list [post]
 func (h *Handler) ListProduct(c *fiber.Ctx) error {
-	h.Log.Info("received request to list products")
+	h.Log.WithContext(c.UserContext()).Info("received request to list products")
 
 	filter := product.ListFilter{
-		Query:    c.Query("name"),
-		Type:     c.Query("type"),
-		SortBy:   c.Query("sort_by"),
-		Order:    c.Query("order"),
-		Page:     c.QueryInt("page", 1),
-		PageSize: c.QueryInt("limit", 10),
-	}
-	list, total, err := h.Usecase.ListProduct(c.Context(), filter)
+		Query:      c.Query("name"),
+		Type:       c.Query("type"),
+		SortBy:     c.Query("sort_by"),
+		Order:      c.Query("order"),
+		Page:       c.QueryInt("page", 1),
+		PageSize:   c.QueryInt("limit", 10),
+		Visibility: visibility(c),
+	}
+	list, total, err := h.Usecase.ListProduct(c.UserContext(), filter)
 	if err != nil {
-		return common.Error(c, fiber.StatusInternalServerError, err)
+		return errorResponse(c, err)
 	}
 
 	meta := product.MetaPage{
@@ -113,16 +138,296 @@ func (h *Handler) ListProduct(c *fiber.Ctx) error {
 // @Produce  json
 // @Param id path string true "Product ID"
 // @Success 200 {object} common.Response
+// @Success 301 {object} common.Response "Product was merged; Location points at the survivor"
+// @Failure 400 {object} common.Response
 // @Failure 404 {object} common.Response
-// @Router /api/v1/products [get]
+// @Router /api/v1/products/{id} [get]
 func (h *Handler) GetProductById(c *fiber.Ctx) error {
-	h.Log.Info("received request get product by id")
+	h.Log.WithContext(c.UserContext()).Info("received request get product by id")
 
 	id := c.Params("id")
-	result, err := h.Usecase.GetProductByID(c.Context(), id)
+	result, err := h.Usecase.GetProductByID(c.UserContext(), id, visibility(c))
+	var mergedErr *product.MergedError
+	if errors.As(err, &mergedErr) {
+		return movedPermanently(c, mergedErr)
+	}
 	if err != nil {
-		return common.NotFound(c, err)
+		return errorResponse(c, err)
 	}
 
 	return common.Success(c, result, "successfully fetched products")
 }
+
+// GetProductByBarcode godoc
+// @Summary Get product by barcode
+// @Description Get product by GTIN, EAN-13 or UPC-A barcode
+// @Tags Products
+// @Accept  json
+// @Produce  json
+// @Param code path string true "Barcode"
+// @Success 200 {object} common.Response
+// @Failure 400 {object} common.Response
+// @Failure 404 {object} common.Response
+// @Router /api/v1/products/by-barcode/{code} [get]
+func (h *Handler) GetProductByBarcode(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request get product by barcode")
+
+	result, err := h.Usecase.GetProductByBarcode(c.UserContext(), c.Params("code"), visibility(c))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return common.Success(c, result, "successfully fetched products")
+}
+
+// GetProductBarcodeImage godoc
+// @Summary Render product barcode
+// @Description Render the product's EAN-13/UPC-A barcode as PNG or SVG for label printing
+// @Tags Products
+// @Produce  png
+// @Produce  image/svg+xml
+// @Param id path string true "Product ID"
+// @Param format query string false "Image format (png or svg)"
+// @Success 200 {file} binary
+// @Failure 400 {object} common.Response
+// @Failure 404 {object} common.Response
+// @Failure 409 {object} common.Response
+// @Failure 422 {object} common.Response
+// @Router /api/v1/products/{id}/barcode [get]
+func (h *Handler) GetProductBarcodeImage(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request render product barcode")
+
+	result, err := h.Usecase.GetProductByID(c.UserContext(), c.Params("id"), visibility(c))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if result.Barcode == "" {
+		return common.NotFound(c, errors.New("product has no barcode"))
+	}
+
+	var image []byte
+	switch c.Query("format", "png") {
+	case "svg":
+		image, err = barcode.RenderSVG(result.Barcode)
+		c.Type("svg")
+	case "png":
+		image, err = barcode.RenderPNG(result.Barcode)
+		c.Type("png")
+	default:
+		return common.BadRequest(c, errors.New("format must be png or svg"))
+	}
+	if err != nil {
+		return common.Error(c, fiber.StatusUnprocessableEntity, err)
+	}
+
+	return c.Send(image)
+}
+
+// UpdateProduct godoc
+// @Summary Update product
+// @Description Partially update a product. Which fields a caller may change depends on their roles; only pricing managers may change prices.
+// @Tags Products
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param request body product.Patch true "Fields to change"
+// @Success 200 {object} common.Response
+// @Failure 400 {object} common.Response
+// @Failure 404 {object} common.Response
+// @Failure 409 {object} common.Response
+// @Failure 422 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/products/{id} [patch]
+func (h *Handler) UpdateProduct(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request update product")
+
+	var patch product.Patch
+	if err := c.BodyParser(&patch); err != nil {
+		return common.BadRequest(c, err)
+	}
+	if err := validatorPkg.Validate.Struct(&patch); err != nil {
+		return common.Error(c, fiber.StatusUnprocessableEntity, err)
+	}
+	if len(patch.Fields()) == 0 {
+		return common.BadRequest(c, errors.New("no fields to update"))
+	}
+
+	result, err := h.Usecase.UpdateProduct(c.UserContext(), c.Params("id"), patch)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return common.Success(c, result, "product updated successfully")
+}
+
+// DeleteProduct godoc
+// @Summary Delete product
+// @Description Delete a product. Products used as a bundle component or merged into, and merged products themselves, cannot be deleted.
+// @Tags Products
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {object} common.Response
+// @Failure 400 {object} common.Response
+// @Failure 404 {object} common.Response
+// @Failure 409 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/products/{id} [delete]
+func (h *Handler) DeleteProduct(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request delete product")
+
+	if err := h.Usecase.DeleteProduct(c.UserContext(), c.Params("id")); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return common.Success(c, nil, "product deleted successfully")
+}
+
+type statusRequest struct {
+	Status    string     `json:"status" validate:"required,oneof=draft pending_review active archived"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// ChangeProductStatus godoc
+// @Summary Change product status
+// @Description Move a product through draft, pending_review, active and archived. Activation may be scheduled with publish_at.
+// @Tags Products
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param request body statusRequest true "Target status"
+// @Success 200 {object} common.Response
+// @Failure 400 {object} common.Response
+// @Failure 404 {object} common.Response
+// @Failure 409 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/products/{id}/status [post]
+func (h *Handler) ChangeProductStatus(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request change product status")
+
+	var req statusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return common.BadRequest(c, err)
+	}
+	if err := validatorPkg.Validate.Struct(&req); err != nil {
+		return common.Error(c, fiber.StatusUnprocessableEntity, err)
+	}
+
+	result, err := h.Usecase.ChangeStatus(c.UserContext(), c.Params("id"), req.Status, req.PublishAt)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return common.Success(c, result, "product status changed successfully")
+}
+
+type mergeRequest struct {
+	TargetID  string   `json:"target_id" validate:"required"`
+	SourceIDs []string `json:"source_ids" validate:"required,min=1,dive,required"`
+}
+
+// MergeProducts godoc
+// @Summary Merge duplicate products
+// @Description Merge source products into a target. Bundle components move to the target and the sources become redirects.
+// @Tags Products
+// @Accept  json
+// @Produce  json
+// @Param request body mergeRequest true "Target and source product IDs"
+// @Success 200 {object} common.Response
+// @Failure 400 {object} common.Response
+// @Failure 404 {object} common.Response
+// @Failure 422 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/products/merge [post]
+func (h *Handler) MergeProducts(c *fiber.Ctx) error {
+	h.Log.WithContext(c.UserContext()).Info("received request merge products")
+
+	var req mergeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return common.BadRequest(c, err)
+	}
+	if err := validatorPkg.Validate.Struct(&req); err != nil {
+		return common.Error(c, fiber.StatusUnprocessableEntity, err)
+	}
+
+	result, err := h.Usecase.MergeProducts(c.UserContext(), req.TargetID, req.SourceIDs)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return common.Success(c, result, "products merged successfully")
+}
+
+// movedPermanently points the caller at the product a merged ID now lives
+// under, both in the Location header and in the body.
+func movedPermanently(c *fiber.Ctx, err *product.MergedError) error {
+	c.Location(strings.TrimSuffix(c.Path(), err.ID) + err.MergedInto)
+	return c.Status(fiber.StatusMovedPermanently).JSON(common.Response{
+		Code:    fiber.StatusMovedPermanently,
+		Message: err.Error(),
+		Data:    fiber.Map{"merged_into": err.MergedInto},
+	})
+}
+
+// visibility grants callers holding the read_unpublished scope access to
+// drafts, pending and archived products; everyone else only sees published
+// ones.
+func visibility(c *fiber.Ctx) product.Visibility {
+	if middleware.Principal(c).HasScope(auth.ScopeProductsReadUnpublished) {
+		return product.VisibilityAll
+	}
+	return product.VisibilityPublic
+}
+
+func errorResponse(c *fiber.Ctx, err error) error {
+	var dupErr *product.DuplicateError
+	if errors.As(err, &dupErr) {
+		return c.Status(fiber.StatusConflict).JSON(common.Response{
+			Code:    fiber.StatusConflict,
+			Message: err.Error(),
+			Data:    dupErr.Matches,
+		})
+	}
+
+	// Reads redirect to the surviving product; writes must not be replayed
+	// there silently.
+	var mergedErr *product.MergedError
+	if errors.As(err, &mergedErr) {
+		return c.Status(fiber.StatusConflict).JSON(common.Response{
+			Code:    fiber.StatusConflict,
+			Message: err.Error(),
+			Data:    fiber.Map{"merged_into": mergedErr.MergedInto},
+		})
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return common.Error(c, fiber.StatusGatewayTimeout, err)
+	case errors.Is(err, breaker.ErrOpen):
+		return common.Error(c, fiber.StatusServiceUnavailable, err)
+	case errors.Is(err, policy.ErrForbidden):
+		return common.Error(c, fiber.StatusForbidden, err)
+	case errors.Is(err, product.ErrNotFound):
+		return common.NotFound(c, err)
+	case errors.Is(err, product.ErrDuplicate), errors.Is(err, product.ErrInUse), errors.Is(err, product.ErrInvalidTransition):
+		return common.Error(c, fiber.StatusConflict, err)
+	case errors.Is(err, product.ErrInvalidBarcode), errors.Is(err, product.ErrInvalidID), errors.Is(err, product.ErrInvalidPattern):
+		return common.BadRequest(c, err)
+	case errors.Is(err, product.ErrInvalidBundle), errors.Is(err, product.ErrInvalidMerge), errors.Is(err, product.ErrInvalidPrice):
+		return common.Error(c, fiber.StatusUnprocessableEntity, err)
+	case errors.Is(err, errors.ErrUnsupported):
+		return common.Error(c, fiber.StatusNotImplemented, err)
+	}
+	return common.Error(c, fiber.StatusInternalServerError, err)
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"simple-product-api/internal/product/mocks"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/breaker"
+	middleware "simple-product-api/pkg/midlleware"
+)
+
+const productID = "00000000-0000-4000-8000-0000000000a1"
+
+func newProductApp(uc *mocks.ProductUsecase) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	NewHandler(uc, logrus.New()).Register(app.Group("/products"))
+	return app
+}
+
+func status(t *testing.T, app *fiber.App, method, path string) int {
+	resp, err := app.Test(httptest.NewRequest(method, path, nil), -1)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestReadsReportOutages(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("find product: %w", breaker.ErrOpen), fiber.StatusServiceUnavailable},
+		{fmt.Errorf("find product: %w", context.DeadlineExceeded), fiber.StatusGatewayTimeout},
+	} {
+		uc := mocks.NewProductUsecase(t)
+		uc.EXPECT().GetProductByID(mock.Anything, productID, mock.Anything).Return(nil, tc.err)
+		uc.EXPECT().ListProduct(mock.Anything, mock.Anything).Return(nil, 0, tc.err)
+		app := newProductApp(uc)
+
+		assert.Equal(t, tc.want, status(t, app, fiber.MethodGet, "/products/"+productID), tc.err.Error())
+		assert.Equal(t, tc.want, status(t, app, fiber.MethodPost, "/products/list"), tc.err.Error())
+	}
+}
+
+func TestUpdateValidatesLikeCreate(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("principal", &auth.Principal{Scopes: []string{auth.ScopeProductsWrite}})
+		return c.Next()
+	})
+	NewHandler(mocks.NewProductUsecase(t), logrus.New()).Register(app.Group("/products"))
+
+	for _, body := range []string{`{"name":"Ka"}`, `{"type":"Minuman"}`} {
+		req := httptest.NewRequest(fiber.MethodPatch, "/products/"+productID, strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode, body)
+	}
+}
@@ -2,21 +2,85 @@ package product
 
 import "time"
 
+const (
+	KindSimple = "simple"
+	KindBundle = "bundle"
+
+	// PricingComputed bundles cost the sum of their components minus the
+	// discount; PricingFixed bundles keep the price they were given.
+	PricingComputed = "computed"
+	PricingFixed    = "fixed"
+)
+
 type Product struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name" validate:"required,min=3"`
-	Type      string    `json:"type" validate:"required,oneof=Sayuran Protein Buah Snack"`
-	Price     float64   `json:"price" validate:"required,gt=0"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         string      `json:"id"`
+	Name       string      `json:"name" validate:"required,min=3"`
+	Type       string      `json:"type" validate:"required,oneof=Sayuran Protein Buah Snack"`
+	Price      float64     `json:"price" validate:"required_unless=Kind bundle,gte=0"`
+	Barcode    string      `json:"barcode,omitempty" validate:"omitempty,gtin"`
+	Kind       string      `json:"kind" validate:"omitempty,oneof=simple bundle"`
+	Pricing    string      `json:"pricing,omitempty" validate:"omitempty,oneof=computed fixed"`
+	Discount   float64     `json:"discount,omitempty" validate:"gte=0"`
+	Components []Component `json:"components,omitempty" validate:"required_if=Kind bundle,dive"`
+	Status     string      `json:"status"`
+	PublishAt  *time.Time  `json:"publish_at,omitempty"`
+	MergedInto string      `json:"merged_into,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type Component struct {
+	ProductID string `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,gt=0"`
+}
+
+// Patch holds the fields of a partial update; nil fields are left alone.
+// Fields carry the same rules as on Product, except that whether a price
+// of zero is allowed depends on the kind of the stored product, which the
+// usecase checks.
+type Patch struct {
+	Name     *string  `json:"name,omitempty" validate:"omitempty,min=3"`
+	Type     *string  `json:"type,omitempty" validate:"omitempty,oneof=Sayuran Protein Buah Snack"`
+	Price    *float64 `json:"price,omitempty" validate:"omitempty,gte=0"`
+	Barcode  *string  `json:"barcode,omitempty" validate:"omitempty,gtin"`
+	Discount *float64 `json:"discount,omitempty" validate:"omitempty,gte=0"`
+}
+
+// Fields lists the names of the fields the patch writes, as used by the
+// access policy.
+func (p Patch) Fields() []string {
+	var fields []string
+	if p.Name != nil {
+		fields = append(fields, "name")
+	}
+	if p.Type != nil {
+		fields = append(fields, "type")
+	}
+	if p.Price != nil {
+		fields = append(fields, "price")
+	}
+	if p.Barcode != nil {
+		fields = append(fields, "barcode")
+	}
+	if p.Discount != nil {
+		fields = append(fields, "discount")
+	}
+	return fields
+}
+
+type CreateOptions struct {
+	// Force skips the near-duplicate check. Exact name and type matches are
+	// still rejected.
+	Force bool
 }
 
 type ListFilter struct {
-	Query    string
-	Type     string
-	SortBy   string
-	Order    string
-	Page     int
-	PageSize int
+	Query      string
+	Type       string
+	SortBy     string
+	Order      string
+	Page       int
+	PageSize   int
+	Visibility Visibility
 }
 
 type PaginatedResult struct {
@@ -30,3 +94,18 @@ type MetaPage struct {
 	Total     int `json:"total"`
 	TotalPage int `json:"total_page"`
 }
+
+// CacheKey is a cached key of the caller's tenant, without the tenant
+// prefix.
+type CacheKey struct {
+	Key        string  `json:"key"`
+	TTLSeconds float64 `json:"ttl_seconds"`
+}
+
+// CacheStats counts the cache lookups of one namespace on one instance.
+type CacheStats struct {
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	Errors   uint64  `json:"errors"`
+	HitRatio float64 `json:"hit_ratio"`
+}
@@ -0,0 +1,50 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound          = errors.New("product not found")
+	ErrInvalidID         = errors.New("invalid product id")
+	ErrDuplicate         = errors.New("product already exists")
+	ErrInvalidBarcode    = errors.New("invalid barcode")
+	ErrInvalidBundle     = errors.New("invalid bundle")
+	ErrInvalidPrice      = errors.New("invalid price")
+	ErrInUse             = errors.New("product is a component of a bundle")
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrInvalidMerge      = errors.New("invalid merge")
+	ErrInvalidPattern    = errors.New("invalid cache pattern")
+)
+
+// Match is an existing product whose name resembles the one being created.
+type Match struct {
+	Product Product `json:"product"`
+	Score   float64 `json:"score"`
+}
+
+// DuplicateError reports near-duplicates found on create. It matches
+// ErrDuplicate with errors.Is.
+type DuplicateError struct {
+	Matches []Match
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%v: found %d similar product(s), retry with force=true to create anyway", ErrDuplicate, len(e.Matches))
+}
+
+func (e *DuplicateError) Unwrap() error {
+	return ErrDuplicate
+}
+
+// MergedError is returned when a product has been merged into another one;
+// MergedInto is the ID of the surviving product.
+type MergedError struct {
+	ID         string
+	MergedInto string
+}
+
+func (e *MergedError) Error() string {
+	return fmt.Sprintf("product %s was merged into %s", e.ID, e.MergedInto)
+}
@@ -7,6 +7,8 @@ import (
 	product "simple-product-api/internal/product"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // ProductRepository is an autogenerated mock type for the ProductRepository type
@@ -22,6 +24,171 @@ func (_m *ProductRepository) EXPECT() *ProductRepository_Expecter {
 	return &ProductRepository_Expecter{mock: &_m.Mock}
 }
 
+// DeleteProduct provides a mock function with given fields: ctx, id
+func (_m *ProductRepository) DeleteProduct(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProduct")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ProductRepository_DeleteProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteProduct'
+type ProductRepository_DeleteProduct_Call struct {
+	*mock.Call
+}
+
+// DeleteProduct is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *ProductRepository_Expecter) DeleteProduct(ctx interface{}, id interface{}) *ProductRepository_DeleteProduct_Call {
+	return &ProductRepository_DeleteProduct_Call{Call: _e.mock.On("DeleteProduct", ctx, id)}
+}
+
+func (_c *ProductRepository_DeleteProduct_Call) Run(run func(ctx context.Context, id string)) *ProductRepository_DeleteProduct_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ProductRepository_DeleteProduct_Call) Return(_a0 error) *ProductRepository_DeleteProduct_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ProductRepository_DeleteProduct_Call) RunAndReturn(run func(context.Context, string) error) *ProductRepository_DeleteProduct_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindBundleIDsByComponent provides a mock function with given fields: ctx, componentID
+func (_m *ProductRepository) FindBundleIDsByComponent(ctx context.Context, componentID string) ([]string, error) {
+	ret := _m.Called(ctx, componentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindBundleIDsByComponent")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, componentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, componentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, componentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProductRepository_FindBundleIDsByComponent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindBundleIDsByComponent'
+type ProductRepository_FindBundleIDsByComponent_Call struct {
+	*mock.Call
+}
+
+// FindBundleIDsByComponent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - componentID string
+func (_e *ProductRepository_Expecter) FindBundleIDsByComponent(ctx interface{}, componentID interface{}) *ProductRepository_FindBundleIDsByComponent_Call {
+	return &ProductRepository_FindBundleIDsByComponent_Call{Call: _e.mock.On("FindBundleIDsByComponent", ctx, componentID)}
+}
+
+func (_c *ProductRepository_FindBundleIDsByComponent_Call) Run(run func(ctx context.Context, componentID string)) *ProductRepository_FindBundleIDsByComponent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ProductRepository_FindBundleIDsByComponent_Call) Return(_a0 []string, _a1 error) *ProductRepository_FindBundleIDsByComponent_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProductRepository_FindBundleIDsByComponent_Call) RunAndReturn(run func(context.Context, string) ([]string, error)) *ProductRepository_FindBundleIDsByComponent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindComponents provides a mock function with given fields: ctx, bundleID
+func (_m *ProductRepository) FindComponents(ctx context.Context, bundleID string) ([]product.Component, error) {
+	ret := _m.Called(ctx, bundleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindComponents")
+	}
+
+	var r0 []product.Component
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]product.Component, error)); ok {
+		return rf(ctx, bundleID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []product.Component); ok {
+		r0 = rf(ctx, bundleID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]product.Component)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, bundleID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProductRepository_FindComponents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindComponents'
+type ProductRepository_FindComponents_Call struct {
+	*mock.Call
+}
+
+// FindComponents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - bundleID string
+func (_e *ProductRepository_Expecter) FindComponents(ctx interface{}, bundleID interface{}) *ProductRepository_FindComponents_Call {
+	return &ProductRepository_FindComponents_Call{Call: _e.mock.On("FindComponents", ctx, bundleID)}
+}
+
+func (_c *ProductRepository_FindComponents_Call) Run(run func(ctx context.Context, bundleID string)) *ProductRepository_FindComponents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ProductRepository_FindComponents_Call) Return(_a0 []product.Component, _a1 error) *ProductRepository_FindComponents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProductRepository_FindComponents_Call) RunAndReturn(run func(context.Context, string) ([]product.Component, error)) *ProductRepository_FindComponents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // FindProduct provides a mock function with given fields: ctx, filter
 func (_m *ProductRepository) FindProduct(ctx context.Context, filter product.ListFilter) ([]product.Product, int, error) {
 	ret := _m.Called(ctx, filter)
@@ -88,6 +255,65 @@ func (_c *ProductRepository_FindProduct_Call) RunAndReturn(run func(context.Cont
 	return _c
 }
 
+// FindProductByBarcode provides a mock function with given fields: ctx, code
+func (_m *ProductRepository) FindProductByBarcode(ctx context.Context, code string) (*product.Product, error) {
+	ret := _m.Called(ctx, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindProductByBarcode")
+	}
+
+	var r0 *product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*product.Product, error)); ok {
+		return rf(ctx, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *product.Product); ok {
+		r0 = rf(ctx, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProductRepository_FindProductByBarcode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindProductByBarcode'
+type ProductRepository_FindProductByBarcode_Call struct {
+	*mock.Call
+}
+
+// FindProductByBarcode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - code string
+func (_e *ProductRepository_Expecter) FindProductByBarcode(ctx interface{}, code interface{}) *ProductRepository_FindProductByBarcode_Call {
+	return &ProductRepository_FindProductByBarcode_Call{Call: _e.mock.On("FindProductByBarcode", ctx, code)}
+}
+
+func (_c *ProductRepository_FindProductByBarcode_Call) Run(run func(ctx context.Context, code string)) *ProductRepository_FindProductByBarcode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ProductRepository_FindProductByBarcode_Call) Return(_a0 *product.Product, _a1 error) *ProductRepository_FindProductByBarcode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProductRepository_FindProductByBarcode_Call) RunAndReturn(run func(context.Context, string) (*product.Product, error)) *ProductRepository_FindProductByBarcode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // FindProductByID provides a mock function with given fields: ctx, id
 func (_m *ProductRepository) FindProductByID(ctx context.Context, id string) (*product.Product, error) {
 	ret := _m.Called(ctx, id)
@@ -207,6 +433,115 @@ func (_c *ProductRepository_FindProductByNameAndType_Call) RunAndReturn(run func
 	return _c
 }
 
+// FindSimilarProducts provides a mock function with given fields: ctx, name, ptype, limit
+func (_m *ProductRepository) FindSimilarProducts(ctx context.Context, name string, ptype string, limit int) ([]product.Product, error) {
+	ret := _m.Called(ctx, name, ptype, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindSimilarProducts")
+	}
+
+	var r0 []product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) ([]product.Product, error)); ok {
+		return rf(ctx, name, ptype, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []product.Product); ok {
+		r0 = rf(ctx, name, ptype, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, name, ptype, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProductRepository_FindSimilarProducts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindSimilarProducts'
+type ProductRepository_FindSimilarProducts_Call struct {
+	*mock.Call
+}
+
+// FindSimilarProducts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+//   - ptype string
+//   - limit int
+func (_e *ProductRepository_Expecter) FindSimilarProducts(ctx interface{}, name interface{}, ptype interface{}, limit interface{}) *ProductRepository_FindSimilarProducts_Call {
+	return &ProductRepository_FindSimilarProducts_Call{Call: _e.mock.On("FindSimilarProducts", ctx, name, ptype, limit)}
+}
+
+func (_c *ProductRepository_FindSimilarProducts_Call) Run(run func(ctx context.Context, name string, ptype string, limit int)) *ProductRepository_FindSimilarProducts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *ProductRepository_FindSimilarProducts_Call) Return(_a0 []product.Product, _a1 error) *ProductRepository_FindSimilarProducts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProductRepository_FindSimilarProducts_Call) RunAndReturn(run func(context.Context, string, string, int) ([]product.Product, error)) *ProductRepository_FindSimilarProducts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MergeProducts provides a mock function with given fields: ctx, targetID, sourceIDs
+func (_m *ProductRepository) MergeProducts(ctx context.Context, targetID string, sourceIDs []string) error {
+	ret := _m.Called(ctx, targetID, sourceIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergeProducts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) error); ok {
+		r0 = rf(ctx, targetID, sourceIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ProductRepository_MergeProducts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MergeProducts'
+type ProductRepository_MergeProducts_Call struct {
+	*mock.Call
+}
+
+// MergeProducts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - targetID string
+//   - sourceIDs []string
+func (_e *ProductRepository_Expecter) MergeProducts(ctx interface{}, targetID interface{}, sourceIDs interface{}) *ProductRepository_MergeProducts_Call {
+	return &ProductRepository_MergeProducts_Call{Call: _e.mock.On("MergeProducts", ctx, targetID, sourceIDs)}
+}
+
+func (_c *ProductRepository_MergeProducts_Call) Run(run func(ctx context.Context, targetID string, sourceIDs []string)) *ProductRepository_MergeProducts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *ProductRepository_MergeProducts_Call) Return(_a0 error) *ProductRepository_MergeProducts_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ProductRepository_MergeProducts_Call) RunAndReturn(run func(context.Context, string, []string) error) *ProductRepository_MergeProducts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SaveProduct provides a mock function with given fields: ctx, p
 func (_m *ProductRepository) SaveProduct(ctx context.Context, p *product.Product) error {
 	ret := _m.Called(ctx, p)
@@ -254,6 +589,162 @@ func (_c *ProductRepository_SaveProduct_Call) RunAndReturn(run func(context.Cont
 	return _c
 }
 
+// ScanProductIDs provides a mock function with given fields: ctx, fn
+func (_m *ProductRepository) ScanProductIDs(ctx context.Context, fn func(string)) error {
+	ret := _m.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScanProductIDs")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(string)) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ProductRepository_ScanProductIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ScanProductIDs'
+type ProductRepository_ScanProductIDs_Call struct {
+	*mock.Call
+}
+
+// ScanProductIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fn func(string)
+func (_e *ProductRepository_Expecter) ScanProductIDs(ctx interface{}, fn interface{}) *ProductRepository_ScanProductIDs_Call {
+	return &ProductRepository_ScanProductIDs_Call{Call: _e.mock.On("ScanProductIDs", ctx, fn)}
+}
+
+func (_c *ProductRepository_ScanProductIDs_Call) Run(run func(ctx context.Context, fn func(string))) *ProductRepository_ScanProductIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(func(string)))
+	})
+	return _c
+}
+
+func (_c *ProductRepository_ScanProductIDs_Call) Return(_a0 error) *ProductRepository_ScanProductIDs_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ProductRepository_ScanProductIDs_Call) RunAndReturn(run func(context.Context, func(string)) error) *ProductRepository_ScanProductIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateProduct provides a mock function with given fields: ctx, p
+func (_m *ProductRepository) UpdateProduct(ctx context.Context, p *product.Product) ([]product.Product, error) {
+	ret := _m.Called(ctx, p)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProduct")
+	}
+
+	var r0 []product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *product.Product) ([]product.Product, error)); ok {
+		return rf(ctx, p)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *product.Product) []product.Product); ok {
+		r0 = rf(ctx, p)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *product.Product) error); ok {
+		r1 = rf(ctx, p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProductRepository_UpdateProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateProduct'
+type ProductRepository_UpdateProduct_Call struct {
+	*mock.Call
+}
+
+// UpdateProduct is a helper method to define mock.On call
+//   - ctx context.Context
+//   - p *product.Product
+func (_e *ProductRepository_Expecter) UpdateProduct(ctx interface{}, p interface{}) *ProductRepository_UpdateProduct_Call {
+	return &ProductRepository_UpdateProduct_Call{Call: _e.mock.On("UpdateProduct", ctx, p)}
+}
+
+func (_c *ProductRepository_UpdateProduct_Call) Run(run func(ctx context.Context, p *product.Product)) *ProductRepository_UpdateProduct_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*product.Product))
+	})
+	return _c
+}
+
+func (_c *ProductRepository_UpdateProduct_Call) Return(_a0 []product.Product, _a1 error) *ProductRepository_UpdateProduct_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProductRepository_UpdateProduct_Call) RunAndReturn(run func(context.Context, *product.Product) ([]product.Product, error)) *ProductRepository_UpdateProduct_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, id, from, to, publishAt
+func (_m *ProductRepository) UpdateStatus(ctx context.Context, id string, from string, to string, publishAt *time.Time) error {
+	ret := _m.Called(ctx, id, from, to, publishAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, *time.Time) error); ok {
+		r0 = rf(ctx, id, from, to, publishAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ProductRepository_UpdateStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateStatus'
+type ProductRepository_UpdateStatus_Call struct {
+	*mock.Call
+}
+
+// UpdateStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - from string
+//   - to string
+//   - publishAt *time.Time
+func (_e *ProductRepository_Expecter) UpdateStatus(ctx interface{}, id interface{}, from interface{}, to interface{}, publishAt interface{}) *ProductRepository_UpdateStatus_Call {
+	return &ProductRepository_UpdateStatus_Call{Call: _e.mock.On("UpdateStatus", ctx, id, from, to, publishAt)}
+}
+
+func (_c *ProductRepository_UpdateStatus_Call) Run(run func(ctx context.Context, id string, from string, to string, publishAt *time.Time)) *ProductRepository_UpdateStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(*time.Time))
+	})
+	return _c
+}
+
+func (_c *ProductRepository_UpdateStatus_Call) Return(_a0 error) *ProductRepository_UpdateStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ProductRepository_UpdateStatus_Call) RunAndReturn(run func(context.Context, string, string, string, *time.Time) error) *ProductRepository_UpdateStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewProductRepository creates a new instance of ProductRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewProductRepository(t interface {
@@ -7,6 +7,8 @@ import (
 	product "simple-product-api/internal/product"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // ProductUsecase is an autogenerated mock type for the ProductUsecase type
@@ -22,17 +24,185 @@ func (_m *ProductUsecase) EXPECT() *ProductUsecase_Expecter {
 	return &ProductUsecase_Expecter{mock: &_m.Mock}
 }
 
-// CreateProduct provides a mock function with given fields: ctx, p
-func (_m *ProductUsecase) CreateProduct(ctx context.Context, p *product.Product) error {
-	ret := _m.Called(ctx, p)
+// CacheKeys provides a mock function with given fields: ctx, pattern, limit
+func (_m *ProductUsecase) CacheKeys(ctx context.Context, pattern string, limit int) ([]product.CacheKey, error) {
+	ret := _m.Called(ctx, pattern, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CacheKeys")
+	}
+
+	var r0 []product.CacheKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]product.CacheKey, error)); ok {
+		return rf(ctx, pattern, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []product.CacheKey); ok {
+		r0 = rf(ctx, pattern, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]product.CacheKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, pattern, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProductUsecase_CacheKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CacheKeys'
+type ProductUsecase_CacheKeys_Call struct {
+	*mock.Call
+}
+
+// CacheKeys is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pattern string
+//   - limit int
+func (_e *ProductUsecase_Expecter) CacheKeys(ctx interface{}, pattern interface{}, limit interface{}) *ProductUsecase_CacheKeys_Call {
+	return &ProductUsecase_CacheKeys_Call{Call: _e.mock.On("CacheKeys", ctx, pattern, limit)}
+}
+
+func (_c *ProductUsecase_CacheKeys_Call) Run(run func(ctx context.Context, pattern string, limit int)) *ProductUsecase_CacheKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *ProductUsecase_CacheKeys_Call) Return(_a0 []product.CacheKey, _a1 error) *ProductUsecase_CacheKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProductUsecase_CacheKeys_Call) RunAndReturn(run func(context.Context, string, int) ([]product.CacheKey, error)) *ProductUsecase_CacheKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CacheStats provides a mock function with no fields
+func (_m *ProductUsecase) CacheStats() map[string]product.CacheStats {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CacheStats")
+	}
+
+	var r0 map[string]product.CacheStats
+	if rf, ok := ret.Get(0).(func() map[string]product.CacheStats); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]product.CacheStats)
+		}
+	}
+
+	return r0
+}
+
+// ProductUsecase_CacheStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CacheStats'
+type ProductUsecase_CacheStats_Call struct {
+	*mock.Call
+}
+
+// CacheStats is a helper method to define mock.On call
+func (_e *ProductUsecase_Expecter) CacheStats() *ProductUsecase_CacheStats_Call {
+	return &ProductUsecase_CacheStats_Call{Call: _e.mock.On("CacheStats")}
+}
+
+func (_c *ProductUsecase_CacheStats_Call) Run(run func()) *ProductUsecase_CacheStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *ProductUsecase_CacheStats_Call) Return(_a0 map[string]product.CacheStats) *ProductUsecase_CacheStats_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ProductUsecase_CacheStats_Call) RunAndReturn(run func() map[string]product.CacheStats) *ProductUsecase_CacheStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ChangeStatus provides a mock function with given fields: ctx, id, status, publishAt
+func (_m *ProductUsecase) ChangeStatus(ctx context.Context, id string, status string, publishAt *time.Time) (*product.Product, error) {
+	ret := _m.Called(ctx, id, status, publishAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangeStatus")
+	}
+
+	var r0 *product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *time.Time) (*product.Product, error)); ok {
+		return rf(ctx, id, status, publishAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *time.Time) *product.Product); ok {
+		r0 = rf(ctx, id, status, publishAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *time.Time) error); ok {
+		r1 = rf(ctx, id, status, publishAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProductUsecase_ChangeStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChangeStatus'
+type ProductUsecase_ChangeStatus_Call struct {
+	*mock.Call
+}
+
+// ChangeStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - status string
+//   - publishAt *time.Time
+func (_e *ProductUsecase_Expecter) ChangeStatus(ctx interface{}, id interface{}, status interface{}, publishAt interface{}) *ProductUsecase_ChangeStatus_Call {
+	return &ProductUsecase_ChangeStatus_Call{Call: _e.mock.On("ChangeStatus", ctx, id, status, publishAt)}
+}
+
+func (_c *ProductUsecase_ChangeStatus_Call) Run(run func(ctx context.Context, id string, status string, publishAt *time.Time)) *ProductUsecase_ChangeStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(*time.Time))
+	})
+	return _c
+}
+
+func (_c *ProductUsecase_ChangeStatus_Call) Return(_a0 *product.Product, _a1 error) *ProductUsecase_ChangeStatus_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProductUsecase_ChangeStatus_Call) RunAndReturn(run func(context.Context, string, string, *time.Time) (*product.Product, error)) *ProductUsecase_ChangeStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateProduct provides a mock function with given fields: ctx, p, opts
+func (_m *ProductUsecase) CreateProduct(ctx context.Context, p *product.Product, opts product.CreateOptions) error {
+	ret := _m.Called(ctx, p, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateProduct")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *product.Product) error); ok {
-		r0 = rf(ctx, p)
+	if rf, ok := ret.Get(0).(func(context.Context, *product.Product, product.CreateOptions) error); ok {
+		r0 = rf(ctx, p, opts)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -48,13 +218,14 @@ type ProductUsecase_CreateProduct_Call struct {
 // CreateProduct is a helper method to define mock.On call
 //   - ctx context.Context
 //   - p *product.Product
-func (_e *ProductUsecase_Expecter) CreateProduct(ctx interface{}, p interface{}) *ProductUsecase_CreateProduct_Call {
-	return &ProductUsecase_CreateProduct_Call{Call: _e.mock.On("CreateProduct", ctx, p)}
+//   - opts product.CreateOptions
+func (_e *ProductUsecase_Expecter) CreateProduct(ctx interface{}, p interface{}, opts interface{}) *ProductUsecase_CreateProduct_Call {
+	return &ProductUsecase_CreateProduct_Call{Call: _e.mock.On("CreateProduct", ctx, p, opts)}
 }
 
-func (_c *ProductUsecase_CreateProduct_Call) Run(run func(ctx context.Context, p *product.Product)) *ProductUsecase_CreateProduct_Call {
+func (_c *ProductUsecase_CreateProduct_Call) Run(run func(ctx context.Context, p *product.Product, opts product.CreateOptions)) *ProductUsecase_CreateProduct_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*product.Product))
+		run(args[0].(context.Context), args[1].(*product.Product), args[2].(product.CreateOptions))
 	})
 	return _c
 }
@@ -64,34 +235,198 @@ func (_c *ProductUsecase_CreateProduct_Call) Return(_a0 error) *ProductUsecase_C
 	return _c
 }
 
-func (_c *ProductUsecase_CreateProduct_Call) RunAndReturn(run func(context.Context, *product.Product) error) *ProductUsecase_CreateProduct_Call {
+func (_c *ProductUsecase_CreateProduct_Call) RunAndReturn(run func(context.Context, *product.Product, product.CreateOptions) error) *ProductUsecase_CreateProduct_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetProductByID provides a mock function with given fields: ctx, id
-func (_m *ProductUsecase) GetProductByID(ctx context.Context, id string) (*product.Product, error) {
+// DeleteProduct provides a mock function with given fields: ctx, id
+func (_m *ProductUsecase) DeleteProduct(ctx context.Context, id string) error {
 	ret := _m.Called(ctx, id)
 
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProduct")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ProductUsecase_DeleteProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteProduct'
+type ProductUsecase_DeleteProduct_Call struct {
+	*mock.Call
+}
+
+// DeleteProduct is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *ProductUsecase_Expecter) DeleteProduct(ctx interface{}, id interface{}) *ProductUsecase_DeleteProduct_Call {
+	return &ProductUsecase_DeleteProduct_Call{Call: _e.mock.On("DeleteProduct", ctx, id)}
+}
+
+func (_c *ProductUsecase_DeleteProduct_Call) Run(run func(ctx context.Context, id string)) *ProductUsecase_DeleteProduct_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ProductUsecase_DeleteProduct_Call) Return(_a0 error) *ProductUsecase_DeleteProduct_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ProductUsecase_DeleteProduct_Call) RunAndReturn(run func(context.Context, string) error) *ProductUsecase_DeleteProduct_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FlushCache provides a mock function with given fields: ctx, pattern
+func (_m *ProductUsecase) FlushCache(ctx context.Context, pattern string) (int, error) {
+	ret := _m.Called(ctx, pattern)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FlushCache")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, pattern)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, pattern)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, pattern)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProductUsecase_FlushCache_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FlushCache'
+type ProductUsecase_FlushCache_Call struct {
+	*mock.Call
+}
+
+// FlushCache is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pattern string
+func (_e *ProductUsecase_Expecter) FlushCache(ctx interface{}, pattern interface{}) *ProductUsecase_FlushCache_Call {
+	return &ProductUsecase_FlushCache_Call{Call: _e.mock.On("FlushCache", ctx, pattern)}
+}
+
+func (_c *ProductUsecase_FlushCache_Call) Run(run func(ctx context.Context, pattern string)) *ProductUsecase_FlushCache_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ProductUsecase_FlushCache_Call) Return(_a0 int, _a1 error) *ProductUsecase_FlushCache_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProductUsecase_FlushCache_Call) RunAndReturn(run func(context.Context, string) (int, error)) *ProductUsecase_FlushCache_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetProductByBarcode provides a mock function with given fields: ctx, code, vis
+func (_m *ProductUsecase) GetProductByBarcode(ctx context.Context, code string, vis product.Visibility) (*product.Product, error) {
+	ret := _m.Called(ctx, code, vis)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProductByBarcode")
+	}
+
+	var r0 *product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, product.Visibility) (*product.Product, error)); ok {
+		return rf(ctx, code, vis)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, product.Visibility) *product.Product); ok {
+		r0 = rf(ctx, code, vis)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, product.Visibility) error); ok {
+		r1 = rf(ctx, code, vis)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProductUsecase_GetProductByBarcode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProductByBarcode'
+type ProductUsecase_GetProductByBarcode_Call struct {
+	*mock.Call
+}
+
+// GetProductByBarcode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - code string
+//   - vis product.Visibility
+func (_e *ProductUsecase_Expecter) GetProductByBarcode(ctx interface{}, code interface{}, vis interface{}) *ProductUsecase_GetProductByBarcode_Call {
+	return &ProductUsecase_GetProductByBarcode_Call{Call: _e.mock.On("GetProductByBarcode", ctx, code, vis)}
+}
+
+func (_c *ProductUsecase_GetProductByBarcode_Call) Run(run func(ctx context.Context, code string, vis product.Visibility)) *ProductUsecase_GetProductByBarcode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(product.Visibility))
+	})
+	return _c
+}
+
+func (_c *ProductUsecase_GetProductByBarcode_Call) Return(_a0 *product.Product, _a1 error) *ProductUsecase_GetProductByBarcode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProductUsecase_GetProductByBarcode_Call) RunAndReturn(run func(context.Context, string, product.Visibility) (*product.Product, error)) *ProductUsecase_GetProductByBarcode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetProductByID provides a mock function with given fields: ctx, id, vis
+func (_m *ProductUsecase) GetProductByID(ctx context.Context, id string, vis product.Visibility) (*product.Product, error) {
+	ret := _m.Called(ctx, id, vis)
+
 	if len(ret) == 0 {
 		panic("no return value specified for GetProductByID")
 	}
 
 	var r0 *product.Product
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*product.Product, error)); ok {
-		return rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, product.Visibility) (*product.Product, error)); ok {
+		return rf(ctx, id, vis)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *product.Product); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, product.Visibility) *product.Product); ok {
+		r0 = rf(ctx, id, vis)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*product.Product)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, string, product.Visibility) error); ok {
+		r1 = rf(ctx, id, vis)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -107,13 +442,14 @@ type ProductUsecase_GetProductByID_Call struct {
 // GetProductByID is a helper method to define mock.On call
 //   - ctx context.Context
 //   - id string
-func (_e *ProductUsecase_Expecter) GetProductByID(ctx interface{}, id interface{}) *ProductUsecase_GetProductByID_Call {
-	return &ProductUsecase_GetProductByID_Call{Call: _e.mock.On("GetProductByID", ctx, id)}
+//   - vis product.Visibility
+func (_e *ProductUsecase_Expecter) GetProductByID(ctx interface{}, id interface{}, vis interface{}) *ProductUsecase_GetProductByID_Call {
+	return &ProductUsecase_GetProductByID_Call{Call: _e.mock.On("GetProductByID", ctx, id, vis)}
 }
 
-func (_c *ProductUsecase_GetProductByID_Call) Run(run func(ctx context.Context, id string)) *ProductUsecase_GetProductByID_Call {
+func (_c *ProductUsecase_GetProductByID_Call) Run(run func(ctx context.Context, id string, vis product.Visibility)) *ProductUsecase_GetProductByID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(product.Visibility))
 	})
 	return _c
 }
@@ -123,7 +459,7 @@ func (_c *ProductUsecase_GetProductByID_Call) Return(_a0 *product.Product, _a1 e
 	return _c
 }
 
-func (_c *ProductUsecase_GetProductByID_Call) RunAndReturn(run func(context.Context, string) (*product.Product, error)) *ProductUsecase_GetProductByID_Call {
+func (_c *ProductUsecase_GetProductByID_Call) RunAndReturn(run func(context.Context, string, product.Visibility) (*product.Product, error)) *ProductUsecase_GetProductByID_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -194,6 +530,126 @@ func (_c *ProductUsecase_ListProduct_Call) RunAndReturn(run func(context.Context
 	return _c
 }
 
+// MergeProducts provides a mock function with given fields: ctx, targetID, sourceIDs
+func (_m *ProductUsecase) MergeProducts(ctx context.Context, targetID string, sourceIDs []string) (*product.Product, error) {
+	ret := _m.Called(ctx, targetID, sourceIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergeProducts")
+	}
+
+	var r0 *product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (*product.Product, error)); ok {
+		return rf(ctx, targetID, sourceIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *product.Product); ok {
+		r0 = rf(ctx, targetID, sourceIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, targetID, sourceIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProductUsecase_MergeProducts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MergeProducts'
+type ProductUsecase_MergeProducts_Call struct {
+	*mock.Call
+}
+
+// MergeProducts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - targetID string
+//   - sourceIDs []string
+func (_e *ProductUsecase_Expecter) MergeProducts(ctx interface{}, targetID interface{}, sourceIDs interface{}) *ProductUsecase_MergeProducts_Call {
+	return &ProductUsecase_MergeProducts_Call{Call: _e.mock.On("MergeProducts", ctx, targetID, sourceIDs)}
+}
+
+func (_c *ProductUsecase_MergeProducts_Call) Run(run func(ctx context.Context, targetID string, sourceIDs []string)) *ProductUsecase_MergeProducts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *ProductUsecase_MergeProducts_Call) Return(_a0 *product.Product, _a1 error) *ProductUsecase_MergeProducts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProductUsecase_MergeProducts_Call) RunAndReturn(run func(context.Context, string, []string) (*product.Product, error)) *ProductUsecase_MergeProducts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateProduct provides a mock function with given fields: ctx, id, patch
+func (_m *ProductUsecase) UpdateProduct(ctx context.Context, id string, patch product.Patch) (*product.Product, error) {
+	ret := _m.Called(ctx, id, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProduct")
+	}
+
+	var r0 *product.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, product.Patch) (*product.Product, error)); ok {
+		return rf(ctx, id, patch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, product.Patch) *product.Product); ok {
+		r0 = rf(ctx, id, patch)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*product.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, product.Patch) error); ok {
+		r1 = rf(ctx, id, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProductUsecase_UpdateProduct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateProduct'
+type ProductUsecase_UpdateProduct_Call struct {
+	*mock.Call
+}
+
+// UpdateProduct is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - patch product.Patch
+func (_e *ProductUsecase_Expecter) UpdateProduct(ctx interface{}, id interface{}, patch interface{}) *ProductUsecase_UpdateProduct_Call {
+	return &ProductUsecase_UpdateProduct_Call{Call: _e.mock.On("UpdateProduct", ctx, id, patch)}
+}
+
+func (_c *ProductUsecase_UpdateProduct_Call) Run(run func(ctx context.Context, id string, patch product.Patch)) *ProductUsecase_UpdateProduct_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(product.Patch))
+	})
+	return _c
+}
+
+func (_c *ProductUsecase_UpdateProduct_Call) Return(_a0 *product.Product, _a1 error) *ProductUsecase_UpdateProduct_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProductUsecase_UpdateProduct_Call) RunAndReturn(run func(context.Context, string, product.Patch) (*product.Product, error)) *ProductUsecase_UpdateProduct_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewProductUsecase creates a new instance of ProductUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewProductUsecase(t interface {
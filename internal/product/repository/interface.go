@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	model "simple-product-api/internal/product"
+	"time"
 )
 
 type ProductRepository interface {
@@ -10,4 +11,13 @@ type ProductRepository interface {
 	FindProduct(ctx context.Context, filter model.ListFilter) ([]model.Product, int, error)
 	FindProductByID(ctx context.Context, id string) (*model.Product, error)
 	FindProductByNameAndType(ctx context.Context, name string, ptype string) (*model.Product, error)
+	FindSimilarProducts(ctx context.Context, name, ptype string, limit int) ([]model.Product, error)
+	FindProductByBarcode(ctx context.Context, code string) (*model.Product, error)
+	FindComponents(ctx context.Context, bundleID string) ([]model.Component, error)
+	FindBundleIDsByComponent(ctx context.Context, componentID string) ([]string, error)
+	UpdateProduct(ctx context.Context, p *model.Product) ([]model.Product, error)
+	DeleteProduct(ctx context.Context, id string) error
+	UpdateStatus(ctx context.Context, id, from, to string, publishAt *time.Time) error
+	MergeProducts(ctx context.Context, targetID string, sourceIDs []string) error
+	ScanProductIDs(ctx context.Context, fn func(id string)) error
 }
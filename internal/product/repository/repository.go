@@ -3,51 +3,112 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 	"simple-product-api/internal/product"
+	"simple-product-api/pkg/breaker"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/deadline"
+	"simple-product-api/pkg/fuzzy"
+	"simple-product-api/pkg/metrics"
+	"simple-product-api/pkg/retry"
+	"simple-product-api/pkg/tenant"
+	"simple-product-api/pkg/tracing"
 	"strings"
+	"time"
 )
 
+const productColumns = `id, name, type, price, COALESCE(barcode, ''), kind, COALESCE(pricing, ''), discount, status, publish_at, COALESCE(merged_into::text, ''), created_at`
+
 type RepositoryPostgre struct {
-	db  *sql.DB
-	Log *logrus.Logger
+	db      *sql.DB
+	Log     *logrus.Logger
+	retry   *retry.Retrier
+	breaker *breaker.Breaker
+
+	// timeout bounds each repository call, including its retries.
+	timeout time.Duration
+	// similarity is the least trigram similarity of the names
+	// FindSimilarProducts returns.
+	similarity float64
+}
+
+// trgmThreshold is pg_trgm's default similarity_threshold, the one the %
+// operator filters with.
+const trgmThreshold = 0.3
+
+func NewPostgresRepo(db *sql.DB, log *logrus.Logger, retrier *retry.Retrier, cb *breaker.Breaker, cfg *config.Config) *RepositoryPostgre {
+	return &RepositoryPostgre{db: db, Log: log, retry: retrier, breaker: cb, timeout: cfg.DBTimeout, similarity: cfg.DuplicateThreshold}
 }
 
-func NewPostgresRepo(db *sql.DB, log *logrus.Logger) *RepositoryPostgre {
-	return &RepositoryPostgre{db: db, Log: log}
+// call runs fn through the circuit breaker, which fails it fast with
+// breaker.ErrOpen while Postgres is down or too slow to answer, and retries
+// it on errors that classify accepts.
+func (r *RepositoryPostgre) call(ctx context.Context, classify retry.Classifier, fn func() error) error {
+	return r.breaker.Do(retry.Outage(ctx, retry.PostgresRead), func() error {
+		return r.retry.Do(ctx, classify, fn)
+	})
 }
 
 func (r *RepositoryPostgre) SaveProduct(ctx context.Context, p *product.Product) error {
-	query := `INSERT INTO products (id, name, type, price, created_at)
-	          VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.db.ExecContext(ctx, query, p.ID, p.Name, p.Type, p.Price, p.CreatedAt)
-	if err != nil {
-		r.Log.WithError(err).Error("error inserting product")
-		return err
-	}
-	return err
+	defer metrics.ObserveQuery("products", "SaveProduct", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	tenantID := tenant.FromContext(ctx)
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		query := `INSERT INTO products (id, name, type, price, barcode, kind, pricing, discount, status, publish_at, created_at, tenant_id, search_name)
+		          VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, NULLIF($7, ''), $8, $9, $10, $11, $12, $13)`
+		_, err := txExec(ctx, tx, query, p.ID, p.Name, p.Type, p.Price, p.Barcode, p.Kind, p.Pricing, p.Discount, p.Status, utc(p.PublishAt), p.CreatedAt, tenantID, fuzzy.Normalize(p.Name))
+		if err != nil {
+			r.Log.WithContext(ctx).WithError(err).Error("error inserting product")
+			if isUniqueViolation(err) {
+				return fmt.Errorf("%w: %v", product.ErrDuplicate, err)
+			}
+			return err
+		}
+
+		for _, c := range p.Components {
+			_, err = txExec(ctx, tx, `INSERT INTO product_components (bundle_id, component_id, quantity, tenant_id) VALUES ($1, $2, $3, $4)`,
+				p.ID, c.ProductID, c.Quantity, tenantID)
+			if err != nil {
+				r.Log.WithContext(ctx).WithError(err).Error("error inserting bundle component")
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 func (r *RepositoryPostgre) FindProductByID(ctx context.Context, id string) (*product.Product, error) {
-	query := `SELECT id, name, type, price, created_at FROM products WHERE id = $1`
-	row := r.db.QueryRowContext(ctx, query, id)
+	defer metrics.ObserveQuery("products", "FindProductByID", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
 
-	var p product.Product
-	err := row.Scan(&p.ID, &p.Name, &p.Type, &p.Price, &p.CreatedAt)
+	query := `SELECT ` + productColumns + ` FROM products WHERE id = $1 AND tenant_id = $2`
+	p, err := r.findOne(ctx, query, id, tenant.FromContext(ctx))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %w", product.ErrNotFound, err)
+	}
 	if err != nil {
-		r.Log.WithError(err).Errorf("error find product by id: %v", id)
+		r.Log.WithContext(ctx).WithError(err).Errorf("error find product by id: %v", id)
 		return nil, err
 	}
-	return &p, nil
+	return p, nil
 }
 
 func (r *RepositoryPostgre) FindProduct(ctx context.Context, f product.ListFilter) (products []product.Product, total int, err error) {
-	baseQuery := `SELECT id, name, type, price, created_at, COUNT(*) OVER() as total_count FROM products`
-	var clauses []string
-	var args []interface{}
+	defer metrics.ObserveQuery("products", "FindProduct", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	baseQuery := `SELECT ` + productColumns + `, COUNT(*) OVER() as total_count FROM products`
+	clauses := []string{"tenant_id = $1", "merged_into IS NULL"}
+	args := []interface{}{tenant.FromContext(ctx)}
 
-	argIndex := 1
+	argIndex := 2
 
 	if f.Query != "" {
 		clauses = append(clauses, fmt.Sprintf("LOWER(name) LIKE LOWER($%d)", argIndex))
@@ -61,10 +122,12 @@ func (r *RepositoryPostgre) FindProduct(ctx context.Context, f product.ListFilte
 		argIndex++
 	}
 
-	if len(clauses) > 0 {
-		baseQuery += " WHERE " + strings.Join(clauses, " AND ")
+	if f.Visibility != product.VisibilityAll {
+		clauses = append(clauses, "status = 'active' AND (publish_at IS NULL OR publish_at <= NOW())")
 	}
 
+	baseQuery += " WHERE " + strings.Join(clauses, " AND ")
+
 	orderBy := "created_at"
 	if f.SortBy == "name" || f.SortBy == "price" || f.SortBy == "created_at" {
 		orderBy = f.SortBy
@@ -79,36 +142,454 @@ func (r *RepositoryPostgre) FindProduct(ctx context.Context, f product.ListFilte
 	offset := (f.Page - 1) * f.PageSize
 	baseQuery += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
 
-	rows, err := r.db.QueryContext(ctx, baseQuery, args...)
+	rows, err := r.query(ctx, baseQuery, args...)
 	if err != nil {
-		r.Log.WithError(err).Error(fmt.Sprintf("error find product using filter: %+v", f))
+		r.Log.WithContext(ctx).WithError(err).Error(fmt.Sprintf("error find product using filter: %+v", f))
 		return nil, total, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var p product.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Type, &p.Price, &p.CreatedAt, &total); err != nil {
-			r.Log.WithError(err).Error("error row scan in find product using filter")
+		p, err := scanProduct(rows, &total)
+		if err != nil {
+			r.Log.WithContext(ctx).WithError(err).Error("error row scan in find product using filter")
 			return nil, total, err
 		}
-		products = append(products, p)
+		products = append(products, *p)
 	}
 	return products, total, nil
 }
 
 func (r *RepositoryPostgre) FindProductByNameAndType(ctx context.Context, name, ptype string) (*product.Product, error) {
-	query := `SELECT id, name, type, price, created_at FROM products WHERE LOWER(name) = LOWER($1) AND LOWER(type) = LOWER($2) LIMIT 1`
-	row := r.db.QueryRowContext(ctx, query, name, ptype)
+	defer metrics.ObserveQuery("products", "FindProductByNameAndType", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT ` + productColumns + ` FROM products WHERE search_name = $1 AND LOWER(type) = LOWER($2) AND merged_into IS NULL AND tenant_id = $3 LIMIT 1`
+	p, err := r.findOne(ctx, query, fuzzy.Normalize(name), ptype, tenant.FromContext(ctx))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Log.WithContext(ctx).WithError(err).Error("error find product same name and type")
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindSimilarProducts returns up to limit products of the type whose
+// normalized names have at least the configured trigram similarity to
+// name, most similar first. The trigram index is only used for thresholds
+// of at least pg_trgm's default of 0.3, since the % operator it serves
+// filters at that threshold; lower ones read every product of the type.
+func (r *RepositoryPostgre) FindSimilarProducts(ctx context.Context, name, ptype string, limit int) ([]product.Product, error) {
+	defer metrics.ObserveQuery("products", "FindSimilarProducts", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	where := `similarity(search_name, $1) >= $5`
+	if r.similarity >= trgmThreshold {
+		where = `search_name % $1 AND ` + where
+	}
+	query := `SELECT ` + productColumns + ` FROM products
+	          WHERE ` + where + ` AND LOWER(type) = LOWER($2) AND merged_into IS NULL AND tenant_id = $3
+	          ORDER BY similarity(search_name, $1) DESC LIMIT $4`
+	rows, err := r.query(ctx, query, fuzzy.Normalize(name), ptype, tenant.FromContext(ctx), limit, r.similarity)
+	if err != nil {
+		r.Log.WithContext(ctx).WithError(err).Errorf("error find products similar to: %v", name)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []product.Product
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			r.Log.WithContext(ctx).WithError(err).Error("error row scan in find similar products")
+			return nil, err
+		}
+		products = append(products, *p)
+	}
+	return products, rows.Err()
+}
 
-	var product product.Product
-	err := row.Scan(&product.ID, &product.Name, &product.Type, &product.Price, &product.CreatedAt)
+func (r *RepositoryPostgre) FindProductByBarcode(ctx context.Context, code string) (*product.Product, error) {
+	defer metrics.ObserveQuery("products", "FindProductByBarcode", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT ` + productColumns + ` FROM products WHERE barcode = $1 AND tenant_id = $2`
+	p, err := r.findOne(ctx, query, code, tenant.FromContext(ctx))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
-		r.Log.WithError(err).Error("error find product same name and type")
+		r.Log.WithContext(ctx).WithError(err).Errorf("error find product by barcode: %v", code)
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *RepositoryPostgre) FindComponents(ctx context.Context, bundleID string) ([]product.Component, error) {
+	defer metrics.ObserveQuery("products", "FindComponents", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT component_id, quantity FROM product_components WHERE bundle_id = $1 AND tenant_id = $2 ORDER BY component_id`
+	rows, err := r.query(ctx, query, bundleID, tenant.FromContext(ctx))
+	if err != nil {
+		r.Log.WithContext(ctx).WithError(err).Errorf("error find components of bundle: %v", bundleID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var components []product.Component
+	for rows.Next() {
+		var c product.Component
+		if err := rows.Scan(&c.ProductID, &c.Quantity); err != nil {
+			r.Log.WithContext(ctx).WithError(err).Error("error row scan in find components")
+			return nil, err
+		}
+		components = append(components, c)
+	}
+	return components, rows.Err()
+}
+
+func (r *RepositoryPostgre) FindBundleIDsByComponent(ctx context.Context, componentID string) ([]string, error) {
+	defer metrics.ObserveQuery("products", "FindBundleIDsByComponent", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT bundle_id FROM product_components WHERE component_id = $1 AND tenant_id = $2 ORDER BY bundle_id`
+	rows, err := r.query(ctx, query, componentID, tenant.FromContext(ctx))
+	if err != nil {
+		r.Log.WithContext(ctx).WithError(err).Errorf("error find bundles using component: %v", componentID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			r.Log.WithContext(ctx).WithError(err).Error("error row scan in find bundles by component")
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ScanProductIDs calls fn with the ID of every product of every tenant. It
+// reads the whole table, so it is bounded only by ctx and not by the
+// per-call timeout.
+func (r *RepositoryPostgre) ScanProductIDs(ctx context.Context, fn func(id string)) error {
+	defer metrics.ObserveQuery("products", "ScanProductIDs", time.Now())
+	rows, err := r.query(ctx, `SELECT id FROM products`)
+	if err != nil {
+		r.Log.WithContext(ctx).WithError(err).Error("error scan product ids")
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			r.Log.WithContext(ctx).WithError(err).Error("error row scan in scan product ids")
+			return err
+		}
+		fn(id)
+	}
+	return rows.Err()
+}
+
+func (r *RepositoryPostgre) DeleteProduct(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("products", "DeleteProduct", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	res, err := r.exec(ctx, `DELETE FROM products WHERE id = $1 AND merged_into IS NULL AND tenant_id = $2`, id, tenant.FromContext(ctx))
+	if err != nil {
+		r.Log.WithContext(ctx).WithError(err).Errorf("error delete product: %v", id)
+		if isForeignKeyViolation(err, "products_merged_into_fkey") {
+			return fmt.Errorf("%w: other products were merged into %s", product.ErrInUse, id)
+		}
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("%w: %v", product.ErrInUse, err)
+		}
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return product.ErrNotFound
+	}
+	return nil
+}
+
+// UpdateProduct writes the editable fields of an existing product. In the
+// same transaction it recalculates the price of every computed bundle that
+// contains the product, directly or through other bundles, and returns the
+// bundles whose price changed. It fails with ErrInvalidBundle when a bundle
+// would no longer cost more than zero.
+func (r *RepositoryPostgre) UpdateProduct(ctx context.Context, p *product.Product) (repriced []product.Product, err error) {
+	defer metrics.ObserveQuery("products", "UpdateProduct", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	tenantID := tenant.FromContext(ctx)
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		repriced = nil
+		query := `UPDATE products SET name = $1, type = $2, price = $3, barcode = NULLIF($4, ''), discount = $5, search_name = $8
+		          WHERE id = $6 AND merged_into IS NULL AND tenant_id = $7`
+		res, err := txExec(ctx, tx, query, p.Name, p.Type, p.Price, p.Barcode, p.Discount, p.ID, tenantID, fuzzy.Normalize(p.Name))
+		if err != nil {
+			r.Log.WithContext(ctx).WithError(err).Errorf("error update product: %v", p.ID)
+			if isUniqueViolation(err) {
+				return fmt.Errorf("%w: %v", product.ErrDuplicate, err)
+			}
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return product.ErrNotFound
+		}
+
+		repriced, err = r.repriceBundles(ctx, tx, p.ID)
+		return err
+	})
+	return repriced, err
+}
+
+// repriceBundles recalculates the computed bundles containing the product,
+// then those containing the bundles that changed, and so on up the graph,
+// which checkCycles keeps free of cycles.
+func (r *RepositoryPostgre) repriceBundles(ctx context.Context, tx *sql.Tx, id string) ([]product.Product, error) {
+	query := `UPDATE products b SET price = s.total - b.discount
+	          FROM (SELECT pc.bundle_id, SUM(c.price * pc.quantity) AS total
+	                FROM product_components pc JOIN products c ON c.id = pc.component_id
+	                WHERE pc.tenant_id = $2 AND pc.bundle_id IN (SELECT bundle_id FROM product_components WHERE component_id = ANY($1) AND tenant_id = $2)
+	                GROUP BY pc.bundle_id) s
+	          WHERE b.id = s.bundle_id AND b.tenant_id = $2 AND b.kind = 'bundle' AND b.pricing = 'computed'
+	            AND b.merged_into IS NULL AND b.price <> s.total - b.discount
+	          RETURNING ` + productColumns
+
+	var repriced []product.Product
+	changed := []string{id}
+	for len(changed) > 0 {
+		qctx, span := tracing.Query(ctx, query)
+		rows, err := tx.QueryContext(qctx, query, pq.Array(changed), tenant.FromContext(ctx))
+		tracing.End(span, err)
+		if err != nil {
+			r.Log.WithContext(ctx).WithError(err).Errorf("error repricing bundles containing %v", id)
+			return nil, err
+		}
+
+		changed = changed[:0]
+		for rows.Next() {
+			b, err := scanProduct(rows)
+			if err != nil {
+				rows.Close()
+				r.Log.WithContext(ctx).WithError(err).Error("error row scan in reprice bundles")
+				return nil, err
+			}
+			if b.Price <= 0 {
+				rows.Close()
+				return nil, fmt.Errorf("%w: bundle %s would cost %.2f", product.ErrInvalidBundle, b.ID, b.Price)
+			}
+			repriced = append(repriced, *b)
+			changed = append(changed, b.ID)
+		}
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return repriced, nil
+}
+
+// UpdateStatus moves a product from one status to another. It fails with
+// ErrInvalidTransition when the product is no longer in the expected status.
+func (r *RepositoryPostgre) UpdateStatus(ctx context.Context, id, from, to string, publishAt *time.Time) error {
+	defer metrics.ObserveQuery("products", "UpdateStatus", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	query := `UPDATE products SET status = $1, publish_at = $2 WHERE id = $3 AND status = $4 AND tenant_id = $5`
+	res, err := r.exec(ctx, query, to, utc(publishAt), id, from, tenant.FromContext(ctx))
+	if err != nil {
+		r.Log.WithContext(ctx).WithError(err).Errorf("error update product status: %v", id)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: product %s is no longer %s", product.ErrInvalidTransition, id, from)
+	}
+	return nil
+}
+
+// MergeProducts folds the sources into the target in one transaction: bundle
+// components pointing at a source are moved to the target (adding up
+// quantities), a barcode is carried over when the target has none, and the
+// sources are archived with merged_into set, along with anything that was
+// previously merged into them.
+func (r *RepositoryPostgre) MergeProducts(ctx context.Context, targetID string, sourceIDs []string) error {
+	defer metrics.ObserveQuery("products", "MergeProducts", time.Now())
+	ctx, cancel := deadline.With(ctx, r.timeout)
+	defer cancel()
+
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return r.mergeProducts(ctx, tx, targetID, sourceIDs)
+	})
+}
+
+func (r *RepositoryPostgre) mergeProducts(ctx context.Context, tx *sql.Tx, targetID string, sourceIDs []string) error {
+	tenantID := tenant.FromContext(ctx)
+	var carried sql.NullString
+	query := `SELECT barcode FROM products WHERE id = ANY($1) AND tenant_id = $2 AND barcode IS NOT NULL ORDER BY created_at LIMIT 1`
+	qctx, span := tracing.Query(ctx, query)
+	err := tx.QueryRowContext(qctx, query, pq.Array(sourceIDs), tenantID).Scan(&carried)
+	tracing.End(span, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.Log.WithContext(ctx).WithError(err).Errorf("error merging products into %v", targetID)
+		return err
+	}
+
+	type statement struct {
+		query string
+		args  []interface{}
+	}
+	statements := []statement{
+		{`INSERT INTO product_components (bundle_id, component_id, quantity, tenant_id)
+		  SELECT bundle_id, $1, SUM(quantity), $3 FROM product_components WHERE component_id = ANY($2) AND tenant_id = $3 GROUP BY bundle_id
+		  ON CONFLICT (bundle_id, component_id) DO UPDATE SET quantity = product_components.quantity + EXCLUDED.quantity`,
+			[]interface{}{targetID, pq.Array(sourceIDs), tenantID}},
+		{`DELETE FROM product_components WHERE component_id = ANY($1) AND tenant_id = $2`,
+			[]interface{}{pq.Array(sourceIDs), tenantID}},
+		{`UPDATE products SET merged_into = $1 WHERE merged_into = ANY($2) AND tenant_id = $3`,
+			[]interface{}{targetID, pq.Array(sourceIDs), tenantID}},
+		{`UPDATE products SET merged_into = $1, status = 'archived', barcode = NULL WHERE id = ANY($2) AND tenant_id = $3`,
+			[]interface{}{targetID, pq.Array(sourceIDs), tenantID}},
+	}
+	if carried.Valid {
+		statements = append(statements, statement{
+			`UPDATE products SET barcode = $2 WHERE id = $1 AND tenant_id = $3 AND barcode IS NULL`,
+			[]interface{}{targetID, carried.String, tenantID}})
+	}
+
+	for _, stmt := range statements {
+		if _, err := txExec(ctx, tx, stmt.query, stmt.args...); err != nil {
+			r.Log.WithContext(ctx).WithError(err).Errorf("error merging products into %v", targetID)
+			return err
+		}
+	}
+	return nil
+}
+
+// inTx runs fn in a transaction, starting over when the transaction failed
+// in a way that guarantees nothing was committed, such as a serialization
+// failure or deadlock.
+func (r *RepositoryPostgre) inTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	return r.call(ctx, retry.PostgresWrite, func() error {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			r.Log.WithContext(ctx).WithError(err).Error("error begin transaction")
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// txExec runs one statement of a transaction in a span of its own.
+func txExec(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := tracing.Query(ctx, query)
+	res, err := tx.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return res, err
+}
+
+// exec retries single statement writes only when the statement cannot have
+// run; dropped connections are ambiguous and returned as they are.
+func (r *RepositoryPostgre) exec(ctx context.Context, query string, args ...interface{}) (res sql.Result, err error) {
+	err = r.call(ctx, retry.PostgresWrite, func() error {
+		ctx, span := tracing.Query(ctx, query)
+		res, err = r.db.ExecContext(ctx, query, args...)
+		tracing.End(span, err)
+		return err
+	})
+	return res, err
+}
+
+func (r *RepositoryPostgre) query(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	err = r.call(ctx, retry.PostgresRead, func() error {
+		ctx, span := tracing.Query(ctx, query)
+		rows, err = r.db.QueryContext(ctx, query, args...)
+		tracing.End(span, err)
+		return err
+	})
+	return rows, err
+}
+
+func (r *RepositoryPostgre) findOne(ctx context.Context, query string, args ...interface{}) (p *product.Product, err error) {
+	err = r.call(ctx, retry.PostgresRead, func() error {
+		ctx, span := tracing.Query(ctx, query)
+		p, err = scanProduct(r.db.QueryRowContext(ctx, query, args...))
+		tracing.End(span, err)
+		return err
+	})
+	return p, err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanProduct reads a row selected with productColumns, followed by any
+// extra columns the caller appended to the select list.
+func scanProduct(s scanner, extra ...interface{}) (*product.Product, error) {
+	var p product.Product
+	dest := append([]interface{}{
+		&p.ID, &p.Name, &p.Type, &p.Price, &p.Barcode, &p.Kind, &p.Pricing, &p.Discount, &p.Status, &p.PublishAt, &p.MergedInto, &p.CreatedAt,
+	}, extra...)
+	if err := s.Scan(dest...); err != nil {
 		return nil, err
 	}
-	return &product, nil
+	p.PublishAt = utc(p.PublishAt)
+	return &p, nil
+}
+
+// utc converts a publish time to UTC, so that the instant is kept whatever
+// offset the caller sent it with.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// isForeignKeyViolation reports whether err is a foreign key violation, of
+// one of the given constraints if any are given.
+func isForeignKeyViolation(err error, constraints ...string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23503" {
+		return false
+	}
+	if len(constraints) == 0 {
+		return true
+	}
+	for _, c := range constraints {
+		if pqErr.Constraint == c {
+			return true
+		}
+	}
+	return false
 }
@@ -3,36 +3,64 @@ package repository_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"simple-product-api/internal/product"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"simple-product-api/internal/product/repository"
+	"simple-product-api/pkg/breaker"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/retry"
+	"simple-product-api/pkg/tenant"
 )
 
+const (
+	selectProducts  = `SELECT id, name, type, price, COALESCE\(barcode, ''\), kind, COALESCE\(pricing, ''\), discount, status, publish_at, COALESCE\(merged_into::text, ''\), created_at`
+	publishedClause = `status = 'active' AND \(publish_at IS NULL OR publish_at <= NOW\(\)\)`
+)
+
+func newProductRows(extra ...string) *sqlmock.Rows {
+	return sqlmock.NewRows(append([]string{"id", "name", "type", "price", "barcode", "kind", "pricing", "discount", "status", "publish_at", "merged_into", "created_at"}, extra...))
+}
+
+func addProductRow(rows *sqlmock.Rows, p product.Product, extra ...driver.Value) *sqlmock.Rows {
+	if p.Kind == "" {
+		p.Kind = product.KindSimple
+	}
+	if p.Status == "" {
+		p.Status = product.StatusActive
+	}
+	var publishAt driver.Value
+	if p.PublishAt != nil {
+		publishAt = *p.PublishAt
+	}
+	return rows.AddRow(append([]driver.Value{p.ID, p.Name, p.Type, p.Price, p.Barcode, p.Kind, p.Pricing, p.Discount, p.Status, publishAt, p.MergedInto, p.CreatedAt}, extra...)...)
+}
+
 func TestRepo_FindByID_Success(t *testing.T) {
 	db, mock, _ := sqlmock.New()
 	defer db.Close()
 
 	log := logrus.New()
-	repo := repository.NewPostgresRepo(db, log)
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
 
 	expected := &product.Product{
 		ID: "84b6f675-1e28-4ef4-b987-2e7422b4f5a0", Name: "Banana", Type: "Buah", Price: 10000, CreatedAt: time.Now(),
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "name", "type", "price", "created_at"}).
-		AddRow(expected.ID, expected.Name, expected.Type, expected.Price, expected.CreatedAt)
+	rows := addProductRow(newProductRows(), *expected)
 
-	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1").
-		WithArgs(expected.ID).
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1 AND tenant_id = \\$2").
+		WithArgs(expected.ID, tenant.Default).
 		WillReturnRows(rows)
 
-	result, err := repo.FindByID(context.Background(), expected.ID)
+	result, err := repo.FindProductByID(context.Background(), expected.ID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected.ID, result.ID)
@@ -43,16 +71,17 @@ func TestRepo_FindByID_NotFound(t *testing.T) {
 	defer db.Close()
 
 	log := logrus.New()
-	repo := repository.NewPostgresRepo(db, log)
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
 
 	id := "non-existent-id"
 
-	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1").
-		WithArgs(id).
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1 AND tenant_id = \\$2").
+		WithArgs(id, tenant.Default).
 		WillReturnError(sql.ErrNoRows)
 
-	_, err := repo.FindByID(context.Background(), id)
+	_, err := repo.FindProductByID(context.Background(), id)
 	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.ErrorIs(t, err, product.ErrNotFound)
 }
 
 func TestRepo_FindByID_DBError(t *testing.T) {
@@ -60,15 +89,15 @@ func TestRepo_FindByID_DBError(t *testing.T) {
 	defer db.Close()
 
 	log := logrus.New()
-	repo := repository.NewPostgresRepo(db, log)
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
 
 	id := "error-id"
 
-	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1").
-		WithArgs(id).
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1 AND tenant_id = \\$2").
+		WithArgs(id, tenant.Default).
 		WillReturnError(errors.New("db connection lost"))
 
-	_, err := repo.FindByID(context.Background(), id)
+	_, err := repo.FindProductByID(context.Background(), id)
 	assert.EqualError(t, err, "db connection lost")
 }
 
@@ -77,18 +106,18 @@ func TestRepo_Find_NoFilter(t *testing.T) {
 	defer db.Close()
 
 	log := logrus.New()
-	repo := repository.NewPostgresRepo(db, log)
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
 
 	filter := product.ListFilter{Page: 1, PageSize: 10}
 
 	now := time.Now()
-	rows := sqlmock.NewRows([]string{"id", "name", "type", "price", "created_at", "total_count"}).
-		AddRow("1", "Apple", "Buah", 15000, now, 1)
+	rows := addProductRow(newProductRows("total_count"),
+		product.Product{ID: "1", Name: "Apple", Type: "Buah", Price: 15000, CreatedAt: now}, 1)
 
-	mock.ExpectQuery(`SELECT id, name, type, price, created_at, COUNT\(\*\) OVER\(\) as total_count FROM products ORDER BY created_at DESC LIMIT 10 OFFSET 0`).
+	mock.ExpectQuery(selectProducts + `, COUNT\(\*\) OVER\(\) as total_count FROM products WHERE tenant_id = \$1 AND merged_into IS NULL AND ` + publishedClause + ` ORDER BY created_at DESC LIMIT 10 OFFSET 0`).
 		WillReturnRows(rows)
 
-	products, total, err := repo.Find(context.Background(), filter)
+	products, total, err := repo.FindProduct(context.Background(), filter)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, total)
@@ -101,19 +130,19 @@ func TestRepo_Find_WithQueryAndType(t *testing.T) {
 	defer db.Close()
 
 	log := logrus.New()
-	repo := repository.NewPostgresRepo(db, log)
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
 
 	filter := product.ListFilter{Page: 1, PageSize: 5, Query: "banana", Type: "Buah", SortBy: "name", Order: "asc"}
 
 	now := time.Now()
-	rows := sqlmock.NewRows([]string{"id", "name", "type", "price", "created_at", "total_count"}).
-		AddRow("2", "Banana", "Buah", 12000, now, 1)
+	rows := addProductRow(newProductRows("total_count"),
+		product.Product{ID: "2", Name: "Banana", Type: "Buah", Price: 12000, CreatedAt: now}, 1)
 
-	mock.ExpectQuery(`SELECT id, name, type, price, created_at, COUNT\(\*\) OVER\(\) as total_count FROM products WHERE LOWER\(name\) LIKE LOWER\(\$1\) AND type = \$2 ORDER BY name ASC LIMIT 5 OFFSET 0`).
-		WithArgs("%banana%", "Buah").
+	mock.ExpectQuery(selectProducts+`, COUNT\(\*\) OVER\(\) as total_count FROM products WHERE tenant_id = \$1 AND merged_into IS NULL AND LOWER\(name\) LIKE LOWER\(\$2\) AND type = \$3 AND `+publishedClause+` ORDER BY name ASC LIMIT 5 OFFSET 0`).
+		WithArgs(tenant.Default, "%banana%", "Buah").
 		WillReturnRows(rows)
 
-	products, total, err := repo.Find(context.Background(), filter)
+	products, total, err := repo.FindProduct(context.Background(), filter)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, total)
@@ -125,14 +154,14 @@ func TestRepo_Find_QueryError(t *testing.T) {
 	defer db.Close()
 
 	log := logrus.New()
-	repo := repository.NewPostgresRepo(db, log)
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
 
 	filter := product.ListFilter{Page: 1, PageSize: 10}
 
 	mock.ExpectQuery("SELECT id, name, type, price").
 		WillReturnError(errors.New("db query error"))
 
-	_, _, err := repo.Find(context.Background(), filter)
+	_, _, err := repo.FindProduct(context.Background(), filter)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "db query error")
@@ -143,18 +172,18 @@ func TestRepo_Find_ScanError(t *testing.T) {
 	defer db.Close()
 
 	log := logrus.New()
-	repo := repository.NewPostgresRepo(db, log)
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
 
 	filter := product.ListFilter{Page: 1, PageSize: 10}
 
 	// simulate broken row (wrong column count)
-	rows := sqlmock.NewRows([]string{"id", "name", "type", "price", "created_at"}). // missing total_count
-											AddRow("3", "Carrot", "Sayuran", 8000, time.Now())
+	rows := addProductRow(newProductRows(), // missing total_count
+		product.Product{ID: "3", Name: "Carrot", Type: "Sayuran", Price: 8000, CreatedAt: time.Now()})
 
 	mock.ExpectQuery("SELECT id, name, type, price").
 		WillReturnRows(rows)
 
-	_, _, err := repo.Find(context.Background(), filter)
+	_, _, err := repo.FindProduct(context.Background(), filter)
 	assert.Error(t, err)
 }
 
@@ -163,17 +192,19 @@ func TestRepo_Save_Success(t *testing.T) {
 	defer db.Close()
 
 	log := logrus.New()
-	repo := repository.NewPostgresRepo(db, log)
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
 
 	p := &product.Product{
 		ID: "123", Name: "Mango", Type: "Buah", Price: 13000, CreatedAt: time.Now(),
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO products").
-		WithArgs(p.ID, p.Name, p.Type, p.Price, p.CreatedAt).
+		WithArgs(p.ID, p.Name, p.Type, p.Price, p.Barcode, p.Kind, p.Pricing, p.Discount, p.Status, p.PublishAt, p.CreatedAt, tenant.Default, sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	err := repo.Save(context.Background(), p)
+	err := repo.SaveProduct(context.Background(), p)
 	assert.NoError(t, err)
 }
 
@@ -182,17 +213,579 @@ func TestRepo_Save_Error(t *testing.T) {
 	defer db.Close()
 
 	log := logrus.New()
-	repo := repository.NewPostgresRepo(db, log)
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
 
 	p := &product.Product{
 		ID: "123", Name: "Papaya", Type: "Buah", Price: 11000, CreatedAt: time.Now(),
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO products").
-		WithArgs(p.ID, p.Name, p.Type, p.Price, p.CreatedAt).
+		WithArgs(p.ID, p.Name, p.Type, p.Price, p.Barcode, p.Kind, p.Pricing, p.Discount, p.Status, p.PublishAt, p.CreatedAt, tenant.Default, sqlmock.AnyArg()).
 		WillReturnError(errors.New("insert error"))
+	mock.ExpectRollback()
 
-	err := repo.Save(context.Background(), p)
+	err := repo.SaveProduct(context.Background(), p)
 	assert.Error(t, err)
 	assert.EqualError(t, err, "insert error")
 }
+
+func TestRepo_Save_DuplicateBarcode(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	p := &product.Product{
+		ID: "123", Name: "Milk", Type: "Protein", Price: 18000, Barcode: "4006381333931", CreatedAt: time.Now(),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO products").
+		WithArgs(p.ID, p.Name, p.Type, p.Price, p.Barcode, p.Kind, p.Pricing, p.Discount, p.Status, p.PublishAt, p.CreatedAt, tenant.Default, sqlmock.AnyArg()).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "products_barcode_key"})
+	mock.ExpectRollback()
+
+	err := repo.SaveProduct(context.Background(), p)
+	assert.ErrorIs(t, err, product.ErrDuplicate)
+}
+
+func TestRepo_FindByBarcode_Success(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	rows := addProductRow(newProductRows(),
+		product.Product{ID: "4", Name: "Milk", Type: "Protein", Price: 18000, Barcode: "4006381333931", CreatedAt: time.Now()})
+
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE barcode = \\$1 AND tenant_id = \\$2").
+		WithArgs("4006381333931", tenant.Default).
+		WillReturnRows(rows)
+
+	result, err := repo.FindProductByBarcode(context.Background(), "4006381333931")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Milk", result.Name)
+	assert.Equal(t, "4006381333931", result.Barcode)
+}
+
+func TestRepo_FindByBarcode_NotFound(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE barcode = \\$1 AND tenant_id = \\$2").
+		WithArgs("4006381333931", tenant.Default).
+		WillReturnError(sql.ErrNoRows)
+
+	result, err := repo.FindProductByBarcode(context.Background(), "4006381333931")
+
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestRepo_Save_BundleWithComponents(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	p := &product.Product{
+		ID: "b1", Name: "Sayur Sop", Type: "Sayuran", Price: 12000, Kind: product.KindBundle, Pricing: product.PricingComputed,
+		Components: []product.Component{{ProductID: "c1", Quantity: 2}, {ProductID: "c2", Quantity: 1}}, CreatedAt: time.Now(),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO products").
+		WithArgs(p.ID, p.Name, p.Type, p.Price, p.Barcode, p.Kind, p.Pricing, p.Discount, p.Status, p.PublishAt, p.CreatedAt, tenant.Default, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO product_components").
+		WithArgs("b1", "c1", 2, tenant.Default).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO product_components").
+		WithArgs("b1", "c2", 1, tenant.Default).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.SaveProduct(context.Background(), p)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_FindComponents(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	rows := sqlmock.NewRows([]string{"component_id", "quantity"}).
+		AddRow("c1", 2).
+		AddRow("c2", 1)
+
+	mock.ExpectQuery("SELECT component_id, quantity FROM product_components WHERE bundle_id = \\$1").
+		WithArgs("b1", tenant.Default).
+		WillReturnRows(rows)
+
+	components, err := repo.FindComponents(context.Background(), "b1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []product.Component{{ProductID: "c1", Quantity: 2}, {ProductID: "c2", Quantity: 1}}, components)
+}
+
+func TestRepo_Delete_ComponentInUse(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	mock.ExpectExec("DELETE FROM products WHERE id = \\$1 AND merged_into IS NULL AND tenant_id = \\$2").
+		WithArgs("c1", tenant.Default).
+		WillReturnError(&pq.Error{Code: "23503", Constraint: "product_components_component_id_fkey"})
+
+	err := repo.DeleteProduct(context.Background(), "c1")
+	assert.ErrorIs(t, err, product.ErrInUse)
+}
+
+func TestRepo_Delete_MergeTarget(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	mock.ExpectExec("DELETE FROM products").
+		WithArgs("t1", tenant.Default).
+		WillReturnError(&pq.Error{Code: "23503", Constraint: "products_merged_into_fkey"})
+
+	err := repo.DeleteProduct(context.Background(), "t1")
+	assert.ErrorIs(t, err, product.ErrInUse)
+	assert.Contains(t, err.Error(), "other products were merged into t1")
+}
+
+func TestRepo_Delete_NotFound(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	mock.ExpectExec("DELETE FROM products WHERE id = \\$1 AND merged_into IS NULL AND tenant_id = \\$2").
+		WithArgs("missing", tenant.Default).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.DeleteProduct(context.Background(), "missing")
+	assert.ErrorIs(t, err, product.ErrNotFound)
+}
+
+func TestRepo_Find_AllVisibility(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	filter := product.ListFilter{Page: 1, PageSize: 10, Visibility: product.VisibilityAll}
+
+	rows := addProductRow(newProductRows("total_count"),
+		product.Product{ID: "5", Name: "Kale", Type: "Sayuran", Price: 9000, Status: product.StatusDraft, CreatedAt: time.Now()}, 1)
+
+	mock.ExpectQuery(selectProducts + `, COUNT\(\*\) OVER\(\) as total_count FROM products WHERE tenant_id = \$1 AND merged_into IS NULL ORDER BY created_at DESC LIMIT 10 OFFSET 0`).
+		WillReturnRows(rows)
+
+	products, _, err := repo.FindProduct(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, product.StatusDraft, products[0].Status)
+}
+
+func TestRepo_UpdateStatus_Stale(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	mock.ExpectExec("UPDATE products SET status = \\$1, publish_at = \\$2 WHERE id = \\$3 AND status = \\$4").
+		WithArgs(product.StatusActive, nil, "p1", product.StatusPendingReview, tenant.Default).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UpdateStatus(context.Background(), "p1", product.StatusPendingReview, product.StatusActive, nil)
+	assert.ErrorIs(t, err, product.ErrInvalidTransition)
+}
+
+func TestRepo_UpdateStatus_StoresPublishAtInUTC(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	publishAt := time.Date(2026, 10, 20, 9, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+	mock.ExpectExec("UPDATE products SET status = \\$1, publish_at = \\$2 WHERE id = \\$3 AND status = \\$4").
+		WithArgs(product.StatusActive, time.Date(2026, 10, 20, 2, 0, 0, 0, time.UTC), "p1", product.StatusPendingReview, tenant.Default).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.UpdateStatus(context.Background(), "p1", product.StatusPendingReview, product.StatusActive, &publishAt)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_FindSimilarProducts(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{DuplicateThreshold: 0.85})
+
+	rows := addProductRow(newProductRows(),
+		product.Product{ID: "6", Name: "Chicken Breast", Type: "Protein", Price: 25000, CreatedAt: time.Now()})
+
+	mock.ExpectQuery(selectProducts+` FROM products\s+WHERE search_name % \$1 AND similarity\(search_name, \$1\) >= \$5 AND LOWER\(type\) = LOWER\(\$2\) AND merged_into IS NULL AND tenant_id = \$3\s+ORDER BY similarity\(search_name, \$1\) DESC LIMIT \$4`).
+		WithArgs("chiken breast", "Protein", tenant.Default, 50, 0.85).
+		WillReturnRows(rows)
+
+	products, err := repo.FindSimilarProducts(context.Background(), "  Chiken  Breast", "Protein", 50)
+
+	assert.NoError(t, err)
+	assert.Len(t, products, 1)
+	assert.Equal(t, "Chicken Breast", products[0].Name)
+}
+
+func TestRepo_FindSimilarProducts_LowThresholdSkipsTrigramOperator(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{DuplicateThreshold: 0.2})
+
+	mock.ExpectQuery(selectProducts+` FROM products\s+WHERE similarity\(search_name, \$1\) >= \$5 AND LOWER\(type\) = LOWER\(\$2\)`).
+		WithArgs("chiken breast", "Protein", tenant.Default, 50, 0.2).
+		WillReturnRows(newProductRows())
+
+	_, err := repo.FindSimilarProducts(context.Background(), "Chiken Breast", "Protein", 50)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_FindByNameAndType_ComparesNormalizedNames(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	mock.ExpectQuery(selectProducts+` FROM products WHERE search_name = \$1 AND LOWER\(type\) = LOWER\(\$2\)`).
+		WithArgs("creme brulee", "Dessert", tenant.Default).
+		WillReturnRows(addProductRow(newProductRows(), product.Product{ID: "7", Name: "Crème Brûlée", Type: "Dessert"}))
+
+	p, err := repo.FindProductByNameAndType(context.Background(), " CREME  brulee ", "Dessert")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "7", p.ID)
+}
+
+func TestRepo_MergeProducts_CarriesBarcode(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	sources := pq.Array([]string{"s1", "s2"})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT barcode FROM products WHERE id = ANY").
+		WithArgs(sources, tenant.Default).
+		WillReturnRows(sqlmock.NewRows([]string{"barcode"}).AddRow("4006381333931"))
+	mock.ExpectExec("INSERT INTO product_components").
+		WithArgs("t1", sources, tenant.Default).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM product_components WHERE component_id = ANY").
+		WithArgs(sources, tenant.Default).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE products SET merged_into = \\$1 WHERE merged_into = ANY").
+		WithArgs("t1", sources, tenant.Default).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE products SET merged_into = \\$1, status = 'archived', barcode = NULL WHERE id = ANY").
+		WithArgs("t1", sources, tenant.Default).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE products SET barcode = \\$2 WHERE id = \\$1 AND tenant_id = \\$3 AND barcode IS NULL").
+		WithArgs("t1", "4006381333931", tenant.Default).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.MergeProducts(context.Background(), "t1", []string{"s1", "s2"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+const repriceBundles = `UPDATE products b SET price = s.total - b.discount`
+
+func TestRepo_UpdateProduct(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	p := &product.Product{ID: "p1", Name: "Kale", Type: "Sayuran", Price: 9500, Barcode: "4006381333931"}
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE products SET name = \$1, type = \$2, price = \$3, barcode = NULLIF\(\$4, ''\), discount = \$5, search_name = \$8\s+WHERE id = \$6 AND merged_into IS NULL AND tenant_id = \$7`).
+		WithArgs(p.Name, p.Type, p.Price, p.Barcode, p.Discount, p.ID, tenant.Default, "kale").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(repriceBundles).
+		WithArgs(pq.Array([]string{"p1"}), tenant.Default).
+		WillReturnRows(newProductRows())
+	mock.ExpectCommit()
+
+	repriced, err := repo.UpdateProduct(context.Background(), p)
+	assert.NoError(t, err)
+	assert.Empty(t, repriced)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_UpdateProduct_RepricesNestedBundles(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	inner := product.Product{ID: "b1", Name: "Salad", Kind: product.KindBundle, Pricing: product.PricingComputed, Price: 19000}
+	outer := product.Product{ID: "b2", Name: "Salad Pack", Kind: product.KindBundle, Pricing: product.PricingComputed, Price: 37000}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE products SET name`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(repriceBundles).
+		WithArgs(pq.Array([]string{"p1"}), tenant.Default).
+		WillReturnRows(addProductRow(newProductRows(), inner))
+	mock.ExpectQuery(repriceBundles).
+		WithArgs(pq.Array([]string{"b1"}), tenant.Default).
+		WillReturnRows(addProductRow(newProductRows(), outer))
+	mock.ExpectQuery(repriceBundles).
+		WithArgs(pq.Array([]string{"b2"}), tenant.Default).
+		WillReturnRows(newProductRows())
+	mock.ExpectCommit()
+
+	repriced, err := repo.UpdateProduct(context.Background(), &product.Product{ID: "p1", Price: 9500})
+	assert.NoError(t, err)
+	if assert.Len(t, repriced, 2) {
+		assert.Equal(t, "b1", repriced[0].ID)
+		assert.Equal(t, 19000.0, repriced[0].Price)
+		assert.Equal(t, "b2", repriced[1].ID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_UpdateProduct_RejectsFreeBundle(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	bundle := product.Product{ID: "b1", Kind: product.KindBundle, Pricing: product.PricingComputed, Price: -500}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE products SET name`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(repriceBundles).WillReturnRows(addProductRow(newProductRows(), bundle))
+	mock.ExpectRollback()
+
+	_, err := repo.UpdateProduct(context.Background(), &product.Product{ID: "p1", Price: 100})
+	assert.ErrorIs(t, err, product.ErrInvalidBundle)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_UpdateProduct_DuplicateBarcode(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE products SET`).WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	_, err := repo.UpdateProduct(context.Background(), &product.Product{ID: "p1", Barcode: "4006381333931"})
+	assert.ErrorIs(t, err, product.ErrDuplicate)
+}
+
+func TestRepo_FindByID_ScopedToTenant(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	log := logrus.New()
+	repo := repository.NewPostgresRepo(db, log, nil, nil, &config.Config{})
+
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1 AND tenant_id = \\$2").
+		WithArgs("p1", "store-2").
+		WillReturnRows(newProductRows())
+
+	_, err := repo.FindProductByID(tenant.WithID(context.Background(), "store-2"), "p1")
+	assert.ErrorIs(t, err, product.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testRetrier() *retry.Retrier {
+	return retry.New(retry.Policy{Attempts: 3}, nil)
+}
+
+func TestRepo_Save_RetriesSerializationFailure(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := repository.NewPostgresRepo(db, logrus.New(), testRetrier(), nil, &config.Config{})
+
+	p := &product.Product{
+		ID: "123", Name: "Mango", Type: "Buah", Price: 13000, CreatedAt: time.Now(),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO products").
+		WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO products").
+		WithArgs(p.ID, p.Name, p.Type, p.Price, p.Barcode, p.Kind, p.Pricing, p.Discount, p.Status, p.PublishAt, p.CreatedAt, tenant.Default, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.SaveProduct(context.Background(), p)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_Save_DoesNotRetryDuplicate(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := repository.NewPostgresRepo(db, logrus.New(), testRetrier(), nil, &config.Config{})
+
+	p := &product.Product{
+		ID: "123", Name: "Milk", Type: "Protein", Price: 18000, Barcode: "4006381333931", CreatedAt: time.Now(),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO products").
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "products_barcode_key"})
+	mock.ExpectRollback()
+
+	err := repo.SaveProduct(context.Background(), p)
+	assert.ErrorIs(t, err, product.ErrDuplicate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_FindByID_RetriesAdminShutdown(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := repository.NewPostgresRepo(db, logrus.New(), testRetrier(), nil, &config.Config{})
+
+	expected := product.Product{ID: "84b6f675-1e28-4ef4-b987-2e7422b4f5a0", Name: "Banana", Type: "Buah", Price: 10000, CreatedAt: time.Now()}
+
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1 AND tenant_id = \\$2").
+		WillReturnError(&pq.Error{Code: "57P01"})
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1 AND tenant_id = \\$2").
+		WithArgs(expected.ID, tenant.Default).
+		WillReturnRows(addProductRow(newProductRows(), expected))
+
+	result, err := repo.FindProductByID(context.Background(), expected.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Name, result.Name)
+}
+
+func TestRepo_FindByID_TimesOut(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := repository.NewPostgresRepo(db, logrus.New(), testRetrier(), nil, &config.Config{DBTimeout: 20 * time.Millisecond})
+
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1 AND tenant_id = \\$2").
+		WillDelayFor(time.Second).
+		WillReturnRows(newProductRows())
+
+	start := time.Now()
+	_, err := repo.FindProductByID(context.Background(), "slow")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestRepo_FindByID_FailsFastWhenBreakerOpen(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	cb := breaker.New(breaker.Settings{Name: "postgres", FailureThreshold: 1, OpenTimeout: time.Minute})
+	repo := repository.NewPostgresRepo(db, logrus.New(), nil, cb, &config.Config{})
+
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1 AND tenant_id = \\$2").
+		WillReturnError(&pq.Error{Code: "57P01"})
+
+	_, err := repo.FindProductByID(context.Background(), "a")
+	assert.Error(t, err)
+	assert.Equal(t, breaker.Open, cb.State())
+
+	_, err = repo.FindProductByID(context.Background(), "a")
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_FindByID_TimeoutsOpenBreaker(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	cb := breaker.New(breaker.Settings{Name: "postgres", FailureThreshold: 1, OpenTimeout: time.Minute})
+	repo := repository.NewPostgresRepo(db, logrus.New(), nil, cb, &config.Config{DBTimeout: 20 * time.Millisecond})
+
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1 AND tenant_id = \\$2").
+		WillDelayFor(time.Second).
+		WillReturnRows(newProductRows())
+
+	_, err := repo.FindProductByID(context.Background(), "slow")
+	assert.Error(t, err)
+	assert.Equal(t, breaker.Open, cb.State())
+}
+
+func TestRepo_FindByID_CancelDoesNotOpenBreaker(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	cb := breaker.New(breaker.Settings{Name: "postgres", FailureThreshold: 1, OpenTimeout: time.Minute})
+	repo := repository.NewPostgresRepo(db, logrus.New(), nil, cb, &config.Config{})
+
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1 AND tenant_id = \\$2").
+		WillDelayFor(time.Second).
+		WillReturnRows(newProductRows())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err := repo.FindProductByID(ctx, "slow")
+	assert.Error(t, err)
+	assert.Equal(t, breaker.Closed, cb.State())
+}
+
+func TestRepo_ScanProductIDs(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := repository.NewPostgresRepo(db, logrus.New(), nil, nil, &config.Config{})
+
+	mock.ExpectQuery(`SELECT id FROM products`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("p1").AddRow("p2"))
+
+	var ids []string
+	err := repo.ScanProductIDs(context.Background(), func(id string) { ids = append(ids, id) })
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"p1", "p2"}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,45 @@
+package product
+
+import "time"
+
+const (
+	StatusDraft         = "draft"
+	StatusPendingReview = "pending_review"
+	StatusActive        = "active"
+	StatusArchived      = "archived"
+)
+
+// transitions lists the statuses each status may move to.
+var transitions = map[string][]string{
+	StatusDraft:         {StatusPendingReview},
+	StatusPendingReview: {StatusActive, StatusDraft},
+	StatusActive:        {StatusArchived},
+	StatusArchived:      {StatusDraft},
+}
+
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPublished reports whether the product is active and its scheduled
+// publish time, if any, has passed.
+func (p *Product) IsPublished(now time.Time) bool {
+	return p.Status == StatusActive && (p.PublishAt == nil || !p.PublishAt.After(now))
+}
+
+// Visibility decides whether unpublished products are returned to a caller.
+type Visibility string
+
+const (
+	VisibilityPublic Visibility = "public"
+	VisibilityAll    Visibility = "all"
+)
+
+func (v Visibility) Allows(p *Product, now time.Time) bool {
+	return v == VisibilityAll || p.IsPublished(now)
+}
@@ -0,0 +1,358 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	model "simple-product-api/internal/product"
+	"simple-product-api/pkg/breaker"
+	"simple-product-api/pkg/cache"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/deadline"
+	"simple-product-api/pkg/metrics"
+)
+
+// cacheSchema versions the shape of everything cached here. Bump it when
+// model.Product or any other cached type changes incompatibly; instances
+// on either side of the deploy then treat each other's values as misses
+// instead of misreading them.
+const cacheSchema byte = 1
+
+// lockPoll is how often a request waiting on another instance's lock checks
+// whether the value has been cached.
+const lockPoll = 25 * time.Millisecond
+
+// errUndecodable is returned for cached values that cannot be decoded.
+// Like a miss, it makes the value load again and be overwritten.
+var errUndecodable = errors.New("undecodable cached value")
+
+// NewCacheCodec returns the codec named by CACHE_CODEC, tagged with the
+// current cacheSchema.
+func NewCacheCodec(cfg *config.Config) (*cache.Codec, error) {
+	return cache.NewCodec(cfg.CacheCodec, cacheSchema, cfg.CacheCompressAbove)
+}
+
+// entry is what is stored under a cache key. Missing entries record that
+// there was nothing to load.
+type entry[T any] struct {
+	Value      T     `json:"value"`
+	FreshUntil int64 `json:"fresh_until"`
+	Missing    bool  `json:"missing,omitempty"`
+}
+
+func (e entry[T]) stale(now time.Time) bool {
+	return e.FreshUntil != 0 && now.UnixMilli() >= e.FreshUntil
+}
+
+// decodeEntry decodes an entry written by the codec. Values cached before
+// the codec existed are JSON entries, or plain JSON values from before
+// entries, which are read as fresh.
+func decodeEntry[T any](codec *cache.Codec, data []byte) (entry[T], error) {
+	var e entry[T]
+	if cache.IsEncoded(data) {
+		return e, codec.Unmarshal(data, &e)
+	}
+
+	var legacy entry[json.RawMessage]
+	if err := json.Unmarshal(data, &legacy); err != nil || (legacy.Value == nil && !legacy.Missing) {
+		legacy = entry[json.RawMessage]{Value: data}
+	}
+	e.FreshUntil, e.Missing = legacy.FreshUntil, legacy.Missing
+	if e.Missing {
+		return e, nil
+	}
+	return e, json.Unmarshal(legacy.Value, &e.Value)
+}
+
+// fetch returns the value cached under key, calling load on a miss and
+// caching the result with tags. Concurrent misses in this process share
+// one call to load, and with CacheLockTTL set and a shared cache,
+// instances take a lock so that only one of them calls it. Expired values
+// still within CacheStaleTTL are returned as they are while one background
+// load refreshes them. When load reports ErrNotFound that is cached for
+// CacheNegativeTTL.
+//
+// load runs detached from ctx so that a canceled request does not fail the
+// others waiting on the same load; the repository's own timeout bounds it.
+// A caller whose ctx is done stops waiting and gets ctx's error.
+func fetch[T any](uc *Usecase, ctx context.Context, key string, load func(context.Context) (T, error), tags ...string) (T, error) {
+	var zero T
+
+	if e, ok := cacheGet[T](uc, ctx, key); ok {
+		if e.Missing {
+			return zero, model.ErrNotFound
+		}
+		if e.stale(time.Now()) {
+			uc.Log.WithContext(ctx).WithField("cache_key", key).Info("serving stale cache while refreshing")
+			uc.goBackground(func() {
+				<-uc.flight.DoChan(key, func() (interface{}, error) {
+					return fill(uc, context.WithoutCancel(ctx), key, load, tags)
+				})
+			})
+		}
+		return e.Value, nil
+	}
+
+	ch := uc.flight.DoChan(key, func() (interface{}, error) {
+		return fill(uc, context.WithoutCancel(ctx), key, load, tags)
+	})
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return res.Val.(T), nil
+	}
+}
+
+// fill loads a value and caches it. When another instance holds the lock
+// for key, fill waits for that instance to cache the value and only loads
+// it itself if the lock expires first.
+func fill[T any](uc *Usecase, ctx context.Context, key string, load func(context.Context) (T, error), tags []string) (T, error) {
+	if uc.Cfg.CacheLockTTL > 0 {
+		unlock, locked := uc.lock(ctx, key)
+		if locked {
+			defer unlock()
+		} else if e, ok := awaitFill[T](uc, ctx, key); ok {
+			if e.Missing {
+				return e.Value, model.ErrNotFound
+			}
+			return e.Value, nil
+		}
+	}
+
+	v, err := load(ctx)
+	if errors.Is(err, model.ErrNotFound) && uc.Cfg.CacheNegativeTTL > 0 {
+		if uc.Cfg.CacheLockTTL > 0 {
+			uc.cacheMissing(ctx, key)
+		} else {
+			uc.goBackground(func() { uc.cacheMissing(ctx, key) })
+		}
+	}
+	if err != nil {
+		return v, err
+	}
+
+	fresh := uc.Cfg.CacheTTL
+	if j := uc.Cfg.CacheTTLJitter; j > 0 && fresh > 0 {
+		fresh -= time.Duration(rand.Float64() * j * float64(fresh))
+	}
+	data, err := uc.Codec.Marshal(entry[T]{Value: v, FreshUntil: time.Now().Add(fresh).UnixMilli()})
+	if err != nil {
+		uc.Log.WithContext(ctx).Errorf("failed to marshal products for caching: %v", err)
+		return v, nil
+	}
+	if uc.Cfg.CacheLockTTL > 0 {
+		// Others are polling for this value; store it before the lock goes.
+		uc.cacheSet(ctx, key, data, fresh, tags...)
+	} else {
+		uc.goBackground(func() { uc.cacheSet(ctx, key, data, fresh, tags...) })
+	}
+	return v, nil
+}
+
+// goBackground runs fn without holding up the response, tracked so that
+// Drain can wait for it.
+func (uc *Usecase) goBackground(fn func()) {
+	uc.background.Add(1)
+	go func() {
+		defer uc.background.Done()
+		fn()
+	}()
+}
+
+// Drain waits until the cache writes and refreshes started by earlier
+// requests have finished, or until ctx is done. It is meant for shutdown,
+// once no more requests are coming in.
+func (uc *Usecase) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		uc.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cacheGet reads a cached entry, giving up after CacheTimeout so that a
+// slow Redis falls back to the database instead of using up the request.
+// It reports false on a miss, for values it cannot read and whenever the
+// cache is unavailable.
+func cacheGet[T any](uc *Usecase, ctx context.Context, key string) (entry[T], bool) {
+	e, err := readEntry[T](uc, ctx, key)
+	uc.stats.record(key, err)
+	switch {
+	case err == nil:
+		return e, true
+	case errors.Is(err, cache.ErrMiss):
+		uc.Log.WithContext(ctx).WithField("cache_key", key).Warn("redis cache missing")
+	case errors.Is(err, cache.ErrVersion):
+		uc.Log.WithContext(ctx).WithField("cache_key", key).Info("cached value has another schema version, reloading")
+	case errors.Is(err, errUndecodable):
+		uc.Log.WithContext(ctx).Errorf("error unmarshall from redis: %v", err)
+	case errors.Is(err, breaker.ErrOpen):
+		// Redis is known to be down; go straight to the database.
+	default:
+		uc.Log.WithContext(ctx).WithError(err).Error("redis error")
+	}
+	return entry[T]{}, false
+}
+
+func readEntry[T any](uc *Usecase, ctx context.Context, key string) (entry[T], error) {
+	ctx, cancel := deadline.With(ctx, uc.Cfg.CacheTimeout)
+	defer cancel()
+
+	data, err := uc.Cache.Get(ctx, key)
+	if err != nil {
+		return entry[T]{}, err
+	}
+	e, err := decodeEntry[T](uc.Codec, data)
+	if err != nil && !errors.Is(err, cache.ErrVersion) {
+		err = fmt.Errorf("%w: %v", errUndecodable, err)
+	}
+	return e, err
+}
+
+// cacheSet stores data as fresh for fresh and keeps it for CacheStaleTTL
+// beyond that. The write is detached from the request so that it is not
+// canceled with it, and bounded by CacheWriteTimeout instead.
+func (uc *Usecase) cacheSet(ctx context.Context, key string, data []byte, fresh time.Duration, tags ...string) {
+	ctx, cancel := deadline.Detach(ctx, uc.Cfg.CacheWriteTimeout)
+	defer cancel()
+
+	err := uc.Cache.Set(ctx, key, data, fresh+uc.Cfg.CacheStaleTTL, tags...)
+	if err != nil && !errors.Is(err, breaker.ErrOpen) {
+		uc.Log.WithContext(ctx).WithError(err).WithField("cache_key", key).Warn("failed to cache products")
+	}
+}
+
+// cacheMissing records for CacheNegativeTTL that key has nothing to load.
+func (uc *Usecase) cacheMissing(ctx context.Context, key string) {
+	ctx, cancel := deadline.Detach(ctx, uc.Cfg.CacheWriteTimeout)
+	defer cancel()
+
+	value, err := uc.Codec.Marshal(entry[any]{Missing: true})
+	if err != nil {
+		uc.Log.WithContext(ctx).Errorf("failed to marshal missing product for caching: %v", err)
+		return
+	}
+	err = uc.Cache.Set(ctx, key, value, uc.Cfg.CacheNegativeTTL)
+	if err != nil && !errors.Is(err, breaker.ErrOpen) {
+		uc.Log.WithContext(ctx).WithError(err).WithField("cache_key", key).Warn("failed to cache missing product")
+	}
+}
+
+// lock takes the fill lock for key, held for at most CacheLockTTL. Caches
+// that are not shared need no lock, and when the lock cannot be taken
+// because the cache is unavailable the caller proceeds as if it held it.
+func (uc *Usecase) lock(ctx context.Context, key string) (unlock func(), locked bool) {
+	locker, ok := uc.Cache.(cache.Locker)
+	if !ok {
+		return func() {}, true
+	}
+	release, locked, err := locker.Lock(ctx, key, uc.Cfg.CacheLockTTL)
+	if err != nil {
+		return func() {}, true
+	}
+	if !locked {
+		return nil, false
+	}
+	return func() {
+		ctx, cancel := deadline.Detach(ctx, uc.Cfg.CacheWriteTimeout)
+		defer cancel()
+		if err := release(ctx); err != nil && !errors.Is(err, breaker.ErrOpen) {
+			uc.Log.WithContext(ctx).WithError(err).WithField("cache_key", key).Warn("failed to release cache lock")
+		}
+	}, true
+}
+
+// awaitFill polls for the value another instance is loading until the lock
+// would have expired.
+func awaitFill[T any](uc *Usecase, ctx context.Context, key string) (entry[T], bool) {
+	t := time.NewTicker(lockPoll)
+	defer t.Stop()
+	timeout := time.After(uc.Cfg.CacheLockTTL)
+
+	for {
+		select {
+		case <-timeout:
+			return entry[T]{}, false
+		case <-t.C:
+			if e, err := readEntry[T](uc, ctx, key); err == nil {
+				return e, true
+			} else if !errors.Is(err, cache.ErrMiss) {
+				return entry[T]{}, false
+			}
+		}
+	}
+}
+
+// cacheStats counts cache lookups by namespace, the part of the key after
+// the tenant up to the second colon, such as "products:id", on this
+// instance and in the cache_lookups_total metric.
+type cacheStats struct {
+	mu     sync.Mutex
+	counts map[string]*model.CacheStats
+}
+
+func (s *cacheStats) record(key string, err error) {
+	ns := "other"
+	if parts := strings.SplitN(key, ":", 5); len(parts) >= 4 {
+		ns = parts[2] + ":" + parts[3]
+	}
+
+	result := metrics.CacheError
+	switch {
+	case err == nil:
+		result = metrics.CacheHit
+	case errors.Is(err, cache.ErrMiss), errors.Is(err, cache.ErrVersion):
+		result = metrics.CacheMiss
+	}
+	metrics.CountCacheLookup(ns, result)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts == nil {
+		s.counts = map[string]*model.CacheStats{}
+	}
+	c := s.counts[ns]
+	if c == nil {
+		c = &model.CacheStats{}
+		s.counts[ns] = c
+	}
+	switch result {
+	case metrics.CacheHit:
+		c.Hits++
+	case metrics.CacheMiss:
+		c.Misses++
+	default:
+		c.Errors++
+	}
+}
+
+func (s *cacheStats) snapshot() map[string]model.CacheStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]model.CacheStats, len(s.counts))
+	for ns, c := range s.counts {
+		stats := *c
+		if total := c.Hits + c.Misses + c.Errors; total > 0 {
+			stats.HitRatio = float64(c.Hits) / float64(total)
+		}
+		out[ns] = stats
+	}
+	return out
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	model "simple-product-api/internal/product"
+	"simple-product-api/pkg/cache"
+	"simple-product-api/pkg/tenant"
+)
+
+// cacheNamespace is the part of the cache the admin endpoints may see.
+const cacheNamespace = "products:"
+
+// CacheStats returns the hit and miss counts of this instance by namespace.
+func (uc *Usecase) CacheStats() map[string]model.CacheStats {
+	return uc.stats.snapshot()
+}
+
+// CacheKeys lists up to limit cached keys of the caller's tenant matching
+// pattern, a Redis glob under "products:".
+func (uc *Usecase) CacheKeys(ctx context.Context, pattern string, limit int) ([]model.CacheKey, error) {
+	scanner, pattern, err := uc.scanner(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := scanner.Keys(ctx, pattern, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := tenantKey(ctx, "")
+	out := make([]model.CacheKey, 0, len(keys))
+	for _, key := range keys {
+		ttl, err := uc.Cache.TTL(ctx, key)
+		if errors.Is(err, cache.ErrMiss) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, model.CacheKey{Key: strings.TrimPrefix(key, prefix), TTLSeconds: ttl.Seconds()})
+	}
+	return out, nil
+}
+
+// FlushCache deletes the cached keys of the caller's tenant matching
+// pattern and returns how many there were.
+func (uc *Usecase) FlushCache(ctx context.Context, pattern string) (int, error) {
+	uc.Log.WithContext(ctx).WithField("pattern", pattern).Info("flushing cache")
+
+	scanner, pattern, err := uc.scanner(ctx, pattern)
+	if err != nil {
+		return 0, err
+	}
+	return scanner.DeleteMatching(ctx, pattern)
+}
+
+// scanner checks pattern and scopes it to the caller's tenant.
+func (uc *Usecase) scanner(ctx context.Context, pattern string) (cache.Scanner, string, error) {
+	if !strings.HasPrefix(pattern, cacheNamespace) {
+		return nil, "", fmt.Errorf("%w: %q must start with %q", model.ErrInvalidPattern, pattern, cacheNamespace)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, "", fmt.Errorf("%w: %q: %v", model.ErrInvalidPattern, pattern, err)
+	}
+	scanner, ok := uc.Cache.(cache.Scanner)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: the cache backend cannot list keys", errors.ErrUnsupported)
+	}
+	return scanner, tenantKey(ctx, "%s", pattern), nil
+}
+
+// Warmer loads the most requested products and list pages into the cache
+// on startup and every CacheWarmInterval, so that a deploy or a flushed
+// Redis does not leave the first users with a cold cache.
+type Warmer struct {
+	uc   *Usecase
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// NewWarmer starts warming when popularity is being counted and returns
+// nil otherwise.
+func NewWarmer(uc *Usecase) *Warmer {
+	if uc.Popular == nil {
+		return nil
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	w := &Warmer{uc: uc, stop: stop, done: make(chan struct{})}
+	go w.run(ctx)
+	return w
+}
+
+// Close stops warming, waiting for a warmup in progress to give up.
+func (w *Warmer) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.stop()
+	<-w.done
+	return nil
+}
+
+func (w *Warmer) run(ctx context.Context) {
+	defer close(w.done)
+
+	t := time.NewTicker(w.uc.Cfg.CacheWarmInterval)
+	defer t.Stop()
+	for {
+		start := time.Now()
+		n, err := w.uc.warmCache(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log := w.uc.Log.WithContext(ctx).WithField("entries", n).WithField("took", time.Since(start))
+		if err != nil {
+			log.WithError(err).Warn("cache warmup failed")
+		} else {
+			log.Info("cache warmed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// warmCache loads the most requested products and list pages that are not
+// cached yet, and returns how many entries it went through. Products that
+// no longer exist are skipped.
+func (uc *Usecase) warmCache(ctx context.Context) (int, error) {
+	ids, err := uc.Popular.topIDs(ctx, uc.Cfg.CacheWarmIDs)
+	if err != nil {
+		return 0, err
+	}
+	lists, err := uc.Popular.topLists(ctx, uc.Cfg.CacheWarmLists)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, p := range ids {
+		_, err := uc.findProductByID(tenant.WithID(ctx, p.Tenant), p.ID)
+		if err != nil && !errors.Is(err, model.ErrNotFound) && !errors.Is(err, model.ErrInvalidID) {
+			return n, err
+		}
+		n++
+	}
+	for _, l := range lists {
+		if _, err := uc.listProducts(tenant.WithID(ctx, l.Tenant), l.Filter); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, uc.Popular.decay(ctx, uc.Cfg.CacheWarmInterval)
+}
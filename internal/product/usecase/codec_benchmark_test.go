@@ -0,0 +1,105 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+	"simple-product-api/internal/product"
+	mockRepo "simple-product-api/internal/product/mocks"
+	"simple-product-api/internal/product/usecase"
+	"simple-product-api/pkg/cache"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/policy"
+)
+
+var benchmarkCodecs = []struct {
+	name          string
+	format        string
+	compressAbove int
+}{
+	{"json", cache.FormatJSON, 0},
+	{"msgpack", cache.FormatMsgpack, 0},
+	{"msgpack+s2", cache.FormatMsgpack, 1024},
+}
+
+func benchmarkPage(n int) []product.Product {
+	products := make([]product.Product, n)
+	for i := range products {
+		products[i] = product.Product{
+			ID:        fmt.Sprintf("00000000-0000-4000-8000-%012d", i),
+			Name:      fmt.Sprintf("Tomato %d", i),
+			Type:      "Sayuran",
+			Barcode:   "4006381333931",
+			Kind:      "simple",
+			Price:     8000 + float64(i),
+			Status:    product.StatusActive,
+			CreatedAt: time.Now(),
+		}
+	}
+	return products
+}
+
+func BenchmarkCacheCodec_ListPage(b *testing.B) {
+	page := benchmarkPage(100)
+
+	for _, c := range benchmarkCodecs {
+		codec, err := cache.NewCodec(c.format, 1, c.compressAbove)
+		if err != nil {
+			b.Fatal(err)
+		}
+		data, err := codec.Marshal(page)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(c.name+"/encode", func(b *testing.B) {
+			b.ReportMetric(float64(len(data)), "bytes")
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = codec.Marshal(page)
+			}
+		})
+		b.Run(c.name+"/decode", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var out []product.Product
+				_ = codec.Unmarshal(data, &out)
+			}
+		})
+	}
+}
+
+// BenchmarkProductUsecase_GetProductByIDCached measures a cache hit, which
+// is dominated by decoding once the value is in process.
+func BenchmarkProductUsecase_GetProductByIDCached(b *testing.B) {
+	productID := "00000000-0000-4000-8000-000000000123"
+	p := &benchmarkPage(1)[0]
+	p.ID = productID
+
+	for _, c := range benchmarkCodecs {
+		b.Run(c.name, func(b *testing.B) {
+			codec, err := cache.NewCodec(c.format, 1, c.compressAbove)
+			if err != nil {
+				b.Fatal(err)
+			}
+			repo := mockRepo.NewProductRepository(b)
+			repo.On("FindProductByID", mock.Anything, productID).Return(p, nil).Maybe()
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+			uc := usecase.NewUsecase(repo, cache.NewMemory(10), codec, nil, nil, logger, &config.Config{CacheTTL: time.Hour}, policy.Default())
+
+			ctx := context.Background()
+			_, _ = uc.GetProductByID(ctx, productID, product.VisibilityAll)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = uc.GetProductByID(ctx, productID, product.VisibilityAll)
+			}
+		})
+	}
+}
@@ -3,10 +3,19 @@ package usecase
 import (
 	"context"
 	model "simple-product-api/internal/product"
+	"time"
 )
 
 type ProductUsecase interface {
-	CreateProduct(ctx context.Context, p *model.Product) error
+	CreateProduct(ctx context.Context, p *model.Product, opts model.CreateOptions) error
 	ListProduct(ctx context.Context, filter model.ListFilter) ([]model.Product, int, error)
-	GetProductByID(ctx context.Context, id string) (*model.Product, error)
+	GetProductByID(ctx context.Context, id string, vis model.Visibility) (*model.Product, error)
+	GetProductByBarcode(ctx context.Context, code string, vis model.Visibility) (*model.Product, error)
+	UpdateProduct(ctx context.Context, id string, patch model.Patch) (*model.Product, error)
+	DeleteProduct(ctx context.Context, id string) error
+	ChangeStatus(ctx context.Context, id, status string, publishAt *time.Time) (*model.Product, error)
+	MergeProducts(ctx context.Context, targetID string, sourceIDs []string) (*model.Product, error)
+	CacheStats() map[string]model.CacheStats
+	CacheKeys(ctx context.Context, pattern string, limit int) ([]model.CacheKey, error)
+	FlushCache(ctx context.Context, pattern string) (int, error)
 }
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"simple-product-api/internal/product/repository"
+	"simple-product-api/pkg/bloom"
+	"simple-product-api/pkg/config"
+)
+
+// recentWriteMargin widens the window of IDs that MayExist lets through
+// without consulting the filter, to cover clock skew between instances and
+// the time between making an ID and committing its product.
+const recentWriteMargin = time.Minute
+
+// knownChannel carries the IDs of products created on each instance so that
+// the others add them to their filters.
+const knownChannel = "products:known"
+
+type knownMessage struct {
+	Origin string `json:"origin"`
+	ID     string `json:"id"`
+}
+
+// KnownIDs is a Bloom filter of the IDs of every product, so that lookups
+// of IDs that were never created can be answered without any I/O. It is
+// loaded from the database in the background, rebuilt periodically to drop
+// deleted IDs and repair any drift, and kept current between rebuilds by
+// the creates made on this instance and the creates published by the
+// others. A published create can be lost, so IDs made since the filter was
+// loaded are let through whether or not it has them; product IDs are
+// version 7 UUIDs, which carry their creation time. Until the first load
+// finishes every ID may exist.
+type KnownIDs struct {
+	repo     repository.ProductRepository
+	rdb      *redis.Client
+	log      *logrus.Logger
+	capacity int
+	fpRate   float64
+	rebuild  time.Duration
+	origin   string
+
+	mu   sync.RWMutex
+	cur  *bloom.Filter
+	next *bloom.Filter
+	// since is when the load that built cur started; cur may be missing
+	// IDs created after it.
+	since time.Time
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// NewKnownIDs starts loading the filter when PRODUCT_BLOOM_CAPACITY is set
+// and returns nil, which lets every ID through, otherwise.
+func NewKnownIDs(cfg *config.Config, repo repository.ProductRepository, rdb *redis.Client, log *logrus.Logger) *KnownIDs {
+	if cfg.ProductBloomCapacity <= 0 {
+		return nil
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	k := &KnownIDs{
+		repo:     repo,
+		rdb:      rdb,
+		log:      log,
+		capacity: cfg.ProductBloomCapacity,
+		fpRate:   cfg.ProductBloomFPRate,
+		rebuild:  cfg.ProductBloomRebuild,
+		origin:   uuid.NewString(),
+		stop:     stop,
+		done:     make(chan struct{}),
+	}
+	go k.run(ctx)
+	return k
+}
+
+// Close stops listening for other instances and rebuilding.
+func (k *KnownIDs) Close() error {
+	if k == nil {
+		return nil
+	}
+	k.stop()
+	<-k.done
+	return nil
+}
+
+// MayExist reports false only for IDs that were never created.
+func (k *KnownIDs) MayExist(id string) bool {
+	if k == nil {
+		return true
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.cur == nil || k.cur.Test(id) || createdSince(id, k.since.Add(-recentWriteMargin))
+}
+
+// Add records a product created on this instance and tells the others.
+func (k *KnownIDs) Add(ctx context.Context, id string) {
+	if k == nil {
+		return
+	}
+	k.add(id)
+
+	msg, _ := json.Marshal(knownMessage{Origin: k.origin, ID: id})
+	if err := k.rdb.Publish(ctx, knownChannel, msg).Err(); err != nil {
+		k.log.WithError(err).Warn("failed to publish new product id, other instances look it up in the database until their next rebuild")
+	}
+}
+
+// createdSince reports whether id is a version 7 UUID made at or after t.
+func createdSince(id string, t time.Time) bool {
+	u, err := uuid.Parse(id)
+	if err != nil || u.Version() != 7 {
+		return false
+	}
+	return !time.Unix(u.Time().UnixTime()).Before(t)
+}
+
+func (k *KnownIDs) add(id string) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.cur != nil {
+		k.cur.Add(id)
+	}
+	if k.next != nil {
+		k.next.Add(id)
+	}
+}
+
+func (k *KnownIDs) run(ctx context.Context) {
+	defer close(k.done)
+
+	// Subscribe before loading so that no create falls between the two.
+	sub := k.rdb.Subscribe(ctx, knownChannel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil && ctx.Err() == nil {
+		k.log.WithError(err).Warn("failed to subscribe to new product ids, retrying in the background")
+	}
+	msgs := sub.Channel()
+
+	loaded := make(chan error, 1)
+	load := func() {
+		go func() { loaded <- k.load(ctx) }()
+	}
+	load()
+
+	var tick <-chan time.Time
+	if k.rebuild > 0 {
+		t := time.NewTicker(k.rebuild)
+		defer t.Stop()
+		tick = t.C
+	}
+
+	loading := true
+	for {
+		select {
+		case <-ctx.Done():
+			if loading {
+				<-loaded
+			}
+			return
+		case msg, ok := <-msgs:
+			if !ok {
+				msgs = nil
+				continue
+			}
+			var m knownMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil {
+				k.log.WithError(err).Warn("ignoring malformed product id message")
+				continue
+			}
+			if m.Origin != k.origin {
+				k.add(m.ID)
+			}
+		case err := <-loaded:
+			loading = false
+			if err != nil && ctx.Err() == nil {
+				k.log.WithError(err).Error("failed to load known product ids")
+			}
+		case <-tick:
+			if !loading {
+				loading = true
+				load()
+			}
+		}
+	}
+}
+
+// load builds a new filter from the database and swaps it in. IDs created
+// while it runs are added to both filters.
+func (k *KnownIDs) load(ctx context.Context) error {
+	next := bloom.New(k.capacity, k.fpRate)
+	started := time.Now()
+	k.mu.Lock()
+	k.next = next
+	k.mu.Unlock()
+
+	err := k.repo.ScanProductIDs(ctx, next.Add)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.next = nil
+	if err != nil {
+		return err
+	}
+	k.cur, k.since = next, started
+	return nil
+}
@@ -0,0 +1,82 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	mockRepo "simple-product-api/internal/product/mocks"
+	"simple-product-api/internal/product/usecase"
+	"simple-product-api/pkg/config"
+)
+
+const (
+	knownID   = "00000000-0000-4000-8000-000000000001"
+	unknownID = "00000000-0000-4000-8000-000000000002"
+)
+
+func newKnownIDs(t *testing.T, m *miniredis.Miniredis, ids ...string) *usecase.KnownIDs {
+	repo := mockRepo.NewProductRepository(t)
+	repo.On("ScanProductIDs", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		for _, id := range ids {
+			args.Get(1).(func(string))(id)
+		}
+	}).Return(nil)
+
+	k := usecase.NewKnownIDs(&config.Config{ProductBloomCapacity: 100, ProductBloomFPRate: 0.01},
+		repo, redis.NewClient(&redis.Options{Addr: m.Addr()}), logrus.New())
+	t.Cleanup(func() { k.Close() })
+
+	require.Eventually(t, func() bool { return !k.MayExist(unknownID) }, time.Second, 5*time.Millisecond)
+	return k
+}
+
+func TestKnownIDsDisabledLetsEveryIDThrough(t *testing.T) {
+	k := usecase.NewKnownIDs(&config.Config{}, nil, nil, logrus.New())
+
+	assert.Nil(t, k)
+	assert.True(t, k.MayExist(unknownID))
+}
+
+func TestKnownIDsLoadsFromRepository(t *testing.T) {
+	k := newKnownIDs(t, miniredis.RunT(t), knownID)
+
+	assert.True(t, k.MayExist(knownID))
+	assert.False(t, k.MayExist(unknownID))
+}
+
+// idAt returns a version 7 UUID made at t.
+func idAt(t time.Time) string {
+	id := uuid.Must(uuid.NewV7())
+	ms := uint64(t.UnixMilli())
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	return id.String()
+}
+
+func TestKnownIDsLetsRecentIDsThrough(t *testing.T) {
+	k := newKnownIDs(t, miniredis.RunT(t))
+
+	// A create on another instance whose message was lost.
+	assert.True(t, k.MayExist(idAt(time.Now())))
+	assert.False(t, k.MayExist(idAt(time.Now().Add(-time.Hour))))
+}
+
+func TestKnownIDsLearnsCreatesFromOtherInstances(t *testing.T) {
+	m := miniredis.RunT(t)
+	a := newKnownIDs(t, m)
+	b := newKnownIDs(t, m)
+
+	a.Add(context.Background(), knownID)
+
+	assert.True(t, a.MayExist(knownID))
+	assert.Eventually(t, func() bool { return b.MayExist(knownID) }, time.Second, 5*time.Millisecond)
+}
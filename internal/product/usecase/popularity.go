@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	model "simple-product-api/internal/product"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/tenant"
+)
+
+const (
+	popularIDsKey   = "stats:products:ids"
+	popularListsKey = "stats:products:lists"
+	popularDecayKey = "stats:products:decayed"
+
+	// popularityFlush is how often counts are added to Redis.
+	popularityFlush = 10 * time.Second
+	// popularityKeep is how many entries are kept for each one warmed, so
+	// that entries below the top can still climb into it.
+	popularityKeep = 10
+)
+
+// popularID is a product as it is counted; members of the sorted sets are
+// these encoded as JSON.
+type popularID struct {
+	Tenant string `json:"tenant"`
+	ID     string `json:"id"`
+}
+
+type popularList struct {
+	Tenant string           `json:"tenant"`
+	Filter model.ListFilter `json:"filter"`
+}
+
+// Popularity counts how often each product and list page is requested, in
+// Redis sorted sets shared by every instance. Requests are counted in
+// process and added to Redis every few seconds, and counts are halved
+// every warmup interval so that old favourites fade. Each set is trimmed
+// to popularityKeep times the entries warmed whenever counts are added.
+type Popularity struct {
+	rdb       *redis.Client
+	log       *logrus.Logger
+	keepIDs   int64
+	keepLists int64
+
+	mu    sync.Mutex
+	ids   map[string]float64
+	lists map[string]float64
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// NewPopularity starts counting when CACHE_WARM_INTERVAL is set and
+// returns nil, which counts nothing, otherwise.
+func NewPopularity(cfg *config.Config, rdb *redis.Client, log *logrus.Logger) *Popularity {
+	if cfg.CacheWarmInterval <= 0 {
+		return nil
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	p := &Popularity{
+		rdb:       rdb,
+		log:       log,
+		keepIDs:   int64(cfg.CacheWarmIDs * popularityKeep),
+		keepLists: int64(cfg.CacheWarmLists * popularityKeep),
+		ids:       map[string]float64{},
+		lists:     map[string]float64{},
+		stop:      stop,
+		done:      make(chan struct{}),
+	}
+	go p.run(ctx)
+	return p
+}
+
+// Close stops counting and adds what was counted since the last flush.
+func (p *Popularity) Close() error {
+	if p == nil {
+		return nil
+	}
+	p.stop()
+	<-p.done
+	return nil
+}
+
+func (p *Popularity) recordID(ctx context.Context, id string) {
+	if p == nil {
+		return
+	}
+	member, _ := json.Marshal(popularID{Tenant: tenant.FromContext(ctx), ID: id})
+	p.mu.Lock()
+	p.ids[string(member)]++
+	p.mu.Unlock()
+}
+
+// recordList counts a list page. Searches are not counted: every query
+// typed would be a page of its own, few of which are asked for twice.
+func (p *Popularity) recordList(ctx context.Context, filter model.ListFilter) {
+	if p == nil || filter.Query != "" {
+		return
+	}
+	member, _ := json.Marshal(popularList{Tenant: tenant.FromContext(ctx), Filter: filter})
+	p.mu.Lock()
+	p.lists[string(member)]++
+	p.mu.Unlock()
+}
+
+// topIDs returns the n most requested products.
+func (p *Popularity) topIDs(ctx context.Context, n int) ([]popularID, error) {
+	return top[popularID](ctx, p, popularIDsKey, n)
+}
+
+// topLists returns the n most requested list pages.
+func (p *Popularity) topLists(ctx context.Context, n int) ([]popularList, error) {
+	return top[popularList](ctx, p, popularListsKey, n)
+}
+
+func top[T any](ctx context.Context, p *Popularity, key string, n int) ([]T, error) {
+	if p == nil || n <= 0 {
+		return nil, nil
+	}
+	members, err := p.rdb.ZRevRange(ctx, key, 0, int64(n-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, 0, len(members))
+	for _, m := range members {
+		var v T
+		if err := json.Unmarshal([]byte(m), &v); err != nil {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// decay halves every count, at most once per interval across all
+// instances.
+func (p *Popularity) decay(ctx context.Context, interval time.Duration) error {
+	if p == nil {
+		return nil
+	}
+	if ok, err := p.rdb.SetNX(ctx, popularDecayKey, 1, interval).Result(); err != nil || !ok {
+		return err
+	}
+	_, err := p.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range []string{popularIDsKey, popularListsKey} {
+			pipe.ZUnionStore(ctx, key, &redis.ZStore{Keys: []string{key}, Weights: []float64{0.5}})
+		}
+		p.trim(ctx, pipe)
+		return nil
+	})
+	return err
+}
+
+// trim drops all but the most requested entries of each kind.
+func (p *Popularity) trim(ctx context.Context, pipe redis.Pipeliner) {
+	pipe.ZRemRangeByRank(ctx, popularIDsKey, 0, -p.keepIDs-1)
+	pipe.ZRemRangeByRank(ctx, popularListsKey, 0, -p.keepLists-1)
+}
+
+func (p *Popularity) run(ctx context.Context) {
+	defer close(p.done)
+
+	t := time.NewTicker(popularityFlush)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			p.flush(context.Background())
+			return
+		case <-t.C:
+			p.flush(ctx)
+		}
+	}
+}
+
+// flush adds the counts gathered since the last flush to Redis. Counts
+// that cannot be added are dropped; they only steer warmups.
+func (p *Popularity) flush(ctx context.Context) {
+	p.mu.Lock()
+	ids, lists := p.ids, p.lists
+	p.ids, p.lists = map[string]float64{}, map[string]float64{}
+	p.mu.Unlock()
+
+	if len(ids) == 0 && len(lists) == 0 {
+		return
+	}
+	_, err := p.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for member, n := range ids {
+			pipe.ZIncrBy(ctx, popularIDsKey, n, member)
+		}
+		for member, n := range lists {
+			pipe.ZIncrBy(ctx, popularListsKey, n, member)
+		}
+		p.trim(ctx, pipe)
+		return nil
+	})
+	if err != nil {
+		p.log.WithError(err).Warn("failed to record product popularity")
+	}
+}
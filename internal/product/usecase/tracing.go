@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	model "simple-product-api/internal/product"
+)
+
+var tracer = otel.Tracer("simple-product-api/internal/product/usecase")
+
+// Traced wraps a ProductUsecase in a span per method call, named
+// "ProductUsecase.<Method>".
+type Traced struct {
+	next ProductUsecase
+}
+
+func NewTraced(uc *Usecase) ProductUsecase {
+	return &Traced{next: uc}
+}
+
+func startSpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "ProductUsecase."+method, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span, recording err.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t *Traced) CreateProduct(ctx context.Context, p *model.Product, opts model.CreateOptions) (err error) {
+	ctx, span := startSpan(ctx, "CreateProduct", attribute.String("product.type", p.Type))
+	defer func() { endSpan(span, err) }()
+	return t.next.CreateProduct(ctx, p, opts)
+}
+
+func (t *Traced) ListProduct(ctx context.Context, filter model.ListFilter) (_ []model.Product, _ int, err error) {
+	ctx, span := startSpan(ctx, "ListProduct", attribute.Int("list.page", filter.Page), attribute.Int("list.page_size", filter.PageSize))
+	defer func() { endSpan(span, err) }()
+	return t.next.ListProduct(ctx, filter)
+}
+
+func (t *Traced) GetProductByID(ctx context.Context, id string, vis model.Visibility) (_ *model.Product, err error) {
+	ctx, span := startSpan(ctx, "GetProductByID", attribute.String("product.id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.GetProductByID(ctx, id, vis)
+}
+
+func (t *Traced) GetProductByBarcode(ctx context.Context, code string, vis model.Visibility) (_ *model.Product, err error) {
+	ctx, span := startSpan(ctx, "GetProductByBarcode")
+	defer func() { endSpan(span, err) }()
+	return t.next.GetProductByBarcode(ctx, code, vis)
+}
+
+func (t *Traced) UpdateProduct(ctx context.Context, id string, patch model.Patch) (_ *model.Product, err error) {
+	ctx, span := startSpan(ctx, "UpdateProduct", attribute.String("product.id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.UpdateProduct(ctx, id, patch)
+}
+
+func (t *Traced) DeleteProduct(ctx context.Context, id string) (err error) {
+	ctx, span := startSpan(ctx, "DeleteProduct", attribute.String("product.id", id))
+	defer func() { endSpan(span, err) }()
+	return t.next.DeleteProduct(ctx, id)
+}
+
+func (t *Traced) ChangeStatus(ctx context.Context, id, status string, publishAt *time.Time) (_ *model.Product, err error) {
+	ctx, span := startSpan(ctx, "ChangeStatus", attribute.String("product.id", id), attribute.String("product.status", status))
+	defer func() { endSpan(span, err) }()
+	return t.next.ChangeStatus(ctx, id, status, publishAt)
+}
+
+func (t *Traced) MergeProducts(ctx context.Context, targetID string, sourceIDs []string) (_ *model.Product, err error) {
+	ctx, span := startSpan(ctx, "MergeProducts", attribute.String("product.id", targetID), attribute.Int("merge.sources", len(sourceIDs)))
+	defer func() { endSpan(span, err) }()
+	return t.next.MergeProducts(ctx, targetID, sourceIDs)
+}
+
+func (t *Traced) CacheStats() map[string]model.CacheStats {
+	return t.next.CacheStats()
+}
+
+func (t *Traced) CacheKeys(ctx context.Context, pattern string, limit int) (_ []model.CacheKey, err error) {
+	ctx, span := startSpan(ctx, "CacheKeys")
+	defer func() { endSpan(span, err) }()
+	return t.next.CacheKeys(ctx, pattern, limit)
+}
+
+func (t *Traced) FlushCache(ctx context.Context, pattern string) (_ int, err error) {
+	ctx, span := startSpan(ctx, "FlushCache")
+	defer func() { endSpan(span, err) }()
+	return t.next.FlushCache(ctx, pattern)
+}
@@ -2,129 +2,667 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/avast/retry-go/v4"
-	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
-	"simple-product-api/internal/product"
+	model "simple-product-api/internal/product"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/barcode"
+	"simple-product-api/pkg/cache"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/deadline"
+	"simple-product-api/pkg/fuzzy"
+	"simple-product-api/pkg/policy"
+	"simple-product-api/pkg/tenant"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
 	"simple-product-api/internal/product/repository"
 )
 
+// maxDuplicateMatches caps how many near-duplicates are reported back, and
+// maxDuplicateCandidates how many similar names are fetched to score.
+const (
+	maxDuplicateMatches    = 5
+	maxDuplicateCandidates = 50
+)
+
 type Usecase struct {
-	Repo  repository.ProductRepository
-	Redis *redis.Client
-	Log   *logrus.Logger
+	Repo    repository.ProductRepository
+	Cache   cache.Cache
+	Codec   *cache.Codec
+	Known   *KnownIDs
+	Popular *Popularity
+	Log     *logrus.Logger
+	Cfg     *config.Config
+	Policy  *policy.Policy
+
+	// flight coalesces concurrent cache fills for the same key.
+	flight singleflight.Group
+	// stats counts cache hits and misses on this instance.
+	stats cacheStats
+	// background tracks the cache writes and refreshes that outlive their
+	// requests, for Drain.
+	background sync.WaitGroup
 }
 
-func NewUsecase(repo repository.ProductRepository, redis *redis.Client, log *logrus.Logger) *Usecase {
-	return &Usecase{Repo: repo, Redis: redis, Log: log}
+func NewUsecase(repo repository.ProductRepository, c cache.Cache, codec *cache.Codec, known *KnownIDs, popular *Popularity, log *logrus.Logger, cfg *config.Config, pol *policy.Policy) *Usecase {
+	return &Usecase{Repo: repo, Cache: c, Codec: codec, Known: known, Popular: popular, Log: log, Cfg: cfg, Policy: pol}
 }
 
-func (uc *Usecase) CreateProduct(ctx context.Context, product *product.Product) error {
-	uc.Log.WithFields(logrus.Fields{
+func (uc *Usecase) CreateProduct(ctx context.Context, product *model.Product, opts model.CreateOptions) error {
+	uc.Log.WithContext(ctx).WithFields(logrus.Fields{
 		"name":  product.Name,
 		"type":  product.Type,
 		"price": product.Price,
 	}).Info("creating new product")
 
+	if err := uc.authorize(ctx, policy.ActionCreate, policy.Resource{}, createFields(product)...); err != nil {
+		return err
+	}
+
 	existing, err := uc.Repo.FindProductByNameAndType(ctx, product.Name, product.Type)
 	if err != nil {
-		uc.Log.Error("failed to check existing product: ", err)
+		uc.Log.WithContext(ctx).Error("failed to check existing product: ", err)
 		return err
 	}
 	if existing != nil {
-		return fmt.Errorf("product with name '%s' and type '%s' already exists", product.Name, product.Type)
+		return fmt.Errorf("%w: product with name '%s' and type '%s' already exists", model.ErrDuplicate, product.Name, product.Type)
+	}
+
+	if !opts.Force {
+		matches, err := uc.findNearDuplicates(ctx, product)
+		if err != nil {
+			uc.Log.WithContext(ctx).Error("failed to check similar products: ", err)
+			return err
+		}
+		if len(matches) > 0 {
+			return &model.DuplicateError{Matches: matches}
+		}
+	}
+
+	if product.Barcode != "" {
+		product.Barcode = barcode.Normalize(product.Barcode)
+		existing, err = uc.Repo.FindProductByBarcode(ctx, product.Barcode)
+		if err != nil {
+			uc.Log.WithContext(ctx).Error("failed to check existing barcode: ", err)
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("%w: product with barcode '%s' already exists", model.ErrDuplicate, product.Barcode)
+		}
 	}
 
-	product.ID = uuid.New().String()
+	// Version 7 IDs carry their creation time, which KnownIDs relies on.
+	product.ID = uuid.Must(uuid.NewV7()).String()
 	product.CreatedAt = time.Now()
+	product.Status = model.StatusDraft
+	product.PublishAt = nil
+
+	if product.Kind == "" {
+		product.Kind = model.KindSimple
+	}
+	if product.Kind == model.KindBundle {
+		if err := uc.prepareBundle(ctx, product); err != nil {
+			return err
+		}
+	} else if len(product.Components) > 0 {
+		return fmt.Errorf("%w: only bundles can have components", model.ErrInvalidBundle)
+	}
 
 	err = uc.Repo.SaveProduct(ctx, product)
 	if err != nil {
-		uc.Log.Error("error save product: ", err)
+		uc.Log.WithContext(ctx).Error("error save product: ", err)
 		return err
 	}
 
+	uc.Known.Add(ctx, product.ID)
+	// Drops lookups of its barcode cached as missing, and list pages it
+	// now belongs on.
+	uc.evict(ctx, product)
+
 	return nil
 }
 
-func (uc *Usecase) ListProduct(ctx context.Context, filter product.ListFilter) (products []product.Product, total int, err error) {
-	uc.Log.WithFields(logrus.Fields{
+func (uc *Usecase) ListProduct(ctx context.Context, filter model.ListFilter) (products []model.Product, total int, err error) {
+	uc.Log.WithContext(ctx).WithFields(logrus.Fields{
 		"query": filter.Query,
 		"type":  filter.Type,
 		"page":  filter.Page,
 		"size":  filter.PageSize,
 	}).Info("listing products")
 
-	cacheKey := fmt.Sprintf("products:all:name=%s:type=%s:sort=%s:order=%s:page=%d:size=%d",
-		filter.Query, filter.Type, filter.SortBy, filter.Order, filter.Page, filter.PageSize,
+	if filter.Visibility == "" {
+		filter.Visibility = model.VisibilityPublic
+	}
+
+	page, err := uc.listProducts(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	uc.Popular.recordList(ctx, filter)
+	return page.Products, page.Total, nil
+}
+
+func (uc *Usecase) listProducts(ctx context.Context, filter model.ListFilter) (listPage, error) {
+	cacheKey := tenantKey(ctx, "products:all:name=%s:type=%s:sort=%s:order=%s:page=%d:size=%d:vis=%s",
+		filter.Query, filter.Type, filter.SortBy, filter.Order, filter.Page, filter.PageSize, filter.Visibility,
 	)
 
-	cached, err := uc.Redis.Get(ctx, cacheKey).Result()
-	if err == nil {
-		uc.Log.WithField("cache_key", cacheKey).Info("redis cache present")
-		var cachedProducts []product.Product
-		if err := json.Unmarshal([]byte(cached), &cachedProducts); err == nil {
-			return cachedProducts, len(cachedProducts), nil
+	return fetch(uc, ctx, cacheKey, func(ctx context.Context) (listPage, error) {
+		products, total, err := uc.Repo.FindProduct(ctx, filter)
+		return listPage{Products: products, Total: total}, err
+	}, tenantKey(ctx, "products:lists"))
+}
+
+// listPage is a page of ListProduct results as it is cached.
+type listPage struct {
+	Products []model.Product `json:"products"`
+	Total    int             `json:"total"`
+}
+
+func (uc *Usecase) GetProductByID(ctx context.Context, id string, vis model.Visibility) (*model.Product, error) {
+	uc.Log.WithContext(ctx).WithField("id", id).Info("retrieving product by ID")
+
+	p, err := uc.findProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.MergedInto != "" {
+		return nil, &model.MergedError{ID: id, MergedInto: p.MergedInto}
+	}
+	if !vis.Allows(p, time.Now()) {
+		return nil, fmt.Errorf("%w: %s", model.ErrNotFound, id)
+	}
+	uc.Popular.recordID(ctx, id)
+	return p, nil
+}
+
+// findProductByID looks a product up through the cache regardless of its
+// status.
+func (uc *Usecase) findProductByID(ctx context.Context, id string) (*model.Product, error) {
+	if err := uc.checkID(id); err != nil {
+		return nil, err
+	}
+	return fetch(uc, ctx, tenantKey(ctx, "products:id:%s", id), func(ctx context.Context) (*model.Product, error) {
+		p, err := uc.Repo.FindProductByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := uc.loadComponents(ctx, p); err != nil {
+			return nil, err
 		}
-	} else if err == redis.Nil {
-		uc.Log.WithField("cache_key", cacheKey).Warn("redis cache missing")
-	} else {
-		uc.Log.WithError(err).Error("redis error")
+		return p, nil
+	})
+}
+
+func (uc *Usecase) GetProductByBarcode(ctx context.Context, code string, vis model.Visibility) (*model.Product, error) {
+	uc.Log.WithContext(ctx).WithField("barcode", code).Info("retrieving product by barcode")
+
+	p, err := uc.findProductByBarcode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !vis.Allows(p, time.Now()) {
+		return nil, fmt.Errorf("%w: barcode %s", model.ErrNotFound, code)
 	}
+	return p, nil
+}
+
+func (uc *Usecase) findProductByBarcode(ctx context.Context, code string) (*model.Product, error) {
+	code = barcode.Normalize(code)
+	if !barcode.IsValidGTIN(code) {
+		return nil, fmt.Errorf("%w: %s", model.ErrInvalidBarcode, code)
+	}
+
+	return fetch(uc, ctx, tenantKey(ctx, "products:barcode:%s", code), func(ctx context.Context) (*model.Product, error) {
+		p, err := uc.Repo.FindProductByBarcode(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			return nil, fmt.Errorf("%w: barcode %s", model.ErrNotFound, code)
+		}
+		if err := uc.loadComponents(ctx, p); err != nil {
+			return nil, err
+		}
+		return p, nil
+	})
+}
+
+// UpdateProduct applies a partial update. Name and type changes are checked
+// for exact duplicates, and the price of a bundle with computed pricing is
+// recalculated instead of being set directly. Computed bundles containing
+// the product are repriced along with it and dropped from the cache.
+func (uc *Usecase) UpdateProduct(ctx context.Context, id string, patch model.Patch) (*model.Product, error) {
+	uc.Log.WithContext(ctx).WithFields(logrus.Fields{
+		"id":     id,
+		"fields": patch.Fields(),
+	}).Info("updating product")
 
-	products, total, err = uc.Repo.FindProduct(ctx, filter)
+	p, err := uc.loadProduct(ctx, id)
 	if err != nil {
-		return products, total, err
+		return nil, err
+	}
+	if p.MergedInto != "" {
+		return nil, &model.MergedError{ID: p.ID, MergedInto: p.MergedInto}
+	}
+	if err := uc.authorize(ctx, policy.ActionUpdate, resource(p), patch.Fields()...); err != nil {
+		return nil, err
+	}
+	old := *p
+
+	if patch.Name != nil {
+		p.Name = *patch.Name
+	}
+	if patch.Type != nil {
+		p.Type = *patch.Type
+	}
+	if p.Name != old.Name || p.Type != old.Type {
+		existing, err := uc.Repo.FindProductByNameAndType(ctx, p.Name, p.Type)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.ID != p.ID {
+			return nil, fmt.Errorf("%w: product with name '%s' and type '%s' already exists", model.ErrDuplicate, p.Name, p.Type)
+		}
 	}
 
-	data, err := json.Marshal(products)
+	if patch.Barcode != nil && barcode.Normalize(*patch.Barcode) != p.Barcode {
+		p.Barcode = barcode.Normalize(*patch.Barcode)
+		if p.Barcode != "" {
+			existing, err := uc.Repo.FindProductByBarcode(ctx, p.Barcode)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil && existing.ID != p.ID {
+				return nil, fmt.Errorf("%w: product with barcode '%s' already exists", model.ErrDuplicate, p.Barcode)
+			}
+		}
+	}
+
+	if patch.Discount != nil {
+		p.Discount = *patch.Discount
+	}
+	if patch.Price != nil {
+		if p.Kind == model.KindBundle && p.Pricing == model.PricingComputed {
+			return nil, fmt.Errorf("%w: the price of a computed bundle follows its components", model.ErrInvalidBundle)
+		}
+		p.Price = *patch.Price
+		if p.Kind != model.KindBundle && p.Price <= 0 {
+			return nil, fmt.Errorf("%w: price must be greater than zero", model.ErrInvalidPrice)
+		}
+	}
+	if p.Kind == model.KindBundle && (patch.Price != nil || patch.Discount != nil) {
+		if err := uc.loadComponents(ctx, p); err != nil {
+			return nil, err
+		}
+		if err := uc.prepareBundle(ctx, p); err != nil {
+			return nil, err
+		}
+		if err := uc.checkCycles(ctx, p.ID, p.Components); err != nil {
+			return nil, err
+		}
+	}
+
+	repriced, err := uc.Repo.UpdateProduct(ctx, p)
 	if err != nil {
-		uc.Log.Errorf("failed to marshal products for caching: %v", err)
-		return
+		uc.Log.WithContext(ctx).Error("error update product: ", err)
+		return nil, err
 	}
 
-	go uc.Redis.Set(ctx, cacheKey, data, 5*time.Minute)
+	uc.evict(ctx, &old)
+	if p.Barcode != old.Barcode {
+		uc.evict(ctx, p)
+	}
+	for i := range repriced {
+		uc.evict(ctx, &repriced[i])
+	}
 
-	return products, total, nil
+	return p, nil
 }
 
-func (uc *Usecase) GetProductByID(ctx context.Context, id string) (products *product.Product, err error) {
-	uc.Log.WithField("id", id).Info("retrieving product by ID")
+func (uc *Usecase) DeleteProduct(ctx context.Context, id string) error {
+	uc.Log.WithContext(ctx).WithField("id", id).Info("deleting product")
 
-	cacheKey := "products:id:" + id
+	existing, err := uc.loadProduct(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing.MergedInto != "" {
+		return &model.MergedError{ID: existing.ID, MergedInto: existing.MergedInto}
+	}
+	if err := uc.authorize(ctx, policy.ActionDelete, resource(existing)); err != nil {
+		return err
+	}
 
-	var cached string
-	err = retry.Do(func() error {
-		var err error
-		cached, err = uc.Redis.Get(ctx, cacheKey).Result()
+	bundles, err := uc.Repo.FindBundleIDsByComponent(ctx, id)
+	if err != nil {
+		uc.Log.WithContext(ctx).Error("failed to check bundles using product: ", err)
 		return err
-	}, retry.Attempts(3), retry.DelayType(retry.BackOffDelay))
+	}
+	if len(bundles) > 0 {
+		return fmt.Errorf("%w: used by %s", model.ErrInUse, strings.Join(bundles, ", "))
+	}
+
+	if err := uc.Repo.DeleteProduct(ctx, id); err != nil {
+		uc.Log.WithContext(ctx).Error("error delete product: ", err)
+		return err
+	}
+
+	uc.evict(ctx, existing)
+
+	return nil
+}
+
+func (uc *Usecase) ChangeStatus(ctx context.Context, id, status string, publishAt *time.Time) (*model.Product, error) {
+	uc.Log.WithContext(ctx).WithFields(logrus.Fields{
+		"id":     id,
+		"status": status,
+	}).Info("changing product status")
+
+	p, err := uc.loadProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(ctx, policy.ActionChangeStatus, policy.Resource{Status: p.Status, TargetStatus: status}); err != nil {
+		return nil, err
+	}
+
+	if !model.CanTransition(p.Status, status) {
+		return nil, fmt.Errorf("%w: %s to %s", model.ErrInvalidTransition, p.Status, status)
+	}
+	if publishAt != nil && status != model.StatusActive {
+		return nil, fmt.Errorf("%w: publish_at only applies when activating", model.ErrInvalidTransition)
+	}
 
-	if cached != "" {
-		if err := json.Unmarshal([]byte(cached), &products); err != nil {
-			uc.Log.Errorf("error unmarshall from redis: %v", err.Error())
-		} else {
-			return products, nil
+	if err := uc.Repo.UpdateStatus(ctx, id, p.Status, status, publishAt); err != nil {
+		uc.Log.WithContext(ctx).Error("error update product status: ", err)
+		return nil, err
+	}
+	p.Status = status
+	p.PublishAt = publishAt
+
+	uc.evict(ctx, p)
+
+	return p, nil
+}
+
+func (uc *Usecase) MergeProducts(ctx context.Context, targetID string, sourceIDs []string) (*model.Product, error) {
+	uc.Log.WithContext(ctx).WithFields(logrus.Fields{
+		"target":  targetID,
+		"sources": sourceIDs,
+	}).Info("merging products")
+
+	if len(sourceIDs) == 0 {
+		return nil, fmt.Errorf("%w: no source products given", model.ErrInvalidMerge)
+	}
+
+	target, err := uc.loadProduct(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(ctx, policy.ActionMerge, resource(target)); err != nil {
+		return nil, err
+	}
+	if target.MergedInto != "" {
+		return nil, fmt.Errorf("%w: target %s was itself merged into %s", model.ErrInvalidMerge, targetID, target.MergedInto)
+	}
+
+	targetComponents, err := uc.Repo.FindComponents(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{targetID: true}
+	sources := make([]*model.Product, 0, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("%w: %s is listed twice or is the target", model.ErrInvalidMerge, id)
 		}
+		seen[id] = true
+
+		source, err := uc.loadProduct(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if source.MergedInto != "" {
+			return nil, fmt.Errorf("%w: %s was already merged into %s", model.ErrInvalidMerge, id, source.MergedInto)
+		}
+		sources = append(sources, source)
 	}
 
-	products, err = uc.Repo.FindProductByID(ctx, id)
+	// Bundles containing a source are repointed at the target, so a target
+	// that contains a source at any depth would end up containing itself.
+	delete(seen, targetID)
+	found, err := uc.findReachable(ctx, targetComponents, seen)
 	if err != nil {
 		return nil, err
 	}
+	if found != "" {
+		return nil, fmt.Errorf("%w: target bundle contains source %s", model.ErrInvalidMerge, found)
+	}
+
+	if err := uc.Repo.MergeProducts(ctx, targetID, sourceIDs); err != nil {
+		uc.Log.WithContext(ctx).Error("error merge products: ", err)
+		return nil, err
+	}
+
+	for _, source := range sources {
+		uc.evict(ctx, source)
+	}
+	uc.evict(ctx, target)
+
+	return uc.Repo.FindProductByID(ctx, targetID)
+}
+
+// authorize asks the policy whether the caller in ctx may perform action on
+// p, writing the given fields. p is nil for products that do not exist yet.
+func (uc *Usecase) authorize(ctx context.Context, action string, res policy.Resource, fields ...string) error {
+	return uc.Policy.Authorize(policy.SubjectFrom(auth.FromContext(ctx)), action, res, fields...)
+}
+
+// resource describes a stored product to the access policy.
+func resource(p *model.Product) policy.Resource {
+	return policy.Resource{Status: p.Status}
+}
+
+// createFields lists the fields a create request sets, so that roles
+// without write access to, say, prices cannot create priced products.
+func createFields(p *model.Product) []string {
+	fields := []string{"name", "type"}
+	if p.Kind != model.KindBundle || p.Pricing == model.PricingFixed {
+		fields = append(fields, "price")
+	}
+	if p.Barcode != "" {
+		fields = append(fields, "barcode")
+	}
+	if p.Discount != 0 {
+		fields = append(fields, "discount")
+	}
+	if len(p.Components) > 0 {
+		fields = append(fields, "components")
+	}
+	return fields
+}
+
+// checkID rejects IDs that are not UUIDs, which no product has, and IDs the
+// known ID filter has never seen.
+func (uc *Usecase) checkID(id string) error {
+	if err := uuid.Validate(id); err != nil {
+		return fmt.Errorf("%w: %s", model.ErrInvalidID, id)
+	}
+	if !uc.Known.MayExist(id) {
+		return fmt.Errorf("%w: %s", model.ErrNotFound, id)
+	}
+	return nil
+}
+
+// loadProduct reads a product from the database, bypassing the cache, for
+// changes that must start from its current state.
+func (uc *Usecase) loadProduct(ctx context.Context, id string) (*model.Product, error) {
+	if err := uc.checkID(id); err != nil {
+		return nil, err
+	}
+	return uc.Repo.FindProductByID(ctx, id)
+}
+
+// tenantKey prefixes a cache key with the tenant of ctx so that stores never
+// see each other's cached products.
+func tenantKey(ctx context.Context, format string, args ...interface{}) string {
+	return "tenant:" + tenant.FromContext(ctx) + ":" + fmt.Sprintf(format, args...)
+}
+
+// evict drops the cached copies of a product along with every cached list
+// page, since a change to one product can move it in or out of any page.
+// The change is already stored, so eviction goes ahead even if the request
+// has been canceled in the meantime.
+func (uc *Usecase) evict(ctx context.Context, p *model.Product) {
+	ctx, cancel := deadline.Detach(ctx, uc.Cfg.CacheWriteTimeout)
+	defer cancel()
 
-	data, err := json.Marshal(products)
+	keys := []string{tenantKey(ctx, "products:id:%s", p.ID)}
+	if p.Barcode != "" {
+		keys = append(keys, tenantKey(ctx, "products:barcode:%s", p.Barcode))
+	}
+
+	if err := uc.Cache.Delete(ctx, keys...); err != nil {
+		uc.Log.WithContext(ctx).WithError(err).Warn("failed to evict product from cache")
+	}
+	if err := uc.Cache.InvalidateTags(ctx, tenantKey(ctx, "products:lists")); err != nil {
+		uc.Log.WithContext(ctx).WithError(err).Warn("failed to evict cached product lists")
+	}
+}
+
+// findNearDuplicates scores the normalized name of p against the products of
+// the same type whose names the repository finds similar, and returns those
+// scoring at or above the configured threshold, best match first.
+func (uc *Usecase) findNearDuplicates(ctx context.Context, p *model.Product) ([]model.Match, error) {
+	candidates, err := uc.Repo.FindSimilarProducts(ctx, p.Name, p.Type, maxDuplicateCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []model.Match
+	for _, c := range candidates {
+		if score := fuzzy.Similarity(p.Name, c.Name); score >= uc.Cfg.DuplicateThreshold {
+			matches = append(matches, model.Match{Product: c, Score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if len(matches) > maxDuplicateMatches {
+		matches = matches[:maxDuplicateMatches]
+	}
+	return matches, nil
+}
+
+// prepareBundle checks the components of a bundle and settles its price.
+// It does not look for cycles: a new bundle cannot be anyone's component
+// yet, so callers updating an existing one run checkCycles themselves.
+func (uc *Usecase) prepareBundle(ctx context.Context, bundle *model.Product) error {
+	if len(bundle.Components) == 0 {
+		return fmt.Errorf("%w: a bundle needs at least one component", model.ErrInvalidBundle)
+	}
+	if bundle.Pricing == "" {
+		bundle.Pricing = model.PricingComputed
+	}
+
+	seen := make(map[string]bool, len(bundle.Components))
+	sum := 0.0
+	for _, c := range bundle.Components {
+		if c.Quantity <= 0 {
+			return fmt.Errorf("%w: component %s needs a positive quantity", model.ErrInvalidBundle, c.ProductID)
+		}
+		if err := uuid.Validate(c.ProductID); err != nil {
+			return fmt.Errorf("%w: component %s", model.ErrInvalidID, c.ProductID)
+		}
+		if seen[c.ProductID] {
+			return fmt.Errorf("%w: component %s is listed twice", model.ErrInvalidBundle, c.ProductID)
+		}
+		seen[c.ProductID] = true
+
+		component, err := uc.loadProduct(ctx, c.ProductID)
+		if errors.Is(err, model.ErrNotFound) {
+			return fmt.Errorf("%w: component %s does not exist", model.ErrInvalidBundle, c.ProductID)
+		}
+		if err != nil {
+			return err
+		}
+		if component.MergedInto != "" {
+			return fmt.Errorf("%w: component %s was merged into %s", model.ErrInvalidBundle, c.ProductID, component.MergedInto)
+		}
+		sum += component.Price * float64(c.Quantity)
+	}
+
+	if bundle.Pricing == model.PricingComputed {
+		bundle.Price = sum - bundle.Discount
+	}
+	if bundle.Price <= 0 {
+		return fmt.Errorf("%w: bundle price must be greater than zero", model.ErrInvalidBundle)
+	}
+	return nil
+}
+
+// checkCycles walks the component graph below a bundle and rejects any path
+// that leads back to the bundle itself.
+func (uc *Usecase) checkCycles(ctx context.Context, bundleID string, components []model.Component) error {
+	found, err := uc.findReachable(ctx, components, map[string]bool{bundleID: true})
 	if err != nil {
-		uc.Log.Errorf("failed to marshal products for caching: %v", err)
-		return
+		return err
+	}
+	if found != "" {
+		return fmt.Errorf("%w: bundle %s would contain itself", model.ErrInvalidBundle, bundleID)
+	}
+	return nil
+}
+
+// findReachable walks the component graph from components down and returns
+// the first of ids it reaches, or "" when it reaches none of them.
+func (uc *Usecase) findReachable(ctx context.Context, components []model.Component, ids map[string]bool) (string, error) {
+	visited := make(map[string]bool)
+	stack := make([]string, 0, len(components))
+	for _, c := range components {
+		stack = append(stack, c.ProductID)
 	}
 
-	go uc.Redis.Set(ctx, cacheKey, data, 5*time.Minute)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
 
-	return products, nil
+		if ids[id] {
+			return id, nil
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		children, err := uc.Repo.FindComponents(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		for _, c := range children {
+			stack = append(stack, c.ProductID)
+		}
+	}
+	return "", nil
+}
+
+func (uc *Usecase) loadComponents(ctx context.Context, p *model.Product) error {
+	if p.Kind != model.KindBundle {
+		return nil
+	}
+	components, err := uc.Repo.FindComponents(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	p.Components = components
+	return nil
 }
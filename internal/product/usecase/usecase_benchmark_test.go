@@ -8,6 +8,9 @@ import (
 	"simple-product-api/internal/product"
 	mockRepo "simple-product-api/internal/product/mocks"
 	"simple-product-api/internal/product/usecase"
+	"simple-product-api/pkg/cache"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/policy"
 	"testing"
 	"time"
 )
@@ -23,7 +26,7 @@ func setupBenchmarkEnv(tb testing.TB) *benchmarkEnv {
 	logger := logrus.New()
 	repo := mockRepo.NewProductRepository(tb)
 
-	uc := usecase.NewUsecase(repo, rdb, logger)
+	uc := usecase.NewUsecase(repo, cache.NewRedis(rdb), testCodec(), nil, nil, logger, &config.Config{DuplicateThreshold: 0.85}, policy.Default())
 
 	return &benchmarkEnv{
 		usecase:   uc,
@@ -35,7 +38,7 @@ func setupBenchmarkEnv(tb testing.TB) *benchmarkEnv {
 func BenchmarkProductUsecase_GetProductByID(b *testing.B) {
 	env := setupBenchmarkEnv(b)
 
-	productID := "test-123"
+	productID := "00000000-0000-4000-8000-000000000123"
 	p := &product.Product{
 		ID:        productID,
 		Name:      "Tomato",
@@ -48,7 +51,7 @@ func BenchmarkProductUsecase_GetProductByID(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = env.usecase.GetProductByID(context.Background(), productID)
+		_, _ = env.usecase.GetProductByID(context.Background(), productID, product.VisibilityAll)
 	}
 }
 
@@ -72,6 +75,7 @@ func BenchmarkProductUsecase_ListProduct(b *testing.B) {
 func BenchmarkProductUsecase_CreateProduct(b *testing.B) {
 	env := setupBenchmarkEnv(b)
 	env.mockRepo.On("FindProductByNameAndType", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	env.mockRepo.On("FindSimilarProducts", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
 
 	env.mockRepo.On("SaveProduct", mock.Anything, mock.Anything).Return(nil)
 
@@ -85,6 +89,6 @@ func BenchmarkProductUsecase_CreateProduct(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = env.usecase.CreateProduct(context.Background(), p)
+		_ = env.usecase.CreateProduct(asRole("admin"), p, product.CreateOptions{})
 	}
 }
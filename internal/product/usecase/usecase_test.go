@@ -4,32 +4,88 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	redismock "github.com/go-redis/redismock/v9"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"simple-product-api/internal/product"
 	//mockRepo "simple-product-api/internal/product/mocks"
 	mockRepo "simple-product-api/internal/product/mocks"
 	"simple-product-api/internal/product/usecase"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/breaker"
+	"simple-product-api/pkg/cache"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/policy"
+	"simple-product-api/pkg/tenant"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 type UsecaseProductTestSuite struct {
 	suite.Suite
-	usecase   *usecase.Usecase
-	mockRepo  *mockRepo.ProductRepository
-	redisMock redismock.ClientMock
+	usecase  *usecase.Usecase
+	mockRepo *mockRepo.ProductRepository
+	cache    *cache.Memory
 }
 
 func (s *UsecaseProductTestSuite) SetupTest() {
-	rdb, mock := redismock.NewClientMock()
-	s.redisMock = mock
+	s.cache = cache.NewMemory(100)
 	s.mockRepo = mockRepo.NewProductRepository(s.T())
 	logger := logrus.New()
-	s.usecase = usecase.NewUsecase(s.mockRepo, rdb, logger)
+	s.usecase = usecase.NewUsecase(s.mockRepo, s.cache, testCodec(), nil, nil, logger, &config.Config{DuplicateThreshold: 0.85, CacheTTL: 5 * time.Minute}, policy.Default())
+}
+
+// failingCache is a cache whose every call fails with err.
+type failingCache struct{ err error }
+
+func (f failingCache) Get(context.Context, string) ([]byte, error) { return nil, f.err }
+func (f failingCache) Set(context.Context, string, []byte, time.Duration, ...string) error {
+	return f.err
+}
+func (f failingCache) Delete(context.Context, ...string) error         { return f.err }
+func (f failingCache) InvalidateTags(context.Context, ...string) error { return f.err }
+func (f failingCache) TTL(context.Context, string) (time.Duration, error) {
+	return 0, f.err
+}
+
+// cached reports whether key is in the suite's cache, waiting for the
+// background write that fills it.
+// testCodec is the codec the service uses by default.
+func testCodec() *cache.Codec {
+	codec, err := usecase.NewCacheCodec(&config.Config{CacheCodec: cache.FormatMsgpack, CacheCompressAbove: 1024})
+	if err != nil {
+		panic(err)
+	}
+	return codec
+}
+
+func (s *UsecaseProductTestSuite) cached(key string) bool {
+	return s.Eventually(func() bool {
+		_, err := s.cache.Get(context.Background(), key)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func (s *UsecaseProductTestSuite) put(key string, value []byte) {
+	s.NoError(s.cache.Set(context.Background(), key, value, time.Minute))
+}
+
+func (s *UsecaseProductTestSuite) gone(key string) bool {
+	_, err := s.cache.Get(context.Background(), key)
+	return errors.Is(err, cache.ErrMiss)
+}
+
+// asRole returns a context authenticated as a caller holding role.
+func asRole(role string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: role, Roles: []string{role}})
 }
 
 func (s *UsecaseProductTestSuite) TearDownTest() {
@@ -42,33 +98,32 @@ func TestUsecaseProductTestSuite(t *testing.T) {
 
 func (s *UsecaseProductTestSuite) TestGetByIDWithRedisPresentSuccess() {
 	expectedProduct := &product.Product{
-		ID:    "123",
-		Name:  "Sawi",
-		Type:  "Sayuran",
-		Price: float64(5000),
+		ID:     "00000000-0000-4000-8000-000000000123",
+		Name:   "Sawi",
+		Type:   "Sayuran",
+		Price:  float64(5000),
+		Status: product.StatusActive,
 	}
 
-	cacheKey := "products:id:123"
+	cacheKey := "tenant:default:products:id:00000000-0000-4000-8000-000000000123"
 	data, _ := json.Marshal(expectedProduct)
 
-	s.redisMock.ExpectGet(cacheKey).SetVal(string(data))
+	s.put(cacheKey, data)
 
 	// just in case
-	s.mockRepo.AssertNotCalled(s.T(), "FindProductByID", mock.Anything, "123")
+	s.mockRepo.AssertNotCalled(s.T(), "FindProductByID", mock.Anything, "00000000-0000-4000-8000-000000000123")
 
-	res, err := s.usecase.GetProductByID(context.Background(), "123")
+	res, err := s.usecase.GetProductByID(context.Background(), "00000000-0000-4000-8000-000000000123", product.VisibilityPublic)
 
 	s.NoError(err)
 	s.Equal(expectedProduct.Name, res.Name)
 	s.Equal(expectedProduct.Type, res.Type)
 	s.Equal(expectedProduct.Price, res.Price)
-
-	err = s.redisMock.ExpectationsWereMet()
-	s.NoError(err)
 }
 
 func (s *UsecaseProductTestSuite) TestCreateSuccess() {
 	s.mockRepo.On("FindProductByNameAndType", mock.Anything, "Banana", "Buah").Return(nil, nil)
+	s.mockRepo.On("FindSimilarProducts", mock.Anything, mock.Anything, "Buah", mock.Anything).Return(nil, nil)
 
 	s.mockRepo.On("SaveProduct", mock.Anything, mock.Anything).Return(nil)
 
@@ -78,7 +133,7 @@ func (s *UsecaseProductTestSuite) TestCreateSuccess() {
 		Price: 10000,
 	}
 
-	err := s.usecase.CreateProduct(context.Background(), newProduct)
+	err := s.usecase.CreateProduct(asRole("admin"), newProduct, product.CreateOptions{})
 	s.NoError(err)
 }
 
@@ -87,10 +142,7 @@ func (s *UsecaseProductTestSuite) TestListProductWithRedisEmptySuccess() {
 		{ID: "1", Name: "A", Type: "Buah", Price: 10000, CreatedAt: time.Now()},
 	}
 	filter := product.ListFilter{Page: 1, PageSize: 10}
-	cacheKey := "products:all:name=:type=:sort=:order=:page=1:size=10"
-
-	s.redisMock.ExpectGet(cacheKey).RedisNil()
-	s.redisMock.ExpectSet(cacheKey, mock.Anything, 5*time.Minute).SetVal("OK")
+	cacheKey := "tenant:default:products:all:name=:type=:sort=:order=:page=1:size=10:vis=public"
 
 	s.mockRepo.On("FindProduct", mock.Anything, mock.Anything).Return(products, 1, nil)
 
@@ -99,6 +151,7 @@ func (s *UsecaseProductTestSuite) TestListProductWithRedisEmptySuccess() {
 	s.NoError(err)
 	s.Equal(1, total)
 	s.Equal("A", res[0].Name)
+	s.True(s.cached(cacheKey))
 }
 
 func (s *UsecaseProductTestSuite) TestListProductWithRedisPresentSuccess() {
@@ -107,15 +160,15 @@ func (s *UsecaseProductTestSuite) TestListProductWithRedisPresentSuccess() {
 		{ID: "1", Name: "A", Type: "Buah", Price: 10000, CreatedAt: time.Now()},
 	}
 	filter := product.ListFilter{Page: 1, PageSize: 10}
-	cacheKey := "products:all:name=:type=:sort=:order=:page=1:size=10"
+	cacheKey := "tenant:default:products:all:name=:type=:sort=:order=:page=1:size=10:vis=public"
 
-	jsonData, _ := json.Marshal(products)
-	s.redisMock.ExpectGet(cacheKey).SetVal(string(jsonData))
+	jsonData, _ := json.Marshal(map[string]interface{}{"products": products, "total": 7})
+	s.put(cacheKey, jsonData)
 
 	res, total, err := s.usecase.ListProduct(context.Background(), filter)
 
 	s.NoError(err)
-	s.Equal(1, total)
+	s.Equal(7, total)
 	s.Len(res, 1)
 	s.Equal("A", res[0].Name)
 }
@@ -126,16 +179,12 @@ func (s *UsecaseProductTestSuite) TestListProductWithRedisError() {
 		{ID: "1", Name: "A", Type: "Buah", Price: 10000, CreatedAt: time.Now()},
 	}
 	filter := product.ListFilter{Page: 1, PageSize: 10}
-	cacheKey := fmt.Sprintf("products:all:name=%s:type=%s:sort=%s:order=%s:page=%d:size=%d",
-		filter.Query, filter.Type, filter.SortBy, filter.Order, filter.Page, filter.PageSize,
-	)
-
-	s.redisMock.ExpectGet(cacheKey).SetErr(errors.New("simulated redis connection error"))
-	s.redisMock.ExpectSet(cacheKey, mock.Anything, 5*time.Minute).SetVal("OK")
+	uc := usecase.NewUsecase(s.mockRepo, failingCache{errors.New("simulated redis connection error")}, testCodec(), nil, nil, logrus.New(),
+		&config.Config{CacheTTL: 5 * time.Minute}, policy.Default())
 
 	s.mockRepo.On("FindProduct", mock.Anything, mock.Anything).Return(products, 1, nil).Once()
 
-	res, total, err := s.usecase.ListProduct(context.Background(), filter)
+	res, total, err := uc.ListProduct(context.Background(), filter)
 
 	s.NoError(err)
 	s.Equal(1, total)
@@ -143,3 +192,696 @@ func (s *UsecaseProductTestSuite) TestListProductWithRedisError() {
 	s.Equal("A", res[0].Name)
 
 }
+
+func (s *UsecaseProductTestSuite) TestCreateDuplicateBarcode() {
+	existing := &product.Product{ID: "9", Name: "Milk", Type: "Protein", Price: 18000, Barcode: "4006381333931"}
+
+	s.mockRepo.On("FindProductByNameAndType", mock.Anything, "Fresh Milk", "Protein").Return(nil, nil)
+	s.mockRepo.On("FindSimilarProducts", mock.Anything, mock.Anything, "Protein", mock.Anything).Return(nil, nil)
+	s.mockRepo.On("FindProductByBarcode", mock.Anything, "4006381333931").Return(existing, nil)
+
+	newProduct := &product.Product{
+		Name:    "Fresh Milk",
+		Type:    "Protein",
+		Price:   20000,
+		Barcode: "400-6381-333931",
+	}
+
+	err := s.usecase.CreateProduct(asRole("admin"), newProduct, product.CreateOptions{})
+	s.ErrorIs(err, product.ErrDuplicate)
+	s.mockRepo.AssertNotCalled(s.T(), "SaveProduct", mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestGetByBarcodeNormalizesUPCA() {
+	expectedProduct := &product.Product{ID: "9", Name: "Cola", Type: "Snack", Price: 7000, Barcode: "0036000291452", Status: product.StatusActive}
+
+	s.mockRepo.On("FindProductByBarcode", mock.Anything, "0036000291452").Return(expectedProduct, nil)
+
+	res, err := s.usecase.GetProductByBarcode(context.Background(), "036000291452", product.VisibilityPublic)
+
+	s.NoError(err)
+	s.Equal("Cola", res.Name)
+	s.True(s.cached("tenant:default:products:barcode:0036000291452"))
+}
+
+func (s *UsecaseProductTestSuite) TestGetByBarcodeInvalidChecksum() {
+	_, err := s.usecase.GetProductByBarcode(context.Background(), "4006381333932", product.VisibilityPublic)
+
+	s.ErrorIs(err, product.ErrInvalidBarcode)
+}
+
+func (s *UsecaseProductTestSuite) TestGetByBarcodeNotFound() {
+	s.mockRepo.On("FindProductByBarcode", mock.Anything, "4006381333931").Return(nil, nil)
+
+	_, err := s.usecase.GetProductByBarcode(context.Background(), "4006381333931", product.VisibilityPublic)
+
+	s.ErrorIs(err, product.ErrNotFound)
+}
+
+func (s *UsecaseProductTestSuite) TestCreateBundleComputesPrice() {
+	tomato := &product.Product{ID: "00000000-0000-4000-8000-0000000000c1", Name: "Tomato", Type: "Sayuran", Price: 5000, Kind: product.KindSimple}
+	carrot := &product.Product{ID: "00000000-0000-4000-8000-0000000000c2", Name: "Carrot", Type: "Sayuran", Price: 3000, Kind: product.KindSimple}
+
+	s.mockRepo.On("FindProductByNameAndType", mock.Anything, "Sayur Sop", "Sayuran").Return(nil, nil)
+	s.mockRepo.On("FindSimilarProducts", mock.Anything, mock.Anything, "Sayuran", mock.Anything).Return(nil, nil)
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000c1").Return(tomato, nil)
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000c2").Return(carrot, nil)
+	s.mockRepo.On("SaveProduct", mock.Anything, mock.Anything).Return(nil)
+
+	bundle := &product.Product{
+		Name:       "Sayur Sop",
+		Type:       "Sayuran",
+		Kind:       product.KindBundle,
+		Discount:   1000,
+		Components: []product.Component{{ProductID: "00000000-0000-4000-8000-0000000000c1", Quantity: 2}, {ProductID: "00000000-0000-4000-8000-0000000000c2", Quantity: 1}},
+	}
+
+	err := s.usecase.CreateProduct(asRole("admin"), bundle, product.CreateOptions{})
+
+	s.NoError(err)
+	s.Equal(product.PricingComputed, bundle.Pricing)
+	s.Equal(float64(12000), bundle.Price)
+}
+
+func (s *UsecaseProductTestSuite) TestUpdateBundleRejectsCycle() {
+	inner := &product.Product{ID: "00000000-0000-4000-8000-0000000000b2", Name: "Inner", Type: "Sayuran", Price: 5000, Kind: product.KindBundle}
+	outer := &product.Product{ID: "00000000-0000-4000-8000-0000000000b1", Name: "Outer", Type: "Sayuran", Price: 5000, Kind: product.KindBundle, Pricing: product.PricingFixed}
+	price := 6000.0
+
+	s.mockRepo.On("FindProductByID", mock.Anything, outer.ID).Return(outer, nil)
+	s.mockRepo.On("FindProductByID", mock.Anything, inner.ID).Return(inner, nil)
+	s.mockRepo.On("FindComponents", mock.Anything, outer.ID).Return([]product.Component{{ProductID: inner.ID, Quantity: 1}}, nil)
+	s.mockRepo.On("FindComponents", mock.Anything, inner.ID).Return([]product.Component{{ProductID: outer.ID, Quantity: 1}}, nil)
+
+	_, err := s.usecase.UpdateProduct(asRole("pricing-manager"), outer.ID, product.Patch{Price: &price})
+
+	s.ErrorIs(err, product.ErrInvalidBundle)
+	s.mockRepo.AssertNotCalled(s.T(), "UpdateProduct", mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestCreateBundleRejectsInvalidComponentID() {
+	s.mockRepo.On("FindProductByNameAndType", mock.Anything, "Outer", "Sayuran").Return(nil, nil)
+	s.mockRepo.On("FindSimilarProducts", mock.Anything, mock.Anything, "Sayuran", mock.Anything).Return(nil, nil)
+
+	bundle := &product.Product{
+		Name:       "Outer",
+		Type:       "Sayuran",
+		Kind:       product.KindBundle,
+		Components: []product.Component{{ProductID: "tomato", Quantity: 1}},
+	}
+
+	err := s.usecase.CreateProduct(asRole("admin"), bundle, product.CreateOptions{})
+
+	s.ErrorIs(err, product.ErrInvalidID)
+	s.mockRepo.AssertNotCalled(s.T(), "SaveProduct", mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestCreateBundleRejectsRepeatedComponent() {
+	tomato := &product.Product{ID: "00000000-0000-4000-8000-0000000000c1", Name: "Tomato", Type: "Sayuran", Price: 5000, Kind: product.KindSimple}
+
+	s.mockRepo.On("FindProductByNameAndType", mock.Anything, "Tomato Pack", "Sayuran").Return(nil, nil)
+	s.mockRepo.On("FindSimilarProducts", mock.Anything, mock.Anything, "Sayuran", mock.Anything).Return(nil, nil)
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000c1").Return(tomato, nil)
+
+	bundle := &product.Product{
+		Name:       "Tomato Pack",
+		Type:       "Sayuran",
+		Kind:       product.KindBundle,
+		Components: []product.Component{{ProductID: "00000000-0000-4000-8000-0000000000c1", Quantity: 1}, {ProductID: "00000000-0000-4000-8000-0000000000c1", Quantity: 2}},
+	}
+
+	err := s.usecase.CreateProduct(asRole("admin"), bundle, product.CreateOptions{})
+
+	s.ErrorIs(err, product.ErrInvalidBundle)
+}
+
+func (s *UsecaseProductTestSuite) TestDeleteComponentInUse() {
+	tomato := &product.Product{ID: "00000000-0000-4000-8000-0000000000c1", Name: "Tomato", Type: "Sayuran", Price: 5000, Kind: product.KindSimple}
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000c1").Return(tomato, nil)
+	s.mockRepo.On("FindBundleIDsByComponent", mock.Anything, "00000000-0000-4000-8000-0000000000c1").Return([]string{"00000000-0000-4000-8000-0000000000b1"}, nil)
+
+	err := s.usecase.DeleteProduct(asRole("admin"), "00000000-0000-4000-8000-0000000000c1")
+
+	s.ErrorIs(err, product.ErrInUse)
+	s.mockRepo.AssertNotCalled(s.T(), "DeleteProduct", mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestDeleteRefusesTombstone() {
+	tombstone := &product.Product{ID: "00000000-0000-4000-8000-0000000000a1", Name: "Tomat", Type: "Sayuran", Status: product.StatusArchived, MergedInto: "00000000-0000-4000-8000-0000000000a2"}
+
+	s.mockRepo.On("FindProductByID", mock.Anything, tombstone.ID).Return(tombstone, nil)
+
+	err := s.usecase.DeleteProduct(asRole("admin"), tombstone.ID)
+
+	var mergedErr *product.MergedError
+	s.ErrorAs(err, &mergedErr)
+	s.mockRepo.AssertNotCalled(s.T(), "DeleteProduct", mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestGetByIDHidesDraftFromPublic() {
+	draft := &product.Product{ID: "00000000-0000-4000-8000-0000000000d1", Name: "Kale", Type: "Sayuran", Price: 9000, Status: product.StatusDraft}
+	data, _ := json.Marshal(draft)
+
+	s.put("tenant:default:products:id:00000000-0000-4000-8000-0000000000d1", data)
+
+	_, err := s.usecase.GetProductByID(context.Background(), "00000000-0000-4000-8000-0000000000d1", product.VisibilityPublic)
+	s.ErrorIs(err, product.ErrNotFound)
+}
+
+func (s *UsecaseProductTestSuite) TestChangeStatusSchedulesPublish() {
+	pending := &product.Product{ID: "00000000-0000-4000-8000-0000000000f1", Name: "Kale", Type: "Sayuran", Price: 9000, Status: product.StatusPendingReview}
+	publishAt := time.Now().Add(time.Hour)
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000f1").Return(pending, nil)
+	s.mockRepo.On("UpdateStatus", mock.Anything, "00000000-0000-4000-8000-0000000000f1", product.StatusPendingReview, product.StatusActive, &publishAt).Return(nil)
+	s.put("tenant:default:products:id:00000000-0000-4000-8000-0000000000f1", []byte(`{}`))
+	s.NoError(s.cache.Set(context.Background(), "tenant:default:products:all:page=1", []byte(`{}`), time.Minute, "tenant:default:products:lists"))
+
+	res, err := s.usecase.ChangeStatus(asRole("admin"), "00000000-0000-4000-8000-0000000000f1", product.StatusActive, &publishAt)
+
+	s.NoError(err)
+	s.Equal(product.StatusActive, res.Status)
+	s.False(res.IsPublished(time.Now()))
+	s.True(s.gone("tenant:default:products:id:00000000-0000-4000-8000-0000000000f1"))
+	s.True(s.gone("tenant:default:products:all:page=1"))
+}
+
+func (s *UsecaseProductTestSuite) TestChangeStatusRejectsInvalidTransition() {
+	draft := &product.Product{ID: "00000000-0000-4000-8000-0000000000d1", Name: "Kale", Type: "Sayuran", Price: 9000, Status: product.StatusDraft}
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000d1").Return(draft, nil)
+
+	_, err := s.usecase.ChangeStatus(asRole("admin"), "00000000-0000-4000-8000-0000000000d1", product.StatusActive, nil)
+
+	s.ErrorIs(err, product.ErrInvalidTransition)
+	s.mockRepo.AssertNotCalled(s.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestChangeStatusEditorCannotPublish() {
+	pending := &product.Product{ID: "00000000-0000-4000-8000-0000000000f1", Name: "Kale", Type: "Sayuran", Price: 9000, Status: product.StatusPendingReview}
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000f1").Return(pending, nil)
+
+	_, err := s.usecase.ChangeStatus(asRole("editor"), "00000000-0000-4000-8000-0000000000f1", product.StatusActive, nil)
+
+	s.ErrorIs(err, policy.ErrForbidden)
+	s.mockRepo.AssertNotCalled(s.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestCreateRejectsNearDuplicate() {
+	existing := []product.Product{
+		{ID: "1", Name: "Chicken Breast", Type: "Protein", Price: 25000},
+		{ID: "2", Name: "Beef Slice", Type: "Protein", Price: 40000},
+	}
+
+	s.mockRepo.On("FindProductByNameAndType", mock.Anything, "Chiken Breast ", "Protein").Return(nil, nil)
+	s.mockRepo.On("FindSimilarProducts", mock.Anything, mock.Anything, "Protein", mock.Anything).Return(existing, nil)
+
+	newProduct := &product.Product{Name: "Chiken Breast ", Type: "Protein", Price: 26000}
+
+	err := s.usecase.CreateProduct(asRole("admin"), newProduct, product.CreateOptions{})
+
+	var dupErr *product.DuplicateError
+	s.ErrorAs(err, &dupErr)
+	s.ErrorIs(err, product.ErrDuplicate)
+	s.Len(dupErr.Matches, 1)
+	s.Equal("1", dupErr.Matches[0].Product.ID)
+	s.mockRepo.AssertNotCalled(s.T(), "SaveProduct", mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestCreateForceSkipsNearDuplicateCheck() {
+	s.mockRepo.On("FindProductByNameAndType", mock.Anything, "Chiken Breast", "Protein").Return(nil, nil)
+	s.mockRepo.On("SaveProduct", mock.Anything, mock.Anything).Return(nil)
+
+	newProduct := &product.Product{Name: "Chiken Breast", Type: "Protein", Price: 26000}
+
+	err := s.usecase.CreateProduct(asRole("admin"), newProduct, product.CreateOptions{Force: true})
+
+	s.NoError(err)
+	s.mockRepo.AssertNotCalled(s.T(), "FindSimilarProducts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestGetByIDMergedReturnsRedirect() {
+	merged := &product.Product{ID: "00000000-0000-4000-8000-0000000000a1", Name: "Tomato", Type: "Sayuran", Price: 5000, Status: product.StatusArchived, MergedInto: "00000000-0000-4000-8000-0000000000a2"}
+	data, _ := json.Marshal(merged)
+
+	s.put("tenant:default:products:id:00000000-0000-4000-8000-0000000000a1", data)
+
+	_, err := s.usecase.GetProductByID(context.Background(), "00000000-0000-4000-8000-0000000000a1", product.VisibilityPublic)
+
+	var mergedErr *product.MergedError
+	s.ErrorAs(err, &mergedErr)
+	s.Equal("00000000-0000-4000-8000-0000000000a2", mergedErr.MergedInto)
+}
+
+func (s *UsecaseProductTestSuite) TestMergeProductsSuccess() {
+	target := &product.Product{ID: "00000000-0000-4000-8000-0000000000a2", Name: "Tomato", Type: "Sayuran", Price: 5000, Status: product.StatusActive}
+	source := &product.Product{ID: "00000000-0000-4000-8000-0000000000a1", Name: "Tomato", Type: "Sayuran", Price: 5000, Status: product.StatusActive}
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000a2").Return(target, nil)
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000a1").Return(source, nil)
+	s.mockRepo.On("FindComponents", mock.Anything, "00000000-0000-4000-8000-0000000000a2").Return(nil, nil)
+	s.mockRepo.On("MergeProducts", mock.Anything, "00000000-0000-4000-8000-0000000000a2", []string{"00000000-0000-4000-8000-0000000000a1"}).Return(nil)
+	s.put("tenant:default:products:id:00000000-0000-4000-8000-0000000000a1", []byte(`{}`))
+	s.put("tenant:default:products:id:00000000-0000-4000-8000-0000000000a2", []byte(`{}`))
+
+	res, err := s.usecase.MergeProducts(asRole("admin"), "00000000-0000-4000-8000-0000000000a2", []string{"00000000-0000-4000-8000-0000000000a1"})
+
+	s.NoError(err)
+	s.Equal("00000000-0000-4000-8000-0000000000a2", res.ID)
+	s.True(s.gone("tenant:default:products:id:00000000-0000-4000-8000-0000000000a1"))
+	s.True(s.gone("tenant:default:products:id:00000000-0000-4000-8000-0000000000a2"))
+}
+
+func (s *UsecaseProductTestSuite) TestMergeProductsRejectsTargetAsSource() {
+	target := &product.Product{ID: "00000000-0000-4000-8000-0000000000a2", Name: "Tomato", Type: "Sayuran", Price: 5000, Status: product.StatusActive}
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000a2").Return(target, nil)
+	s.mockRepo.On("FindComponents", mock.Anything, "00000000-0000-4000-8000-0000000000a2").Return(nil, nil)
+
+	_, err := s.usecase.MergeProducts(asRole("admin"), "00000000-0000-4000-8000-0000000000a2", []string{"00000000-0000-4000-8000-0000000000a2"})
+
+	s.ErrorIs(err, product.ErrInvalidMerge)
+	s.mockRepo.AssertNotCalled(s.T(), "MergeProducts", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestMergeProductsRejectsSourceNestedInTarget() {
+	target := &product.Product{ID: "00000000-0000-4000-8000-0000000000b1", Name: "Party Pack", Type: "Paket", Kind: product.KindBundle, Status: product.StatusActive}
+	source := &product.Product{ID: "00000000-0000-4000-8000-0000000000a1", Name: "Tomato", Type: "Sayuran", Price: 5000, Status: product.StatusActive}
+
+	// target > b2 > b3 > source: after the merge b3 would contain target.
+	s.mockRepo.On("FindProductByID", mock.Anything, target.ID).Return(target, nil)
+	s.mockRepo.On("FindProductByID", mock.Anything, source.ID).Return(source, nil)
+	s.mockRepo.On("FindComponents", mock.Anything, target.ID).Return([]product.Component{{ProductID: "00000000-0000-4000-8000-0000000000b2", Quantity: 1}}, nil)
+	s.mockRepo.On("FindComponents", mock.Anything, "00000000-0000-4000-8000-0000000000b2").Return([]product.Component{{ProductID: "00000000-0000-4000-8000-0000000000b3", Quantity: 1}}, nil)
+	s.mockRepo.On("FindComponents", mock.Anything, "00000000-0000-4000-8000-0000000000b3").Return([]product.Component{{ProductID: source.ID, Quantity: 2}}, nil)
+
+	_, err := s.usecase.MergeProducts(asRole("admin"), target.ID, []string{source.ID})
+
+	s.ErrorIs(err, product.ErrInvalidMerge)
+	s.ErrorContains(err, source.ID)
+	s.mockRepo.AssertNotCalled(s.T(), "MergeProducts", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestCreateRequiresRole() {
+	newProduct := &product.Product{Name: "Banana", Type: "Buah", Price: 10000}
+
+	err := s.usecase.CreateProduct(context.Background(), newProduct, product.CreateOptions{})
+	s.ErrorIs(err, policy.ErrForbidden)
+
+	err = s.usecase.CreateProduct(asRole("viewer"), newProduct, product.CreateOptions{})
+	s.ErrorIs(err, policy.ErrForbidden)
+	s.mockRepo.AssertNotCalled(s.T(), "SaveProduct", mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestUpdatePriceRequiresPricingManager() {
+	existing := &product.Product{ID: "00000000-0000-4000-8000-0000000000f1", Name: "Kale", Type: "Sayuran", Price: 9000, Kind: product.KindSimple, Status: product.StatusActive}
+	price := 9500.0
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000f1").Return(existing, nil)
+
+	_, err := s.usecase.UpdateProduct(asRole("editor"), "00000000-0000-4000-8000-0000000000f1", product.Patch{Price: &price})
+	s.ErrorIs(err, policy.ErrForbidden)
+	s.mockRepo.AssertNotCalled(s.T(), "UpdateProduct", mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestUpdatePriceAsPricingManager() {
+	existing := &product.Product{ID: "00000000-0000-4000-8000-0000000000f1", Name: "Kale", Type: "Sayuran", Price: 9000, Kind: product.KindSimple, Status: product.StatusActive}
+	price := 9500.0
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000f1").Return(existing, nil)
+	s.mockRepo.On("UpdateProduct", mock.Anything, mock.MatchedBy(func(p *product.Product) bool {
+		return p.ID == "00000000-0000-4000-8000-0000000000f1" && p.Price == 9500 && p.Name == "Kale"
+	})).Return(nil, nil)
+	s.put("tenant:default:products:id:00000000-0000-4000-8000-0000000000f1", []byte(`{}`))
+
+	res, err := s.usecase.UpdateProduct(asRole("pricing-manager"), "00000000-0000-4000-8000-0000000000f1", product.Patch{Price: &price})
+
+	s.NoError(err)
+	s.Equal(9500.0, res.Price)
+	s.True(s.gone("tenant:default:products:id:00000000-0000-4000-8000-0000000000f1"))
+}
+
+func (s *UsecaseProductTestSuite) TestUpdatePriceEvictsRepricedBundles() {
+	existing := &product.Product{ID: "00000000-0000-4000-8000-0000000000f1", Name: "Kale", Type: "Sayuran", Price: 9000, Kind: product.KindSimple, Status: product.StatusActive}
+	bundle := product.Product{ID: "00000000-0000-4000-8000-0000000000b1", Name: "Salad", Kind: product.KindBundle, Pricing: product.PricingComputed, Price: 19000, Barcode: "4006381333931"}
+	price := 9500.0
+
+	s.mockRepo.On("FindProductByID", mock.Anything, existing.ID).Return(existing, nil)
+	s.mockRepo.On("UpdateProduct", mock.Anything, mock.Anything).Return([]product.Product{bundle}, nil)
+	s.put("tenant:default:products:id:00000000-0000-4000-8000-0000000000b1", []byte(`{}`))
+	s.put("tenant:default:products:barcode:4006381333931", []byte(`{}`))
+
+	_, err := s.usecase.UpdateProduct(asRole("pricing-manager"), existing.ID, product.Patch{Price: &price})
+
+	s.NoError(err)
+	s.True(s.gone("tenant:default:products:id:00000000-0000-4000-8000-0000000000b1"))
+	s.True(s.gone("tenant:default:products:barcode:4006381333931"))
+}
+
+func (s *UsecaseProductTestSuite) TestUpdateNameRejectsDuplicate() {
+	existing := &product.Product{ID: "00000000-0000-4000-8000-0000000000f1", Name: "Kale", Type: "Sayuran", Price: 9000, Status: product.StatusDraft}
+	other := &product.Product{ID: "00000000-0000-4000-8000-0000000000f2", Name: "Spinach", Type: "Sayuran"}
+	name := "Spinach"
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-0000000000f1").Return(existing, nil)
+	s.mockRepo.On("FindProductByNameAndType", mock.Anything, "Spinach", "Sayuran").Return(other, nil)
+
+	_, err := s.usecase.UpdateProduct(asRole("editor"), "00000000-0000-4000-8000-0000000000f1", product.Patch{Name: &name})
+
+	s.ErrorIs(err, product.ErrDuplicate)
+	s.mockRepo.AssertNotCalled(s.T(), "UpdateProduct", mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestGetByIDUsesTenantCache() {
+	ctx := tenant.WithID(context.Background(), "store-1")
+	expected := &product.Product{ID: "00000000-0000-4000-8000-000000000123", Name: "Sawi", Type: "Sayuran", Price: 5000, Status: product.StatusActive}
+	data, _ := json.Marshal(expected)
+
+	s.put("tenant:store-1:products:id:00000000-0000-4000-8000-000000000123", data)
+
+	res, err := s.usecase.GetProductByID(ctx, "00000000-0000-4000-8000-000000000123", product.VisibilityPublic)
+
+	s.NoError(err)
+	s.Equal("Sawi", res.Name)
+}
+
+func (s *UsecaseProductTestSuite) TestCacheWriteOutlivesRequest() {
+	m := miniredis.RunT(s.T())
+	uc := usecase.NewUsecase(s.mockRepo, cache.NewRedis(redis.NewClient(&redis.Options{Addr: m.Addr()})), testCodec(), nil, nil, logrus.New(),
+		&config.Config{CacheWriteTimeout: time.Second}, policy.Default())
+	expected := &product.Product{ID: "00000000-0000-4000-8000-000000000123", Name: "Sawi", Type: "Sayuran", Price: 5000, Status: product.StatusActive}
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-000000000123").Return(expected, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := uc.GetProductByID(ctx, "00000000-0000-4000-8000-000000000123", product.VisibilityPublic)
+	cancel()
+
+	s.NoError(err)
+	s.Eventually(func() bool {
+		return m.Exists("tenant:default:products:id:00000000-0000-4000-8000-000000000123")
+	}, time.Second, 10*time.Millisecond)
+}
+
+// slowCache holds every Set until release is closed.
+type slowCache struct {
+	*cache.Memory
+	release chan struct{}
+}
+
+func (c slowCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	<-c.release
+	return c.Memory.Set(ctx, key, value, ttl, tags...)
+}
+
+func (s *UsecaseProductTestSuite) TestDrainWaitsForCacheWrites() {
+	c := slowCache{Memory: cache.NewMemory(10), release: make(chan struct{})}
+	uc := usecase.NewUsecase(s.mockRepo, c, testCodec(), nil, nil, logrus.New(),
+		&config.Config{CacheTTL: time.Minute, CacheWriteTimeout: time.Second}, policy.Default())
+	expected := &product.Product{ID: "00000000-0000-4000-8000-000000000123", Name: "Sawi", Type: "Sayuran", Price: 5000, Status: product.StatusActive}
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-000000000123").Return(expected, nil)
+
+	_, err := uc.GetProductByID(context.Background(), "00000000-0000-4000-8000-000000000123", product.VisibilityPublic)
+	s.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	s.ErrorIs(uc.Drain(ctx), context.DeadlineExceeded)
+
+	close(c.release)
+	s.NoError(uc.Drain(context.Background()))
+	_, err = c.Get(context.Background(), "tenant:default:products:id:00000000-0000-4000-8000-000000000123")
+	s.NoError(err, "the write finished before Drain returned")
+}
+
+func (s *UsecaseProductTestSuite) TestGetByIDBypassesOpenCache() {
+	expected := &product.Product{ID: "00000000-0000-4000-8000-000000000123", Name: "Sawi", Type: "Sayuran", Price: 5000, Status: product.StatusActive}
+
+	uc := usecase.NewUsecase(s.mockRepo, failingCache{breaker.ErrOpen}, testCodec(), nil, nil, logrus.New(), &config.Config{}, policy.Default())
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-000000000123").Return(expected, nil)
+
+	res, err := uc.GetProductByID(context.Background(), "00000000-0000-4000-8000-000000000123", product.VisibilityPublic)
+
+	s.NoError(err)
+	s.Equal("Sawi", res.Name)
+}
+
+func (s *UsecaseProductTestSuite) newCachedUsecase(cfg *config.Config) (*usecase.Usecase, *miniredis.Miniredis) {
+	m := miniredis.RunT(s.T())
+	cfg.CacheTTL = time.Minute
+	cfg.CacheWriteTimeout = time.Second
+	return usecase.NewUsecase(s.mockRepo, cache.NewRedis(redis.NewClient(&redis.Options{Addr: m.Addr()})), testCodec(), nil, nil, logrus.New(), cfg, policy.Default()), m
+}
+
+func (s *UsecaseProductTestSuite) TestConcurrentMissesShareOneLoad() {
+	uc, _ := s.newCachedUsecase(&config.Config{})
+	expected := &product.Product{ID: "00000000-0000-4000-8000-000000000123", Name: "Sawi", Type: "Sayuran", Status: product.StatusActive}
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-000000000123").After(50*time.Millisecond).Return(expected, nil).Once()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := uc.GetProductByID(context.Background(), "00000000-0000-4000-8000-000000000123", product.VisibilityPublic)
+			s.NoError(err)
+			s.Equal("Sawi", res.Name)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *UsecaseProductTestSuite) TestSharedLoadWaitHonoursContext() {
+	uc, _ := s.newCachedUsecase(&config.Config{})
+	expected := &product.Product{ID: "00000000-0000-4000-8000-000000000123", Name: "Sawi", Type: "Sayuran", Status: product.StatusActive}
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-000000000123").After(300*time.Millisecond).Return(expected, nil).Once()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := uc.GetProductByID(ctx, "00000000-0000-4000-8000-000000000123", product.VisibilityPublic)
+
+	s.ErrorIs(err, context.DeadlineExceeded)
+	s.Less(time.Since(start), 200*time.Millisecond)
+
+	// The load carries on for other callers and fills the cache.
+	res, err := uc.GetProductByID(context.Background(), "00000000-0000-4000-8000-000000000123", product.VisibilityPublic)
+	s.NoError(err)
+	s.Equal("Sawi", res.Name)
+}
+
+func (s *UsecaseProductTestSuite) TestServesStaleWhileRevalidating() {
+	uc, m := s.newCachedUsecase(&config.Config{CacheStaleTTL: time.Minute})
+	key := "tenant:default:products:id:00000000-0000-4000-8000-000000000123"
+	m.Set(key, `{"value":{"id":"00000000-0000-4000-8000-000000000123","name":"Old","status":"active"},"fresh_until":1}`)
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-000000000123").
+		Return(&product.Product{ID: "00000000-0000-4000-8000-000000000123", Name: "New", Status: product.StatusActive}, nil).Once()
+
+	res, err := uc.GetProductByID(context.Background(), "00000000-0000-4000-8000-000000000123", product.VisibilityPublic)
+
+	s.NoError(err)
+	s.Equal("Old", res.Name)
+	s.Eventually(func() bool {
+		v, _ := m.Get(key)
+		return strings.Contains(v, "New")
+	}, time.Second, 10*time.Millisecond)
+	s.Greater(m.TTL(key), time.Minute, "entries are kept for the stale window too")
+}
+
+func (s *UsecaseProductTestSuite) TestWaitsForFillLockHolder() {
+	uc, m := s.newCachedUsecase(&config.Config{CacheLockTTL: time.Second})
+	key := "tenant:default:products:id:00000000-0000-4000-8000-000000000123"
+	m.Set("lock:"+key, "other-instance")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		m.Set(key, `{"value":{"id":"00000000-0000-4000-8000-000000000123","name":"Sawi","status":"active"},"fresh_until":0}`)
+	}()
+
+	res, err := uc.GetProductByID(context.Background(), "00000000-0000-4000-8000-000000000123", product.VisibilityPublic)
+
+	s.NoError(err)
+	s.Equal("Sawi", res.Name)
+	s.mockRepo.AssertNotCalled(s.T(), "FindProductByID", mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestGetByIDRejectsMalformedID() {
+	_, err := s.usecase.GetProductByID(context.Background(), "1 OR 1=1", product.VisibilityPublic)
+
+	s.ErrorIs(err, product.ErrInvalidID)
+	s.mockRepo.AssertNotCalled(s.T(), "FindProductByID", mock.Anything, mock.Anything)
+}
+
+func (s *UsecaseProductTestSuite) TestGetByIDCachesMissing() {
+	uc := usecase.NewUsecase(s.mockRepo, s.cache, testCodec(), nil, nil, logrus.New(),
+		&config.Config{CacheTTL: time.Minute, CacheNegativeTTL: time.Minute}, policy.Default())
+	id := "00000000-0000-4000-8000-0000000000e1"
+
+	s.mockRepo.On("FindProductByID", mock.Anything, id).Return(nil, product.ErrNotFound).Once()
+
+	_, err := uc.GetProductByID(context.Background(), id, product.VisibilityPublic)
+	s.ErrorIs(err, product.ErrNotFound)
+	s.True(s.cached("tenant:default:products:id:" + id))
+
+	_, err = uc.GetProductByID(context.Background(), id, product.VisibilityPublic)
+	s.ErrorIs(err, product.ErrNotFound)
+}
+
+func (s *UsecaseProductTestSuite) TestTracedRecordsSpanPerCall() {
+	spans := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	defer otel.SetTracerProvider(prev)
+
+	_, err := usecase.NewTraced(s.usecase).GetProductByID(context.Background(), "1 OR 1=1", product.VisibilityPublic)
+
+	s.ErrorIs(err, product.ErrInvalidID)
+	s.Require().Len(spans.Ended(), 1)
+	span := spans.Ended()[0]
+	s.Equal("ProductUsecase.GetProductByID", span.Name())
+	s.Equal(codes.Error, span.Status().Code)
+}
+
+func (s *UsecaseProductTestSuite) TestGetByIDReloadsOtherSchemaVersions() {
+	key := "tenant:default:products:id:00000000-0000-4000-8000-000000000123"
+	old, _ := cache.NewCodec(cache.FormatMsgpack, 0, 0)
+	data, err := old.Marshal(map[string]any{"value": map[string]any{"id": 123, "name": "Old"}})
+	s.Require().NoError(err)
+	s.put(key, data)
+
+	s.mockRepo.On("FindProductByID", mock.Anything, "00000000-0000-4000-8000-000000000123").
+		Return(&product.Product{ID: "00000000-0000-4000-8000-000000000123", Name: "Sawi", Status: product.StatusActive}, nil).Once()
+
+	res, err := s.usecase.GetProductByID(context.Background(), "00000000-0000-4000-8000-000000000123", product.VisibilityPublic)
+
+	s.NoError(err)
+	s.Equal("Sawi", res.Name)
+	s.Eventually(func() bool {
+		v, err := s.cache.Get(context.Background(), key)
+		return err == nil && testCodec().Unmarshal(v, new(any)) == nil
+	}, time.Second, 5*time.Millisecond, "the value is rewritten under the current version")
+}
+
+func (s *UsecaseProductTestSuite) TestCreateDropsCachedMissingBarcode() {
+	key := "tenant:default:products:barcode:4006381333931"
+	s.put(key, []byte(`{"missing":true}`))
+
+	s.mockRepo.On("FindProductByNameAndType", mock.Anything, "Fresh Milk", "Protein").Return(nil, nil)
+	s.mockRepo.On("FindSimilarProducts", mock.Anything, mock.Anything, "Protein", mock.Anything).Return(nil, nil)
+	s.mockRepo.On("FindProductByBarcode", mock.Anything, "4006381333931").Return(nil, nil)
+	s.mockRepo.On("SaveProduct", mock.Anything, mock.Anything).Return(nil)
+
+	err := s.usecase.CreateProduct(asRole("admin"), &product.Product{Name: "Fresh Milk", Type: "Protein", Price: 20000, Barcode: "4006381333931"}, product.CreateOptions{})
+
+	s.NoError(err)
+	s.True(s.gone(key))
+}
+
+func (s *UsecaseProductTestSuite) TestFlushCacheStaysInTenant() {
+	s.put("tenant:default:products:all:page=1", []byte(`{}`))
+	s.put("tenant:default:products:id:00000000-0000-4000-8000-000000000123", []byte(`{}`))
+	s.put("tenant:store-1:products:all:page=1", []byte(`{}`))
+
+	n, err := s.usecase.FlushCache(context.Background(), "products:all:*")
+
+	s.NoError(err)
+	s.Equal(1, n)
+	s.True(s.gone("tenant:default:products:all:page=1"))
+	s.False(s.gone("tenant:default:products:id:00000000-0000-4000-8000-000000000123"))
+	s.False(s.gone("tenant:store-1:products:all:page=1"))
+}
+
+func (s *UsecaseProductTestSuite) TestCacheKeysRejectsPatternOutsideProducts() {
+	_, err := s.usecase.CacheKeys(context.Background(), "*", 10)
+
+	s.ErrorIs(err, product.ErrInvalidPattern)
+}
+
+func (s *UsecaseProductTestSuite) TestCacheKeysTrimsTenant() {
+	s.put("tenant:default:products:all:page=1", []byte(`{}`))
+
+	keys, err := s.usecase.CacheKeys(context.Background(), "products:*", 10)
+
+	s.NoError(err)
+	s.Require().Len(keys, 1)
+	s.Equal("products:all:page=1", keys[0].Key)
+	s.InDelta(60, keys[0].TTLSeconds, 1)
+}
+
+func (s *UsecaseProductTestSuite) TestCacheStatsCountsHitsAndMisses() {
+	id := "00000000-0000-4000-8000-000000000123"
+	s.mockRepo.On("FindProductByID", mock.Anything, id).Return(&product.Product{ID: id, Name: "Sawi", Status: product.StatusActive}, nil).Once()
+
+	_, err := s.usecase.GetProductByID(context.Background(), id, product.VisibilityPublic)
+	s.NoError(err)
+	s.True(s.cached("tenant:default:products:id:" + id))
+	_, err = s.usecase.GetProductByID(context.Background(), id, product.VisibilityPublic)
+	s.NoError(err)
+
+	s.Equal(product.CacheStats{Hits: 1, Misses: 1, HitRatio: 0.5}, s.usecase.CacheStats()["products:id"])
+}
+
+func (s *UsecaseProductTestSuite) TestWarmerRefillsPopularProducts() {
+	m := miniredis.RunT(s.T())
+	rdb := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	cfg := &config.Config{CacheTTL: time.Minute, CacheWriteTimeout: time.Second, CacheWarmInterval: time.Hour, CacheWarmIDs: 10, CacheWarmLists: 10}
+	popular := usecase.NewPopularity(cfg, rdb, logrus.New())
+	uc := usecase.NewUsecase(s.mockRepo, cache.NewRedis(rdb), testCodec(), nil, popular, logrus.New(), cfg, policy.Default())
+	id := "00000000-0000-4000-8000-000000000123"
+	key := "tenant:default:products:id:" + id
+
+	s.mockRepo.On("FindProductByID", mock.Anything, id).Return(&product.Product{ID: id, Name: "Sawi", Status: product.StatusActive}, nil).Twice()
+
+	_, err := uc.GetProductByID(context.Background(), id, product.VisibilityPublic)
+	s.NoError(err)
+	s.Eventually(func() bool { return m.Exists(key) }, time.Second, 10*time.Millisecond)
+	popular.Close()
+	m.Del(key)
+
+	w := usecase.NewWarmer(uc)
+	defer w.Close()
+
+	s.Eventually(func() bool { return m.Exists(key) }, time.Second, 10*time.Millisecond)
+}
+
+func (s *UsecaseProductTestSuite) TestPopularityCountsBrowsingNotSearches() {
+	m := miniredis.RunT(s.T())
+	rdb := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	cfg := &config.Config{CacheTTL: time.Minute, CacheWriteTimeout: time.Second, CacheWarmInterval: time.Hour, CacheWarmIDs: 1, CacheWarmLists: 1}
+	popular := usecase.NewPopularity(cfg, rdb, logrus.New())
+	uc := usecase.NewUsecase(s.mockRepo, cache.NewRedis(rdb), testCodec(), nil, popular, logrus.New(), cfg, policy.Default())
+
+	s.mockRepo.On("FindProduct", mock.Anything, mock.Anything).Return(nil, 0, nil)
+
+	for page := 1; page <= 20; page++ {
+		_, _, err := uc.ListProduct(context.Background(), product.ListFilter{Page: page, PageSize: 10})
+		s.NoError(err)
+		_, _, err = uc.ListProduct(context.Background(), product.ListFilter{Query: "search" + strings.Repeat("x", page), Page: 1, PageSize: 10})
+		s.NoError(err)
+	}
+	popular.Close()
+
+	members, err := m.ZMembers("stats:products:lists")
+	s.NoError(err)
+	s.Len(members, 10)
+	for _, member := range members {
+		s.NotContains(member, "search")
+	}
+}
+
+func (s *UsecaseProductTestSuite) TestUpdatePriceRejectsZeroForSimpleProduct() {
+	existing := &product.Product{ID: "00000000-0000-4000-8000-0000000000f1", Name: "Kale", Type: "Sayuran", Price: 9000, Kind: product.KindSimple, Status: product.StatusActive}
+	price := 0.0
+
+	s.mockRepo.On("FindProductByID", mock.Anything, existing.ID).Return(existing, nil)
+
+	_, err := s.usecase.UpdateProduct(asRole("pricing-manager"), existing.ID, product.Patch{Price: &price})
+
+	s.ErrorIs(err, product.ErrInvalidPrice)
+	s.mockRepo.AssertNotCalled(s.T(), "UpdateProduct", mock.Anything, mock.Anything)
+}
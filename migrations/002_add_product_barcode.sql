@@ -0,0 +1,3 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS barcode TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_barcode_key ON products (barcode) WHERE barcode IS NOT NULL;
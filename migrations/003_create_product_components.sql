@@ -0,0 +1,13 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'simple' CHECK (kind IN ('simple', 'bundle'));
+ALTER TABLE products ADD COLUMN IF NOT EXISTS pricing TEXT CHECK (pricing IN ('computed', 'fixed'));
+ALTER TABLE products ADD COLUMN IF NOT EXISTS discount NUMERIC NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS product_components (
+    bundle_id UUID NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    component_id UUID NOT NULL REFERENCES products (id) ON DELETE RESTRICT,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (bundle_id, component_id),
+    CHECK (bundle_id <> component_id)
+);
+
+CREATE INDEX IF NOT EXISTS product_components_component_id_idx ON product_components (component_id);
@@ -0,0 +1,7 @@
+-- Existing rows stay live; products created from now on start as drafts.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active'
+    CHECK (status IN ('draft', 'pending_review', 'active', 'archived'));
+ALTER TABLE products ALTER COLUMN status SET DEFAULT 'draft';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS products_status_idx ON products (status);
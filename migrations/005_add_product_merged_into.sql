@@ -0,0 +1,4 @@
+-- Tombstoned duplicates point at the product they were merged into.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS merged_into UUID REFERENCES products (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS products_merged_into_idx ON products (merged_into);
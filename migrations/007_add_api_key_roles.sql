@@ -0,0 +1,2 @@
+-- Roles feed the product access policy, just like the roles claim of a JWT.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,55 @@
+-- Every catalog row belongs to a tenant (store). Existing rows move to the
+-- default tenant; new rows must name theirs explicitly.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE product_components ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE products ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE product_components ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- Earlier versions of the seeder added the sample catalog again on every
+-- start, so a database can hold several live products with the same name
+-- and type, give or take case. Fold each group into its oldest product,
+-- the way MergeProducts does, so that the unique index below can be built.
+CREATE TEMP TABLE product_duplicates ON COMMIT DROP AS
+SELECT id, survivor, barcode FROM (
+    SELECT id, barcode,
+           FIRST_VALUE(id) OVER (PARTITION BY tenant_id, LOWER(name), LOWER(type) ORDER BY created_at, id) AS survivor
+    FROM products
+    WHERE merged_into IS NULL
+) ranked
+WHERE id <> survivor;
+
+INSERT INTO product_components (bundle_id, component_id, quantity, tenant_id)
+SELECT pc.bundle_id, d.survivor, SUM(pc.quantity), pc.tenant_id
+FROM product_components pc JOIN product_duplicates d ON pc.component_id = d.id
+WHERE pc.bundle_id <> d.survivor
+GROUP BY pc.bundle_id, d.survivor, pc.tenant_id
+ON CONFLICT (bundle_id, component_id) DO UPDATE SET quantity = product_components.quantity + EXCLUDED.quantity;
+
+DELETE FROM product_components pc USING product_duplicates d WHERE pc.component_id = d.id;
+
+UPDATE products p SET merged_into = d.survivor FROM product_duplicates d WHERE p.merged_into = d.id;
+
+UPDATE products p SET merged_into = d.survivor, status = 'archived', barcode = NULL
+FROM product_duplicates d WHERE p.id = d.id;
+
+-- A survivor without a barcode takes the first one of its duplicates.
+UPDATE products p SET barcode = carried.barcode
+FROM (
+    SELECT DISTINCT ON (d.survivor) d.survivor, d.barcode
+    FROM product_duplicates d JOIN products dup ON dup.id = d.id
+    WHERE d.barcode IS NOT NULL
+    ORDER BY d.survivor, dup.created_at, dup.id
+) carried
+WHERE p.id = carried.survivor AND p.barcode IS NULL;
+
+-- Barcodes and name+type pairs only need to be unique within a tenant.
+DROP INDEX IF EXISTS products_barcode_key;
+CREATE UNIQUE INDEX IF NOT EXISTS products_tenant_barcode_key ON products (tenant_id, barcode) WHERE barcode IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS products_tenant_name_type_key ON products (tenant_id, LOWER(name), LOWER(type)) WHERE merged_into IS NULL;
+
+CREATE INDEX IF NOT EXISTS products_tenant_id_idx ON products (tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS product_components_tenant_id_idx ON product_components (tenant_id);
+CREATE INDEX IF NOT EXISTS api_keys_tenant_id_idx ON api_keys (tenant_id);
@@ -0,0 +1,6 @@
+-- Deleting a merge target used to cascade to every product merged into it,
+-- removing their tombstones and breaking the redirects from old IDs. Keep
+-- targets from being deleted while tombstones point at them instead.
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_merged_into_fkey;
+ALTER TABLE products ADD CONSTRAINT products_merged_into_fkey
+    FOREIGN KEY (merged_into) REFERENCES products (id) ON DELETE RESTRICT;
@@ -0,0 +1,15 @@
+-- search_name is the product name as fuzzy.Normalize writes it: lower case,
+-- without diacritics and with single spaces. Duplicate checks compare it
+-- exactly, and near-duplicate checks look up candidates with its trigram
+-- index instead of reading every product of a type. The service sets it on
+-- every write; existing rows get the closest SQL equivalent.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_name TEXT;
+UPDATE products SET search_name = LOWER(REGEXP_REPLACE(BTRIM(unaccent(name)), '\s+', ' ', 'g'))
+WHERE search_name IS NULL;
+ALTER TABLE products ALTER COLUMN search_name SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS products_search_name_idx ON products (tenant_id, search_name);
+CREATE INDEX IF NOT EXISTS products_search_name_trgm_idx ON products USING gin (search_name gin_trgm_ops);
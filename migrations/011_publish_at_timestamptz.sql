@@ -0,0 +1,6 @@
+-- publish_at was a TIMESTAMP, which drops the offset of the value written to
+-- it, so a publish time sent as +07:00 was stored seven hours late. Store
+-- instants instead. Existing values are taken to be UTC, which is how the
+-- service writes them from now on.
+ALTER TABLE products ALTER COLUMN publish_at TYPE TIMESTAMPTZ
+    USING publish_at AT TIME ZONE 'UTC';
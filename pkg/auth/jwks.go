@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minRefetchInterval stops tokens with unknown key IDs from forcing a
+// JWKS download on every request.
+const minRefetchInterval = 30 * time.Second
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS caches the RSA keys of a JSON Web Key Set read from a file or URL.
+// Keys are reloaded once the refresh interval has passed, or sooner when a
+// token names a key ID that is not in the cache, so that key rotation is
+// picked up without a restart.
+type JWKS struct {
+	source  string
+	refresh time.Duration
+	client  *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+func NewJWKS(source string, refresh time.Duration) (*JWKS, error) {
+	j := &JWKS{
+		source:  source,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+	if err := j.reload(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *JWKS) Key(kid string) (*rsa.PublicKey, error) {
+	j.mu.RLock()
+	key, ok := j.keys[kid]
+	fresh := time.Since(j.fetchedAt) < j.refresh
+	recent := time.Since(j.lastAttempt) < minRefetchInterval
+	j.mu.RUnlock()
+
+	if ok && (fresh || recent) {
+		return key, nil
+	}
+	if recent {
+		return nil, ErrUnknownKey
+	}
+
+	if err := j.reload(); err != nil {
+		if ok {
+			// keep serving the last known key while the source is unavailable
+			return key, nil
+		}
+		return nil, err
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if key, ok := j.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+func (j *JWKS) reload() error {
+	j.mu.Lock()
+	j.lastAttempt = time.Now()
+	j.mu.Unlock()
+
+	raw, err := j.fetch()
+	if err != nil {
+		return fmt.Errorf("load jwks from %s: %w", j.source, err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return fmt.Errorf("parse jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		pub, err := k.rsaKey()
+		if err != nil {
+			return fmt.Errorf("parse jwk %s: %w", k.Kid, err)
+		}
+		keys[k.Kid] = pub
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.mu.Unlock()
+	return nil
+}
+
+func (j *JWKS) fetch() ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+
+	resp, err := j.client.Get(j.source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func (k jwk) rsaKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"simple-product-api/pkg/config"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrNoKeys       = errors.New("no token signing keys configured")
+)
+
+// Claims are the JWT claims the API understands. Scopes may be given as an
+// OAuth style space separated "scope" string or as a "scopes" array; roles
+// feed the policy engine.
+type Claims struct {
+	jwt.RegisteredClaims
+	TenantID string   `json:"tenant_id,omitempty"`
+	Scope    string   `json:"scope,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	Plan     string   `json:"plan,omitempty"`
+}
+
+// Verifier checks HS256 tokens against a shared secret and RS256 tokens
+// against a JWKS, depending on which of the two is configured.
+type Verifier struct {
+	secret  []byte
+	jwks    *JWKS
+	options []jwt.ParserOption
+}
+
+func NewVerifier(cfg *config.Config) (*Verifier, error) {
+	v := &Verifier{}
+	var methods []string
+
+	if cfg.JWTSecret != "" {
+		v.secret = []byte(cfg.JWTSecret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	source := cfg.JWKSURL
+	if source == "" {
+		source = cfg.JWKSFile
+	}
+	if source != "" {
+		jwks, err := NewJWKS(source, cfg.JWKSRefreshInterval)
+		if err != nil {
+			return nil, err
+		}
+		v.jwks = jwks
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	v.options = []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if cfg.JWTIssuer != "" {
+		v.options = append(v.options, jwt.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		v.options = append(v.options, jwt.WithAudience(cfg.JWTAudience))
+	}
+	return v, nil
+}
+
+// Enabled reports whether any signing key is configured. Without one every
+// token is rejected.
+func (v *Verifier) Enabled() bool {
+	return v.secret != nil || v.jwks != nil
+}
+
+func (v *Verifier) Verify(raw string) (*Principal, error) {
+	if !v.Enabled() {
+		return nil, ErrNoKeys
+	}
+
+	claims := &Claims{}
+	if _, err := jwt.ParseWithClaims(raw, claims, v.key, v.options...); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	scopes := append(strings.Fields(claims.Scope), claims.Scopes...)
+	return &Principal{
+		Subject:  claims.Subject,
+		TenantID: claims.TenantID,
+		Scopes:   scopes,
+		Roles:    claims.Roles,
+		Plan:     claims.Plan,
+	}, nil
+}
+
+func (v *Verifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		return v.jwks.Key(kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
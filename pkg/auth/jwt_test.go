@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-product-api/pkg/config"
+)
+
+func claims(exp time.Time) Claims {
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-1",
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+		TenantID: "acme",
+		Scope:    "products:write products:publish",
+		Scopes:   []string{ScopeProductsAdmin},
+	}
+}
+
+func signHS256(t *testing.T, secret string, c Claims) string {
+	raw, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return raw
+}
+
+func TestVerifyHS256(t *testing.T) {
+	v, err := NewVerifier(&config.Config{JWTSecret: "secret"})
+	require.NoError(t, err)
+
+	p, err := v.Verify(signHS256(t, "secret", claims(time.Now().Add(time.Hour))))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", p.Subject)
+	assert.Equal(t, "acme", p.TenantID)
+	assert.True(t, p.HasScope(ScopeProductsWrite))
+	assert.True(t, p.HasScope(ScopeProductsPublish))
+	assert.True(t, p.HasScope(ScopeProductsAdmin))
+	assert.False(t, p.HasScope(ScopeProductsReadUnpublished))
+}
+
+func TestVerifyRejectsBadTokens(t *testing.T) {
+	v, err := NewVerifier(&config.Config{JWTSecret: "secret", JWTIssuer: "https://issuer"})
+	require.NoError(t, err)
+
+	expired := claims(time.Now().Add(-time.Minute))
+	expired.Issuer = "https://issuer"
+	_, err = v.Verify(signHS256(t, "secret", expired))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	wrongSecret := claims(time.Now().Add(time.Hour))
+	wrongSecret.Issuer = "https://issuer"
+	_, err = v.Verify(signHS256(t, "other", wrongSecret))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = v.Verify(signHS256(t, "secret", claims(time.Now().Add(time.Hour))))
+	assert.ErrorIs(t, err, ErrInvalidToken, "issuer is required when configured")
+}
+
+func TestVerifyWithoutKeys(t *testing.T) {
+	v, err := NewVerifier(&config.Config{})
+	require.NoError(t, err)
+	assert.False(t, v.Enabled())
+
+	_, err = v.Verify(signHS256(t, "secret", claims(time.Now().Add(time.Hour))))
+	assert.ErrorIs(t, err, ErrNoKeys)
+}
+
+func writeJWKS(t *testing.T, path string, keys map[string]*rsa.PublicKey) {
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	for kid, k := range keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		})
+	}
+	body, err := json.Marshal(set)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, body, 0o600))
+}
+
+func signRS256(t *testing.T, kid string, key *rsa.PrivateKey, c Claims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+	token.Header["kid"] = kid
+	raw, err := token.SignedString(key)
+	require.NoError(t, err)
+	return raw
+}
+
+func TestVerifyRS256WithJWKSRotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]*rsa.PublicKey{"k1": &oldKey.PublicKey})
+
+	v, err := NewVerifier(&config.Config{JWKSFile: path, JWKSRefreshInterval: time.Hour})
+	require.NoError(t, err)
+
+	p, err := v.Verify(signRS256(t, "k1", oldKey, claims(time.Now().Add(time.Hour))))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", p.Subject)
+
+	// A token signed by a key added after startup is accepted once the
+	// set is reloaded on the unknown key ID.
+	writeJWKS(t, path, map[string]*rsa.PublicKey{"k1": &oldKey.PublicKey, "k2": &newKey.PublicKey})
+	v.jwks.lastAttempt = time.Time{}
+	_, err = v.Verify(signRS256(t, "k2", newKey, claims(time.Now().Add(time.Hour))))
+	require.NoError(t, err)
+
+	// HS256 tokens are not accepted when only a JWKS is configured.
+	_, err = v.Verify(signHS256(t, "secret", claims(time.Now().Add(time.Hour))))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
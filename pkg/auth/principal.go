@@ -0,0 +1,58 @@
+package auth
+
+import "context"
+
+const (
+	ScopeProductsWrite           = "products:write"
+	ScopeProductsPublish         = "products:publish"
+	ScopeProductsAdmin           = "products:admin"
+	ScopeProductsReadUnpublished = "products:read_unpublished"
+	ScopeAPIKeysAdmin            = "api_keys:admin"
+)
+
+// Principal is the authenticated caller behind a request.
+type Principal struct {
+	Subject  string
+	TenantID string
+	Scopes   []string
+	Roles    []string
+	// Plan selects the caller's rate limit.
+	Plan string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, or nil for anonymous
+// requests.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
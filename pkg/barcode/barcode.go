@@ -0,0 +1,60 @@
+package barcode
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidChecksum   = errors.New("barcode checksum mismatch")
+	ErrUnsupportedFormat = errors.New("unsupported barcode format")
+)
+
+// Normalize strips separators and folds equivalent GTIN forms, so that a
+// UPC-A code and its zero-padded EAN-13 form compare equal.
+func Normalize(code string) string {
+	code = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code))
+	switch {
+	case len(code) == 12:
+		return "0" + code
+	case len(code) == 14 && code[0] == '0':
+		return code[1:]
+	}
+	return code
+}
+
+// IsValidGTIN reports whether code is a GTIN-8, GTIN-12 (UPC-A),
+// GTIN-13 (EAN-13) or GTIN-14 with a correct check digit.
+func IsValidGTIN(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+		return validChecksum(code)
+	}
+	return false
+}
+
+// CheckDigit computes the GS1 mod-10 check digit for the given payload
+// (the code without its trailing check digit).
+func CheckDigit(payload string) (byte, error) {
+	sum := 0
+	for i := len(payload) - 1; i >= 0; i-- {
+		c := payload[i]
+		if c < '0' || c > '9' {
+			return 0, ErrUnsupportedFormat
+		}
+		d := int(c - '0')
+		if (len(payload)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10), nil
+}
+
+func validChecksum(code string) bool {
+	want, err := CheckDigit(code[:len(code)-1])
+	if err != nil {
+		return false
+	}
+	return code[len(code)-1] == want
+}
@@ -0,0 +1,40 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidGTIN(t *testing.T) {
+	cases := map[string]bool{
+		"4006381333931":  true,  // EAN-13
+		"036000291452":   true,  // UPC-A
+		"96385074":       true,  // EAN-8
+		"00012345600012": true,  // GTIN-14
+		"4006381333932":  false, // bad check digit
+		"40063813339":    false, // wrong length
+		"40063813339a1":  false,
+	}
+	for code, want := range cases {
+		assert.Equal(t, want, IsValidGTIN(code), code)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "0036000291452", Normalize("036000291452"))
+	assert.Equal(t, "0036000291452", Normalize("00036000291452"))
+	assert.Equal(t, "4006381333931", Normalize(" 400-6381-333931 "))
+}
+
+func TestEncode(t *testing.T) {
+	modules, err := Encode("4006381333931")
+	assert.NoError(t, err)
+	assert.Len(t, modules, 95)
+
+	_, err = Encode("4006381333932")
+	assert.ErrorIs(t, err, ErrInvalidChecksum)
+
+	_, err = Encode("96385074")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
@@ -0,0 +1,109 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+const (
+	moduleWidth = 2
+	barHeight   = 80
+	quietZone   = 9
+)
+
+var (
+	lCodes = [10]string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	gCodes = [10]string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+	rCodes = [10]string{"1110010", "1100110", "1101100", "1000010", "1011100", "1001110", "1010000", "1000100", "1001000", "1110100"}
+
+	// parity selects L (0) or G (1) encoding for the left half, keyed by the
+	// leading digit of an EAN-13 code.
+	parity = [10]string{"000000", "001011", "001101", "001110", "010011", "011001", "011100", "010101", "010110", "011010"}
+)
+
+// Encode returns the 95 bar modules of an EAN-13 or UPC-A code, true being
+// a dark bar. UPC-A codes are encoded as EAN-13 with a leading zero.
+func Encode(code string) ([]bool, error) {
+	if len(code) == 12 {
+		code = "0" + code
+	}
+	if len(code) != 13 {
+		return nil, ErrUnsupportedFormat
+	}
+	if !validChecksum(code) {
+		return nil, ErrInvalidChecksum
+	}
+
+	var pattern bytes.Buffer
+	pattern.WriteString("101")
+	first := code[0] - '0'
+	for i := 1; i <= 6; i++ {
+		d := code[i] - '0'
+		if parity[first][i-1] == '0' {
+			pattern.WriteString(lCodes[d])
+		} else {
+			pattern.WriteString(gCodes[d])
+		}
+	}
+	pattern.WriteString("01010")
+	for i := 7; i <= 12; i++ {
+		pattern.WriteString(rCodes[code[i]-'0'])
+	}
+	pattern.WriteString("101")
+
+	modules := make([]bool, pattern.Len())
+	for i, b := range pattern.Bytes() {
+		modules[i] = b == '1'
+	}
+	return modules, nil
+}
+
+func RenderSVG(code string) ([]byte, error) {
+	modules, err := Encode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	width := (len(modules) + 2*quietZone) * moduleWidth
+	height := barHeight + 20
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/>`, width, height)
+	for i, dark := range modules {
+		if dark {
+			fmt.Fprintf(&buf, `<rect x="%d" y="0" width="%d" height="%d" fill="#000"/>`, (quietZone+i)*moduleWidth, moduleWidth, barHeight)
+		}
+	}
+	fmt.Fprintf(&buf, `<text x="%d" y="%d" font-family="monospace" font-size="14" text-anchor="middle">%s</text>`, width/2, height-4, code)
+	buf.WriteString(`</svg>`)
+	return buf.Bytes(), nil
+}
+
+func RenderPNG(code string) ([]byte, error) {
+	modules, err := Encode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	width := (len(modules) + 2*quietZone) * moduleWidth
+	img := image.NewGray(image.Rect(0, 0, width, barHeight))
+	for x := 0; x < width; x++ {
+		c := color.Gray{Y: 0xff}
+		if i := x/moduleWidth - quietZone; i >= 0 && i < len(modules) && modules[i] {
+			c = color.Gray{Y: 0x00}
+		}
+		for y := 0; y < barHeight; y++ {
+			img.SetGray(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
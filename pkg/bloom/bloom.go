@@ -0,0 +1,72 @@
+// Package bloom implements a Bloom filter of strings.
+package bloom
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// Filter answers whether a string may have been added. It never reports
+// an added string as absent, and reports an absent one as present with
+// roughly the false positive rate it was sized for. Strings cannot be
+// removed; callers rebuild the filter to forget them.
+type Filter struct {
+	mu   sync.RWMutex
+	bits []uint64
+	m    uint32
+	k    int
+}
+
+// New sizes a filter for capacity strings at a false positive rate of
+// fpRate.
+func New(capacity int, fpRate float64) *Filter {
+	if capacity < 1 {
+		capacity = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	m := math.Ceil(-float64(capacity) * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	k := int(math.Round(m / float64(capacity) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &Filter{bits: make([]uint64, (int(m)+63)/64), m: uint32(m), k: k}
+}
+
+func (f *Filter) Add(s string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, i := range f.slots(s) {
+		f.bits[i/64] |= 1 << (i % 64)
+	}
+}
+
+// Test reports whether s may have been added.
+func (f *Filter) Test(s string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, i := range f.slots(s) {
+		if f.bits[i/64]&(1<<(i%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// slots derives the k bits of s from two halves of one 64-bit hash.
+func (f *Filter) slots(s string) []uint32 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	sum := h.Sum64()
+	h1, h2 := uint32(sum), uint32(sum>>32)|1
+
+	slots := make([]uint32, f.k)
+	for i := range slots {
+		slots[i] = (h1 + uint32(i)*h2) % f.m
+	}
+	return slots
+}
@@ -0,0 +1,33 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddedStringsArePresent(t *testing.T) {
+	f := New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add(fmt.Sprintf("id-%d", i))
+	}
+	for i := 0; i < 1000; i++ {
+		assert.True(t, f.Test(fmt.Sprintf("id-%d", i)))
+	}
+}
+
+func TestFalsePositiveRate(t *testing.T) {
+	f := New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add(fmt.Sprintf("id-%d", i))
+	}
+
+	positives := 0
+	for i := 0; i < 10000; i++ {
+		if f.Test(fmt.Sprintf("other-%d", i)) {
+			positives++
+		}
+	}
+	assert.Less(t, positives, 300, "about 1%% of 10000 expected")
+}
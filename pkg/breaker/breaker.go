@@ -0,0 +1,223 @@
+// Package breaker stops calls to a dependency that keeps failing, so that
+// requests fail fast instead of each waiting out timeouts and retries.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"simple-product-api/pkg/config"
+)
+
+// ErrOpen is returned instead of calling a dependency whose breaker is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	// Closed lets every call through and counts consecutive failures.
+	Closed State = iota
+	// Open rejects every call until OpenTimeout has passed.
+	Open
+	// HalfOpen lets a few trial calls through to see if the dependency
+	// has recovered.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+type Settings struct {
+	Name string
+	// FailureThreshold is the number of consecutive failures that opens
+	// the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before trying again.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of trial calls that must all succeed
+	// to close the breaker again.
+	HalfOpenRequests int
+	// OnStateChange is called on every transition, outside the lock.
+	OnStateChange func(name string, from, to State)
+}
+
+// FromConfig returns the BREAKER_* settings for the named dependency.
+func FromConfig(cfg *config.Config, name string) Settings {
+	return Settings{
+		Name:             name,
+		FailureThreshold: cfg.BreakerFailures,
+		OpenTimeout:      cfg.BreakerOpenTimeout,
+		HalfOpenRequests: cfg.BreakerHalfOpenRequests,
+	}
+}
+
+// LogTransitions returns an OnStateChange hook that logs every transition,
+// as a warning when a breaker opens.
+func LogTransitions(log *logrus.Logger) func(name string, from, to State) {
+	return func(name string, from, to State) {
+		entry := log.WithFields(logrus.Fields{"breaker": name, "from": from.String(), "to": to.String()})
+		if to == Open {
+			entry.Warn("circuit breaker opened")
+			return
+		}
+		entry.Info("circuit breaker changed state")
+	}
+}
+
+// Breaker is a circuit breaker for one dependency. A nil Breaker lets every
+// call through.
+type Breaker struct {
+	settings Settings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	// trials and passed count the half-open calls let through and the ones
+	// that succeeded.
+	trials int
+	passed int
+}
+
+func New(s Settings) *Breaker {
+	if s.FailureThreshold < 1 {
+		s.FailureThreshold = 1
+	}
+	if s.HalfOpenRequests < 1 {
+		s.HalfOpenRequests = 1
+	}
+	return &Breaker{settings: s, now: time.Now}
+}
+
+func (b *Breaker) Name() string {
+	if b == nil {
+		return ""
+	}
+	return b.settings.Name
+}
+
+func (b *Breaker) State() State {
+	if b == nil {
+		return Closed
+	}
+	b.mu.Lock()
+	from := b.state
+	state := b.current()
+	b.mu.Unlock()
+	b.notify(from, state)
+	return state
+}
+
+// Do calls fn unless the breaker is open, in which case it returns ErrOpen.
+// Errors that isFailure reports count towards opening the breaker; any
+// other result, including errors such as "not found", counts as a success.
+func (b *Breaker) Do(isFailure func(error) bool, fn func() error) error {
+	if b == nil {
+		return fn()
+	}
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.record(err != nil && isFailure(err))
+	return err
+}
+
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	from := b.state
+	state := b.current()
+	if state == Open || (state == HalfOpen && b.trials >= b.settings.HalfOpenRequests) {
+		b.mu.Unlock()
+		return ErrOpen
+	}
+	if state == HalfOpen {
+		b.trials++
+	}
+	b.mu.Unlock()
+	b.notify(from, state)
+	return nil
+}
+
+func (b *Breaker) record(failed bool) {
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case Closed:
+		if !failed {
+			b.failures = 0
+		} else if b.failures++; b.failures >= b.settings.FailureThreshold {
+			b.trip()
+		}
+	case HalfOpen:
+		if failed {
+			b.trip()
+		} else if b.passed++; b.passed >= b.settings.HalfOpenRequests {
+			b.state = Closed
+			b.failures = 0
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+// current moves an open breaker to half-open once OpenTimeout has passed.
+// b.mu must be held.
+func (b *Breaker) current() State {
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.settings.OpenTimeout {
+		b.state = HalfOpen
+		b.trials, b.passed = 0, 0
+	}
+	return b.state
+}
+
+func (b *Breaker) trip() {
+	b.state = Open
+	b.openedAt = b.now()
+	b.failures = 0
+}
+
+func (b *Breaker) notify(from, to State) {
+	if from != to && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(b.settings.Name, from, to)
+	}
+}
+
+// Group holds the breakers of the service so that their states can be
+// reported together.
+type Group struct {
+	mu       sync.Mutex
+	breakers []*Breaker
+}
+
+func NewGroup() *Group {
+	return &Group{}
+}
+
+// Add adds b to the group and returns it.
+func (g *Group) Add(b *Breaker) *Breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.breakers = append(g.breakers, b)
+	return b
+}
+
+// Breakers returns the breakers in the order they were added.
+func (g *Group) Breakers() []*Breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]*Breaker(nil), g.breakers...)
+}
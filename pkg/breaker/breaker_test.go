@@ -0,0 +1,110 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errDown = errors.New("connection refused")
+
+func failure(err error) bool { return errors.Is(err, errDown) }
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newTestBreaker(transitions *[]string) (*Breaker, *clock) {
+	c := &clock{t: time.Unix(0, 0)}
+	b := New(Settings{
+		Name:             "test",
+		FailureThreshold: 2,
+		OpenTimeout:      time.Second,
+		HalfOpenRequests: 1,
+		OnStateChange: func(name string, from, to State) {
+			*transitions = append(*transitions, from.String()+"->"+to.String())
+		},
+	})
+	b.now = c.now
+	return b, c
+}
+
+func fail() error    { return errDown }
+func succeed() error { return nil }
+
+func TestOpensAfterConsecutiveFailures(t *testing.T) {
+	var transitions []string
+	b, _ := newTestBreaker(&transitions)
+
+	assert.ErrorIs(t, b.Do(failure, fail), errDown)
+	assert.NoError(t, b.Do(failure, succeed), "a success resets the count")
+	assert.ErrorIs(t, b.Do(failure, fail), errDown)
+	assert.Equal(t, Closed, b.State())
+	assert.ErrorIs(t, b.Do(failure, fail), errDown)
+	assert.Equal(t, Open, b.State())
+
+	called := false
+	err := b.Do(failure, func() error { called = true; return nil })
+	assert.ErrorIs(t, err, ErrOpen)
+	assert.False(t, called)
+	assert.Equal(t, []string{"closed->open"}, transitions)
+}
+
+func TestIgnoresErrorsThatAreNotFailures(t *testing.T) {
+	var transitions []string
+	b, _ := newTestBreaker(&transitions)
+	notFound := errors.New("not found")
+
+	for i := 0; i < 5; i++ {
+		assert.ErrorIs(t, b.Do(failure, func() error { return notFound }), notFound)
+	}
+	assert.Equal(t, Closed, b.State())
+}
+
+func TestHalfOpenClosesOnSuccess(t *testing.T) {
+	var transitions []string
+	b, c := newTestBreaker(&transitions)
+	_ = b.Do(failure, fail)
+	_ = b.Do(failure, fail)
+
+	c.t = c.t.Add(time.Second)
+	assert.NoError(t, b.Do(failure, succeed))
+	assert.Equal(t, Closed, b.State())
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, transitions)
+}
+
+func TestHalfOpenReopensOnFailure(t *testing.T) {
+	var transitions []string
+	b, c := newTestBreaker(&transitions)
+	_ = b.Do(failure, fail)
+	_ = b.Do(failure, fail)
+
+	c.t = c.t.Add(time.Second)
+	assert.ErrorIs(t, b.Do(failure, fail), errDown)
+	assert.Equal(t, Open, b.State())
+	assert.ErrorIs(t, b.Do(failure, succeed), ErrOpen)
+}
+
+func TestHalfOpenLimitsTrialCalls(t *testing.T) {
+	var transitions []string
+	b, c := newTestBreaker(&transitions)
+	_ = b.Do(failure, fail)
+	_ = b.Do(failure, fail)
+	c.t = c.t.Add(time.Second)
+
+	err := b.Do(failure, func() error {
+		// A second call while the trial is still running is rejected.
+		assert.ErrorIs(t, b.Do(failure, succeed), ErrOpen)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Closed, b.State())
+}
+
+func TestNilBreakerCallsThrough(t *testing.T) {
+	var b *Breaker
+	assert.ErrorIs(t, b.Do(failure, fail), errDown)
+	assert.Equal(t, Closed, b.State())
+}
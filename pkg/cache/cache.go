@@ -0,0 +1,65 @@
+// Package cache stores byte values under string keys with a TTL and
+// optional tags, in process, in Redis, or in both.
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"simple-product-api/pkg/config"
+)
+
+// ErrMiss is returned by Get and TTL for keys that are not cached.
+var ErrMiss = errors.New("cache miss")
+
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+	BackendTiered = "tiered"
+)
+
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value for ttl and adds key to each tag, so that it is
+	// dropped by InvalidateTags on any of them.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+	Delete(ctx context.Context, keys ...string) error
+	InvalidateTags(ctx context.Context, tags ...string) error
+	// TTL returns how long key has left.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// Locker is implemented by caches shared between instances. Lock takes
+// key for at most ttl and reports whether it was free; unlock releases it
+// only if it is still held by the caller.
+type Locker interface {
+	Lock(ctx context.Context, key string, ttl time.Duration) (unlock func(context.Context) error, locked bool, err error)
+}
+
+// Scanner is implemented by caches whose keys can be listed by a Redis
+// glob pattern. Both methods walk the keys incrementally, so neither blocks
+// a shared cache the way KEYS would.
+type Scanner interface {
+	// Keys returns up to limit live keys matching pattern.
+	Keys(ctx context.Context, pattern string, limit int) ([]string, error)
+	// DeleteMatching deletes every key matching pattern and returns how
+	// many there were.
+	DeleteMatching(ctx context.Context, pattern string) (int, error)
+}
+
+// New returns the backend named by CACHE_BACKEND.
+func New(cfg *config.Config, rdb *redis.Client, log *logrus.Logger) (Cache, error) {
+	switch cfg.CacheBackend {
+	case BackendMemory:
+		return NewMemory(cfg.CacheMemorySize), nil
+	case BackendRedis:
+		return NewRedis(rdb), nil
+	case BackendTiered:
+		return NewTiered(rdb, NewMemory(cfg.CacheMemorySize), cfg.CacheL1TTL, log), nil
+	}
+	return nil, fmt.Errorf("CACHE_BACKEND: unknown backend %q", cfg.CacheBackend)
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ErrVersion is returned by Unmarshal for values written under another
+// schema version. Callers treat it as a miss and overwrite the value.
+var ErrVersion = errors.New("cache value has another schema version")
+
+const (
+	FormatJSON    = "json"
+	FormatMsgpack = "msgpack"
+)
+
+// Encoded values start with a four byte header: codecMarker, the schema
+// version, the format and flags. The marker is never the first byte of JSON
+// or MessagePack, so values written before the header existed are still
+// told apart and read as plain JSON.
+const (
+	codecMarker byte = 0xc1
+	headerSize       = 4
+
+	formatJSON    byte = 1
+	formatMsgpack byte = 2
+
+	flagS2 byte = 1 << 0
+)
+
+// Codec encodes cached values in one format, tagged with the schema
+// version of the types being cached. It decodes values in any format it
+// knows, so that instances of a rolling deploy that changes the format can
+// read each other's values, but only of its own schema version.
+type Codec struct {
+	version       byte
+	format        byte
+	compressAbove int
+}
+
+// NewCodec returns a codec writing format, tagged with version. Encoded
+// values longer than compressAbove bytes are compressed with S2; zero
+// turns compression off.
+func NewCodec(format string, version byte, compressAbove int) (*Codec, error) {
+	c := &Codec{version: version, compressAbove: compressAbove}
+	switch format {
+	case FormatJSON:
+		c.format = formatJSON
+	case FormatMsgpack:
+		c.format = formatMsgpack
+	default:
+		return nil, fmt.Errorf("CACHE_CODEC: unknown format %q", format)
+	}
+	return c, nil
+}
+
+// IsEncoded reports whether data was written by a Codec rather than being
+// plain JSON from before codecs existed.
+func IsEncoded(data []byte) bool {
+	return len(data) >= headerSize && data[0] == codecMarker
+}
+
+func (c *Codec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write([]byte{codecMarker, c.version, c.format, 0})
+
+	var err error
+	switch c.format {
+	case formatMsgpack:
+		enc := msgpack.GetEncoder()
+		enc.Reset(&buf)
+		enc.SetCustomStructTag("json")
+		err = enc.Encode(v)
+		msgpack.PutEncoder(enc)
+	default:
+		err = json.NewEncoder(&buf).Encode(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data := buf.Bytes()
+	if c.compressAbove > 0 && len(data)-headerSize > c.compressAbove {
+		out := append([]byte{codecMarker, c.version, c.format, flagS2}, s2.Encode(nil, data[headerSize:])...)
+		return out, nil
+	}
+	return data, nil
+}
+
+func (c *Codec) Unmarshal(data []byte, v any) error {
+	if !IsEncoded(data) {
+		return json.Unmarshal(data, v)
+	}
+	if data[1] != c.version {
+		return fmt.Errorf("%w: %d, want %d", ErrVersion, data[1], c.version)
+	}
+	format, flags, body := data[2], data[3], data[headerSize:]
+
+	if flags&flagS2 != 0 {
+		var err error
+		if body, err = s2.Decode(nil, body); err != nil {
+			return err
+		}
+	}
+
+	switch format {
+	case formatMsgpack:
+		dec := msgpack.GetDecoder()
+		defer msgpack.PutDecoder(dec)
+		dec.Reset(bytes.NewReader(body))
+		dec.SetCustomStructTag("json")
+		return dec.Decode(v)
+	case formatJSON:
+		return json.Unmarshal(body, v)
+	}
+	return fmt.Errorf("unknown cache value format %d", format)
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type codecValue struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name,omitempty"`
+	Price float64  `json:"price"`
+	Tags  []string `json:"tags"`
+}
+
+func TestCodecRoundTrips(t *testing.T) {
+	in := codecValue{ID: "p1", Name: strings.Repeat("sawi ", 100), Price: 12.5, Tags: []string{"a", "b"}}
+
+	for _, format := range []string{FormatJSON, FormatMsgpack} {
+		for _, compressAbove := range []int{0, 64} {
+			c, err := NewCodec(format, 1, compressAbove)
+			require.NoError(t, err)
+
+			data, err := c.Marshal(in)
+			require.NoError(t, err)
+			assert.True(t, IsEncoded(data))
+			assert.Equal(t, compressAbove > 0, data[3]&flagS2 != 0, "%s compressed", format)
+
+			var out codecValue
+			require.NoError(t, c.Unmarshal(data, &out))
+			assert.Equal(t, in, out)
+		}
+	}
+}
+
+func TestCodecUsesJSONFieldNames(t *testing.T) {
+	c, _ := NewCodec(FormatMsgpack, 1, 0)
+	data, err := c.Marshal(codecValue{ID: "p1"})
+	require.NoError(t, err)
+
+	var out map[string]any
+	require.NoError(t, c.Unmarshal(data, &out))
+	assert.Equal(t, "p1", out["id"])
+	assert.NotContains(t, out, "name")
+}
+
+func TestCodecReadsOtherFormats(t *testing.T) {
+	js, _ := NewCodec(FormatJSON, 1, 0)
+	mp, _ := NewCodec(FormatMsgpack, 1, 0)
+
+	data, err := js.Marshal(codecValue{ID: "p1"})
+	require.NoError(t, err)
+	var out codecValue
+	require.NoError(t, mp.Unmarshal(data, &out))
+	assert.Equal(t, "p1", out.ID)
+}
+
+func TestCodecReadsPlainJSON(t *testing.T) {
+	c, _ := NewCodec(FormatMsgpack, 1, 0)
+	data := []byte(`{"id":"p1","price":3}`)
+	assert.False(t, IsEncoded(data))
+
+	var out codecValue
+	require.NoError(t, c.Unmarshal(data, &out))
+	assert.Equal(t, codecValue{ID: "p1", Price: 3}, out)
+}
+
+func TestCodecRejectsOtherVersions(t *testing.T) {
+	v1, _ := NewCodec(FormatMsgpack, 1, 0)
+	v2, _ := NewCodec(FormatMsgpack, 2, 0)
+
+	data, err := v1.Marshal(codecValue{ID: "p1"})
+	require.NoError(t, err)
+	var out codecValue
+	assert.ErrorIs(t, v2.Unmarshal(data, &out), ErrVersion)
+}
+
+func TestNewCodecRejectsUnknownFormats(t *testing.T) {
+	_, err := NewCodec("protobuf", 1, 0)
+	assert.Error(t, err)
+}
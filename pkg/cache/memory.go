@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"path"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process LRU cache holding at most size entries. It is
+// not shared between instances.
+type Memory struct {
+	size int
+	now  func() time.Time
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	tags  map[string]map[string]struct{}
+}
+
+type memoryItem struct {
+	key     string
+	value   []byte
+	expires time.Time
+	tags    []string
+}
+
+func NewMemory(size int) *Memory {
+	if size < 1 {
+		size = 1
+	}
+	return &Memory{
+		size:  size,
+		now:   time.Now,
+		ll:    list.New(),
+		items: map[string]*list.Element{},
+		tags:  map[string]map[string]struct{}{},
+	}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.lookup(key)
+	if !ok {
+		return nil, ErrMiss
+	}
+	return item.value, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		m.remove(el)
+	}
+	item := &memoryItem{key: key, value: value, tags: tags}
+	if ttl > 0 {
+		item.expires = m.now().Add(ttl)
+	}
+	m.items[key] = m.ll.PushFront(item)
+	for _, tag := range tags {
+		if m.tags[tag] == nil {
+			m.tags[tag] = map[string]struct{}{}
+		}
+		m.tags[tag][key] = struct{}{}
+	}
+
+	for m.ll.Len() > m.size {
+		m.remove(m.ll.Back())
+	}
+	return nil
+}
+
+func (m *Memory) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := m.items[key]; ok {
+			m.remove(el)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) InvalidateTags(_ context.Context, tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range m.tags[tag] {
+			if el, ok := m.items[key]; ok {
+				m.remove(el)
+			}
+		}
+		delete(m.tags, tag)
+	}
+	return nil
+}
+
+func (m *Memory) TTL(_ context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.lookup(key)
+	if !ok {
+		return 0, ErrMiss
+	}
+	if item.expires.IsZero() {
+		return 0, nil
+	}
+	return item.expires.Sub(m.now()), nil
+}
+
+func (m *Memory) Keys(_ context.Context, pattern string, limit int) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var keys []string
+	for el := m.ll.Front(); el != nil && len(keys) < limit; el = el.Next() {
+		item := el.Value.(*memoryItem)
+		if ok, err := path.Match(pattern, item.key); err != nil {
+			return nil, err
+		} else if ok && (item.expires.IsZero() || m.now().Before(item.expires)) {
+			keys = append(keys, item.key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *Memory) DeleteMatching(_ context.Context, pattern string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for el := m.ll.Front(); el != nil; {
+		next := el.Next()
+		if ok, err := path.Match(pattern, el.Value.(*memoryItem).key); err != nil {
+			return n, err
+		} else if ok {
+			m.remove(el)
+			n++
+		}
+		el = next
+	}
+	return n, nil
+}
+
+// lookup returns a live item and marks it as recently used. m.mu must be
+// held.
+func (m *Memory) lookup(key string) (*memoryItem, bool) {
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := el.Value.(*memoryItem)
+	if !item.expires.IsZero() && !m.now().Before(item.expires) {
+		m.remove(el)
+		return nil, false
+	}
+	m.ll.MoveToFront(el)
+	return item, true
+}
+
+// remove drops an entry and its tag memberships. m.mu must be held.
+func (m *Memory) remove(el *list.Element) {
+	item := m.ll.Remove(el).(*memoryItem)
+	delete(m.items, item.key)
+	for _, tag := range item.tags {
+		if keys := m.tags[tag]; keys != nil {
+			delete(keys, item.key)
+			if len(keys) == 0 {
+				delete(m.tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(2)
+
+	require.NoError(t, m.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, m.Set(ctx, "b", []byte("2"), 0))
+	_, err := m.Get(ctx, "a")
+	require.NoError(t, err)
+	require.NoError(t, m.Set(ctx, "c", []byte("3"), 0))
+
+	_, err = m.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrMiss)
+	v, err := m.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", string(v))
+}
+
+func TestMemoryExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	m := NewMemory(10)
+	m.now = func() time.Time { return now }
+
+	require.NoError(t, m.Set(ctx, "a", []byte("1"), time.Minute))
+	ttl, err := m.TTL(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+
+	now = now.Add(time.Minute)
+	_, err = m.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrMiss)
+	_, err = m.TTL(ctx, "a")
+	assert.ErrorIs(t, err, ErrMiss)
+}
+
+func TestMemoryInvalidateTags(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(10)
+
+	require.NoError(t, m.Set(ctx, "list:1", []byte("1"), 0, "lists"))
+	require.NoError(t, m.Set(ctx, "list:2", []byte("2"), 0, "lists"))
+	require.NoError(t, m.Set(ctx, "id:1", []byte("3"), 0))
+
+	require.NoError(t, m.InvalidateTags(ctx, "lists"))
+
+	_, err := m.Get(ctx, "list:1")
+	assert.ErrorIs(t, err, ErrMiss)
+	_, err = m.Get(ctx, "list:2")
+	assert.ErrorIs(t, err, ErrMiss)
+	_, err = m.Get(ctx, "id:1")
+	assert.NoError(t, err)
+}
+
+func TestMemoryKeysAndDeleteMatching(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(10)
+	require.NoError(t, m.Set(ctx, "products:id:1", []byte("1"), 0))
+	require.NoError(t, m.Set(ctx, "products:id:2", []byte("2"), 0))
+	require.NoError(t, m.Set(ctx, "products:all:1", []byte("3"), 0))
+
+	keys, err := m.Keys(ctx, "products:id:*", 10)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"products:id:1", "products:id:2"}, keys)
+
+	n, err := m.DeleteMatching(ctx, "products:id:*")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	keys, _ = m.Keys(ctx, "*", 10)
+	assert.Equal(t, []string{"products:all:1"}, keys)
+}
@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// scanCount is the number of keys each SCAN asks Redis to look at.
+const scanCount = 100
+
+// unlockScript deletes a lock only if it is still held by the same owner,
+// so a caller that outlived its lock cannot release someone else's.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// invalidateScript deletes each tag set given in KEYS together with the keys
+// it lists, and returns all of them. Running in one script, no key can be
+// added to a tag between reading the set and deleting it and so outlive the
+// invalidation. Keys are deleted in batches to stay within Lua's stack.
+var invalidateScript = redis.NewScript(`
+local deleted = {}
+for _, tag in ipairs(KEYS) do
+	local members = redis.call("SMEMBERS", tag)
+	for i = 1, #members, 500 do
+		redis.call("DEL", unpack(members, i, math.min(i + 499, #members)))
+	end
+	redis.call("DEL", tag)
+	for _, key in ipairs(members) do
+		deleted[#deleted + 1] = key
+	end
+	deleted[#deleted + 1] = tag
+end
+return deleted`)
+
+// Redis is a cache shared by every instance. Each tag is a set of the keys
+// stored with it, kept at least as long as the longest lived of them.
+type Redis struct {
+	rdb *redis.Client
+}
+
+func NewRedis(rdb *redis.Client) *Redis {
+	return &Redis{rdb: rdb}
+}
+
+func tagKey(tag string) string {
+	return "tag:" + tag
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := r.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return data, err
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if len(tags) == 0 {
+		return r.rdb.Set(ctx, key, value, ttl).Err()
+	}
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, value, ttl)
+		for _, tag := range tags {
+			pipe.SAdd(ctx, tagKey(tag), key)
+			if ttl > 0 {
+				pipe.ExpireNX(ctx, tagKey(tag), ttl)
+				pipe.ExpireGT(ctx, tagKey(tag), ttl)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.rdb.Del(ctx, keys...).Err()
+}
+
+func (r *Redis) InvalidateTags(ctx context.Context, tags ...string) error {
+	_, err := r.invalidateTags(ctx, tags...)
+	return err
+}
+
+// invalidateTags deletes every key stored with tags and returns them.
+func (r *Redis) invalidateTags(ctx context.Context, tags ...string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	tagKeys := make([]string, len(tags))
+	for i, tag := range tags {
+		tagKeys[i] = tagKey(tag)
+	}
+	return invalidateScript.Run(ctx, r.rdb, tagKeys).StringSlice()
+}
+
+func (r *Redis) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.rdb.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// go-redis passes the -2 (no key) and -1 (no expiry) replies through
+	// unscaled.
+	switch ttl {
+	case -2:
+		return 0, ErrMiss
+	case -1:
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (r *Redis) Lock(ctx context.Context, key string, ttl time.Duration) (func(context.Context) error, bool, error) {
+	lockKey := "lock:" + key
+	token := uuid.NewString()
+
+	ok, err := r.rdb.SetNX(ctx, lockKey, token, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return func(ctx context.Context) error {
+		return unlockScript.Run(ctx, r.rdb, []string{lockKey}, token).Err()
+	}, true, nil
+}
+
+func (r *Redis) Keys(ctx context.Context, pattern string, limit int) ([]string, error) {
+	var keys []string
+	iter := r.rdb.Scan(ctx, 0, pattern, scanCount).Iterator()
+	for len(keys) < limit && iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+func (r *Redis) DeleteMatching(ctx context.Context, pattern string) (int, error) {
+	n := 0
+	err := r.deleteMatching(ctx, pattern, func(keys []string) { n += len(keys) })
+	return n, err
+}
+
+// deleteMatching unlinks the keys matching pattern a SCAN batch at a time,
+// calling deleted with each batch.
+func (r *Redis) deleteMatching(ctx context.Context, pattern string, deleted func([]string)) error {
+	var cursor uint64
+	for {
+		keys, next, err := r.rdb.Scan(ctx, cursor, pattern, scanCount).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := r.rdb.Unlink(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			deleted(keys)
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	m := miniredis.RunT(t)
+	return redis.NewClient(&redis.Options{Addr: m.Addr()}), m
+}
+
+func TestRedisSetGetTTL(t *testing.T) {
+	ctx := context.Background()
+	rdb, _ := newTestRedis(t)
+	c := NewRedis(rdb)
+
+	_, err := c.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrMiss)
+	_, err = c.TTL(ctx, "a")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	v, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(v))
+	ttl, err := c.TTL(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+}
+
+func TestRedisInvalidateTags(t *testing.T) {
+	ctx := context.Background()
+	rdb, m := newTestRedis(t)
+	c := NewRedis(rdb)
+
+	require.NoError(t, c.Set(ctx, "list:1", []byte("1"), time.Minute, "lists"))
+	require.NoError(t, c.Set(ctx, "list:2", []byte("2"), time.Hour, "lists"))
+	require.NoError(t, c.Set(ctx, "id:1", []byte("3"), time.Minute))
+	assert.Equal(t, time.Hour, m.TTL(tagKey("lists")), "tags live as long as their longest lived key")
+
+	require.NoError(t, c.InvalidateTags(ctx, "lists"))
+
+	assert.False(t, m.Exists("list:1"))
+	assert.False(t, m.Exists("list:2"))
+	assert.False(t, m.Exists(tagKey("lists")))
+	assert.True(t, m.Exists("id:1"))
+}
+
+func TestRedisInvalidateLargeTag(t *testing.T) {
+	ctx := context.Background()
+	rdb, m := newTestRedis(t)
+	c := NewRedis(rdb)
+
+	for i := 0; i < 1200; i++ {
+		require.NoError(t, c.Set(ctx, fmt.Sprintf("list:%d", i), []byte("1"), time.Minute, "lists"))
+	}
+	require.NoError(t, c.Set(ctx, "other", []byte("1"), time.Minute, "others"))
+
+	keys, err := c.invalidateTags(ctx, "lists", "others")
+	require.NoError(t, err)
+
+	assert.Len(t, keys, 1203)
+	assert.Contains(t, keys, tagKey("lists"))
+	assert.Contains(t, keys, "other")
+	assert.Empty(t, m.Keys())
+}
+
+func TestRedisLock(t *testing.T) {
+	ctx := context.Background()
+	rdb, m := newTestRedis(t)
+	c := NewRedis(rdb)
+
+	unlock, locked, err := c.Lock(ctx, "a", time.Second)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	_, locked, err = c.Lock(ctx, "a", time.Second)
+	require.NoError(t, err)
+	assert.False(t, locked)
+
+	require.NoError(t, unlock(ctx))
+	assert.False(t, m.Exists("lock:a"))
+}
+
+func TestRedisUnlockKeepsOthersLock(t *testing.T) {
+	ctx := context.Background()
+	rdb, m := newTestRedis(t)
+	c := NewRedis(rdb)
+
+	unlock, _, err := c.Lock(ctx, "a", time.Second)
+	require.NoError(t, err)
+	m.FastForward(time.Second)
+	_, locked, err := c.Lock(ctx, "a", time.Second)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	require.NoError(t, unlock(ctx))
+	assert.True(t, m.Exists("lock:a"))
+}
+
+func TestTieredInvalidatesOtherInstances(t *testing.T) {
+	ctx := context.Background()
+	rdb, m := newTestRedis(t)
+	a := NewTiered(rdb, NewMemory(10), time.Minute, logrus.New())
+	b := NewTiered(rdb, NewMemory(10), time.Minute, logrus.New())
+	t.Cleanup(func() {
+		a.Close()
+		b.Close()
+	})
+	require.Eventually(t, func() bool {
+		return m.PubSubNumSub(invalidationChannel)[invalidationChannel] == 2
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, a.Set(ctx, "list:1", []byte("old"), time.Minute, "lists"))
+	v, err := b.Get(ctx, "list:1")
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(v))
+
+	// b now serves list:1 from its L1, so a change made through a only
+	// reaches it through the invalidation.
+	require.NoError(t, a.InvalidateTags(ctx, "lists"))
+	assert.Eventually(t, func() bool {
+		_, err := b.Get(ctx, "list:1")
+		return errors.Is(err, ErrMiss)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestRedisKeysAndDeleteMatching(t *testing.T) {
+	ctx := context.Background()
+	rdb, m := newTestRedis(t)
+	c := NewRedis(rdb)
+	for i := 0; i < 250; i++ {
+		require.NoError(t, c.Set(ctx, fmt.Sprintf("products:id:%d", i), []byte("1"), time.Minute))
+	}
+	require.NoError(t, c.Set(ctx, "products:all:1", []byte("1"), time.Minute))
+
+	keys, err := c.Keys(ctx, "products:id:*", 10)
+	require.NoError(t, err)
+	assert.Len(t, keys, 10)
+
+	n, err := c.DeleteMatching(ctx, "products:id:*")
+	require.NoError(t, err)
+	assert.Equal(t, 250, n)
+	assert.Equal(t, []string{"products:all:1"}, m.Keys())
+}
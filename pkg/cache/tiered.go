@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// invalidationChannel carries the keys each instance changes in Redis so
+// that the others drop them from their L1.
+const invalidationChannel = "cache:invalidate"
+
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+// Tiered keeps recently used values in a per-instance L1 in front of
+// Redis. L1 entries live for at most l1TTL, and every change made through
+// any instance is published so that the others drop their L1 copies.
+type Tiered struct {
+	l1    *Memory
+	l2    *Redis
+	rdb   *redis.Client
+	l1TTL time.Duration
+	log   *logrus.Logger
+	id    string
+	sub   *redis.PubSub
+}
+
+// NewTiered subscribes to invalidations from other instances until Close.
+func NewTiered(rdb *redis.Client, l1 *Memory, l1TTL time.Duration, log *logrus.Logger) *Tiered {
+	t := &Tiered{
+		l1:    l1,
+		l2:    NewRedis(rdb),
+		rdb:   rdb,
+		l1TTL: l1TTL,
+		log:   log,
+		id:    uuid.NewString(),
+		sub:   rdb.Subscribe(context.Background(), invalidationChannel),
+	}
+	go t.listen()
+	return t
+}
+
+func (t *Tiered) Close() error {
+	return t.sub.Close()
+}
+
+func (t *Tiered) Get(ctx context.Context, key string) ([]byte, error) {
+	if value, err := t.l1.Get(ctx, key); err == nil {
+		return value, nil
+	}
+	value, err := t.l2.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	_ = t.l1.Set(ctx, key, value, t.l1TTL)
+	return value, nil
+}
+
+func (t *Tiered) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if err := t.l2.Set(ctx, key, value, ttl, tags...); err != nil {
+		return err
+	}
+	l1TTL := t.l1TTL
+	if ttl > 0 && ttl < l1TTL {
+		l1TTL = ttl
+	}
+	_ = t.l1.Set(ctx, key, value, l1TTL)
+	t.publish(ctx, key)
+	return nil
+}
+
+func (t *Tiered) Delete(ctx context.Context, keys ...string) error {
+	_ = t.l1.Delete(ctx, keys...)
+	if err := t.l2.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	t.publish(ctx, keys...)
+	return nil
+}
+
+// InvalidateTags resolves tags to keys through Redis, since L1 entries
+// filled from Redis do not know their tags.
+func (t *Tiered) InvalidateTags(ctx context.Context, tags ...string) error {
+	keys, err := t.l2.invalidateTags(ctx, tags...)
+	if err != nil {
+		return err
+	}
+	_ = t.l1.Delete(ctx, keys...)
+	t.publish(ctx, keys...)
+	return nil
+}
+
+func (t *Tiered) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return t.l2.TTL(ctx, key)
+}
+
+func (t *Tiered) Lock(ctx context.Context, key string, ttl time.Duration) (func(context.Context) error, bool, error) {
+	return t.l2.Lock(ctx, key, ttl)
+}
+
+func (t *Tiered) Keys(ctx context.Context, pattern string, limit int) ([]string, error) {
+	return t.l2.Keys(ctx, pattern, limit)
+}
+
+func (t *Tiered) DeleteMatching(ctx context.Context, pattern string) (int, error) {
+	_, _ = t.l1.DeleteMatching(ctx, pattern)
+	n := 0
+	err := t.l2.deleteMatching(ctx, pattern, func(keys []string) {
+		n += len(keys)
+		t.publish(ctx, keys...)
+	})
+	return n, err
+}
+
+func (t *Tiered) publish(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	msg, _ := json.Marshal(invalidation{Origin: t.id, Keys: keys})
+	if err := t.rdb.Publish(ctx, invalidationChannel, msg).Err(); err != nil {
+		t.log.WithError(err).Warn("failed to publish cache invalidation, other instances may serve stale values until their L1 expires")
+	}
+}
+
+func (t *Tiered) listen() {
+	for msg := range t.sub.Channel() {
+		var inv invalidation
+		if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+			t.log.WithError(err).Warn("ignoring malformed cache invalidation")
+			continue
+		}
+		if inv.Origin != t.id {
+			_ = t.l1.Delete(context.Background(), inv.Keys...)
+		}
+	}
+}
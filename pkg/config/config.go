@@ -1,31 +1,175 @@
+// Package config loads the service configuration from built in defaults, a
+// YAML file, a .env file, the environment and command line flags, each
+// overriding the one before.
+//
+// Every field is declared once, with struct tags: env names the
+// environment variable, from which the YAML key (lower case) and the flag
+// (lower case, dashes) are derived; default is the value used when no
+// source sets it; required fields must not be empty; secret fields are
+// redacted when printed; oneof, min and max bound the value; and reload
+// marks the fields that can change without a restart.
 package config
 
 import (
-	"github.com/joho/godotenv"
-	"github.com/sirupsen/logrus"
-	"os"
+	"time"
 )
 
 type Config struct {
-	ServerPort   string
-	PostgresDSN  string
-	RedisAddress string
-}
+	// The HTTP server listens on ServerPort. Requests must be read within
+	// ServerReadTimeout and responses written within ServerWriteTimeout;
+	// idle keep-alive connections are closed after ServerIdleTimeout.
+	ServerPort         string        `env:"SERVER_PORT" default:"8080" required:"true"`
+	ServerReadTimeout  time.Duration `env:"SERVER_READ_TIMEOUT" default:"10s" min:"0s"`
+	ServerWriteTimeout time.Duration `env:"SERVER_WRITE_TIMEOUT" default:"10s" min:"0s"`
+	ServerIdleTimeout  time.Duration `env:"SERVER_IDLE_TIMEOUT" default:"1m" min:"0s"`
+	ServerBodyLimit    int           `env:"SERVER_BODY_LIMIT" default:"4194304" min:"1"`
 
-func Load() *Config {
-	_ = godotenv.Load(".env") // load .env file
+	// PostgresDSN is a lib/pq connection string. The pool keeps at most
+	// DBMaxOpenConns connections, DBMaxIdleConns of them idle, and
+	// replaces them after DBConnMaxLifetime, or DBConnMaxIdleTime unused.
+	PostgresDSN       string        `env:"POSTGRES_DSN" required:"true" secret:"true"`
+	DBMaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS" default:"25" min:"1"`
+	DBMaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS" default:"10" min:"0"`
+	DBConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"30m" min:"0s"`
+	DBConnMaxIdleTime time.Duration `env:"DB_CONN_MAX_IDLE_TIME" default:"5m" min:"0s"`
 
-	return &Config{
-		ServerPort:   getEnv("SERVER_PORT", "8080"),
-		PostgresDSN:  getEnv("POSTGRES_DSN", ""),
-		RedisAddress: getEnv("REDIS_ADDRESS", ""),
-	}
-}
+	// RedisPoolSize of zero keeps the client default of ten connections
+	// per CPU.
+	RedisAddress  string `env:"REDIS_ADDRESS" required:"true"`
+	RedisPassword string `env:"REDIS_PASSWORD" secret:"true"`
+	RedisDB       int    `env:"REDIS_DB" default:"0" min:"0"`
+	RedisPoolSize int    `env:"REDIS_POOL_SIZE" default:"0" min:"0"`
+
+	// DuplicateThreshold is the name similarity (0-1) at or above which a
+	// new product is reported as a likely duplicate of an existing one.
+	// Candidates are looked up by trigram similarity at the same threshold;
+	// below 0.3 that lookup cannot use the trigram index.
+	DuplicateThreshold float64 `env:"DUPLICATE_SIMILARITY_THRESHOLD" default:"0.85" min:"0" max:"1"`
+
+	// JWTSecret enables HS256 tokens; JWKSURL or JWKSFile enable RS256
+	// tokens signed by the keys in that set.
+	JWTSecret           string        `env:"JWT_HS256_SECRET" secret:"true"`
+	JWKSURL             string        `env:"JWT_JWKS_URL"`
+	JWKSFile            string        `env:"JWT_JWKS_FILE"`
+	JWKSRefreshInterval time.Duration `env:"JWT_JWKS_REFRESH_INTERVAL" default:"15m" min:"1s"`
+	JWTIssuer           string        `env:"JWT_ISSUER"`
+	JWTAudience         string        `env:"JWT_AUDIENCE"`
+
+	// PolicyFile is a YAML policy replacing the built in role rules.
+	PolicyFile string `env:"POLICY_FILE"`
+
+	// Rate limits are written as requests/period, e.g. "20/1m". Plans and
+	// routes are comma separated lists of "plan=limit" and
+	// "METHOD /path=limit". RateLimitIP applies to every request from one
+	// client IP, on every route and before credentials are checked; empty
+	// turns it off.
+	RateLimitDefault string `env:"RATE_LIMIT_DEFAULT" default:"20/1m" required:"true" reload:"true"`
+	RateLimitPlans   string `env:"RATE_LIMIT_PLANS" reload:"true"`
+	RateLimitRoutes  string `env:"RATE_LIMIT_ROUTES" reload:"true"`
+	RateLimitIP      string `env:"RATE_LIMIT_IP" default:"600/1m" reload:"true"`
+
+	// IdempotencyTTL is how long responses are kept for replay;
+	// IdempotencyLockTTL bounds how long a crashed request blocks retries.
+	IdempotencyTTL     time.Duration `env:"IDEMPOTENCY_TTL" default:"24h" min:"1s"`
+	IdempotencyLockTTL time.Duration `env:"IDEMPOTENCY_LOCK_TTL" default:"1m" min:"1s"`
+
+	// Dependency retries: attempts include the first call, delays use full
+	// jitter, and the budget caps retries at RetryBudgetRatio per success.
+	RetryAttempts     int           `env:"RETRY_ATTEMPTS" default:"3" min:"1"`
+	RetryBaseDelay    time.Duration `env:"RETRY_BASE_DELAY" default:"50ms" min:"0s"`
+	RetryMaxDelay     time.Duration `env:"RETRY_MAX_DELAY" default:"1s" min:"0s"`
+	RetryBudgetTokens int           `env:"RETRY_BUDGET_TOKENS" default:"10" min:"0"`
+	RetryBudgetRatio  float64       `env:"RETRY_BUDGET_RATIO" default:"0.1" min:"0"`
+
+	// RequestTimeout bounds every API request; RouteTimeouts overrides it
+	// with a comma separated list of "METHOD /path=duration".
+	RequestTimeout time.Duration `env:"REQUEST_TIMEOUT" default:"3s" min:"1ms" reload:"true"`
+	RouteTimeouts  string        `env:"ROUTE_TIMEOUTS" reload:"true"`
+
+	// Per-operation timeouts, applied within the request deadline. Cache
+	// writes run after the response and only get CacheWriteTimeout.
+	DBTimeout         time.Duration `env:"DB_TIMEOUT" default:"2s" min:"0s"`
+	CacheTimeout      time.Duration `env:"CACHE_TIMEOUT" default:"200ms" min:"0s"`
+	CacheWriteTimeout time.Duration `env:"CACHE_WRITE_TIMEOUT" default:"1s" min:"0s"`
+
+	// CacheTTL is how long cached products are fresh, shortened by up to
+	// CacheTTLJitter (a fraction) so keys written together do not expire
+	// together. For CacheStaleTTL after that, expired values are served
+	// while one request refreshes them. CacheLockTTL, when set, makes
+	// instances take a Redis lock so only one of them loads a missing key.
+	CacheTTL       time.Duration `env:"CACHE_TTL" default:"5m" min:"0s"`
+	CacheTTLJitter float64       `env:"CACHE_TTL_JITTER" default:"0.1" min:"0" max:"1"`
+	CacheStaleTTL  time.Duration `env:"CACHE_STALE_TTL" default:"0s" min:"0s"`
+	CacheLockTTL   time.Duration `env:"CACHE_LOCK_TTL" default:"0s" min:"0s"`
+
+	// CacheBackend is "redis", "memory" (per instance LRU of
+	// CacheMemorySize entries) or "tiered" (that LRU in front of Redis,
+	// holding entries for at most CacheL1TTL).
+	CacheBackend    string        `env:"CACHE_BACKEND" default:"redis" oneof:"redis memory tiered"`
+	CacheMemorySize int           `env:"CACHE_MEMORY_SIZE" default:"10000" min:"1"`
+	CacheL1TTL      time.Duration `env:"CACHE_L1_TTL" default:"10s" min:"0s"`
+
+	// CacheCodec is how cached values are encoded, "msgpack" or "json".
+	// Values longer than CacheCompressAbove bytes are compressed; zero
+	// turns compression off.
+	CacheCodec         string `env:"CACHE_CODEC" default:"msgpack" oneof:"msgpack json"`
+	CacheCompressAbove int    `env:"CACHE_COMPRESS_ABOVE" default:"1024" min:"0"`
+
+	// CacheNegativeTTL is how long a lookup of a missing product is
+	// remembered; zero turns negative caching off.
+	CacheNegativeTTL time.Duration `env:"CACHE_NEGATIVE_TTL" default:"30s" min:"0s"`
+
+	// CacheWarmInterval, when set, counts which products and list pages
+	// are requested and, on startup and at that interval, loads the
+	// CacheWarmIDs most requested products and CacheWarmLists most
+	// requested pages into the cache.
+	CacheWarmInterval time.Duration `env:"CACHE_WARM_INTERVAL" default:"15m" min:"0s"`
+	CacheWarmIDs      int           `env:"CACHE_WARM_IDS" default:"100" min:"0"`
+	CacheWarmLists    int           `env:"CACHE_WARM_LISTS" default:"20" min:"0"`
+
+	// ProductBloomCapacity, when set, keeps a Bloom filter of known
+	// product IDs sized for that many products, so lookups of IDs it has
+	// never seen are rejected without any I/O, unless the IDs are newer
+	// than the filter. The filter is rebuilt from the database every
+	// ProductBloomRebuild.
+	ProductBloomCapacity int           `env:"PRODUCT_BLOOM_CAPACITY" default:"0" min:"0"`
+	ProductBloomFPRate   float64       `env:"PRODUCT_BLOOM_FP_RATE" default:"0.01" min:"0" max:"1"`
+	ProductBloomRebuild  time.Duration `env:"PRODUCT_BLOOM_REBUILD" default:"1h" min:"0s"`
+
+	// Circuit breakers for Postgres and Redis open after BreakerFailures
+	// consecutive transient errors and let BreakerHalfOpenRequests trial
+	// calls through once BreakerOpenTimeout has passed.
+	BreakerFailures         int           `env:"BREAKER_FAILURES" default:"5" min:"1"`
+	BreakerOpenTimeout      time.Duration `env:"BREAKER_OPEN_TIMEOUT" default:"10s" min:"0s"`
+	BreakerHalfOpenRequests int           `env:"BREAKER_HALF_OPEN_REQUESTS" default:"1" min:"1"`
+
+	// TraceExporter sends spans over OTLP/HTTP to TraceEndpoint ("otlp"),
+	// prints them to stdout ("stdout") or appends them to TraceFile
+	// ("file"); empty turns tracing off. TraceSampleRatio of new traces
+	// are sampled, and requests whose traceparent is sampled always are.
+	TraceExporter    string  `env:"TRACE_EXPORTER" oneof:"otlp stdout file"`
+	TraceEndpoint    string  `env:"TRACE_ENDPOINT" default:"http://localhost:4318"`
+	TraceFile        string  `env:"TRACE_FILE" default:"traces.jsonl"`
+	TraceSampleRatio float64 `env:"TRACE_SAMPLE_RATIO" default:"1" min:"0" max:"1"`
+	TraceServiceName string  `env:"TRACE_SERVICE_NAME" default:"simple-product-api"`
+
+	// LogLevel is the lowest level logged, such as "debug" or "warn", and
+	// LogFormat is "json" or "text".
+	LogLevel  string `env:"LOG_LEVEL" default:"info" oneof:"panic fatal error warn warning info debug trace" reload:"true"`
+	LogFormat string `env:"LOG_FORMAT" default:"json" oneof:"json text"`
+
+	// HealthCheckTimeout bounds each dependency check run by /readyz.
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" default:"1s" min:"1ms"`
+
+	// On SIGTERM or SIGINT, /readyz fails for ShutdownDelay so that load
+	// balancers stop routing here, then in-flight requests and background
+	// work get ShutdownTimeout to finish before connections are closed.
+	ShutdownDelay   time.Duration `env:"SHUTDOWN_DELAY" default:"0s" min:"0s"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"15s" min:"0s"`
 
-func getEnv(key, fallback string) string {
-	if val, ok := os.LookupEnv(key); ok {
-		return val
-	}
-	logrus.Infof("[CONFIG] ENV '%s' not found, using default: %s", key, fallback)
-	return fallback
+	// args are the command line arguments the configuration was loaded
+	// with, kept for reloads, and sources records which source set each
+	// field, by environment variable name.
+	args    []string
+	sources map[string]string
 }
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// required sets the fields that have no default.
+func required(t *testing.T) {
+	t.Setenv("POSTGRES_DSN", "postgres://app:s3cret@db:5432/app")
+	t.Setenv("REDIS_ADDRESS", "localhost:6379")
+}
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	required(t)
+
+	cfg, err := Load(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "8080", cfg.ServerPort)
+	assert.Equal(t, 5*time.Minute, cfg.CacheTTL)
+	assert.Equal(t, 0.85, cfg.DuplicateThreshold)
+	assert.Equal(t, 25, cfg.DBMaxOpenConns)
+	assert.Equal(t, SourceDefault, cfg.Source("CACHE_TTL"))
+	assert.Equal(t, SourceEnv, cfg.Source("POSTGRES_DSN"))
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	required(t)
+	path := writeFile(t, "server_port: 9000\ncache_ttl: 10m\nlog_level: debug\nretry_attempts: 5\n")
+	t.Setenv("CACHE_TTL", "1m")
+	t.Setenv("LOG_LEVEL", "warn")
+
+	cfg, err := Load([]string{"--config", path, "--log-level=error"})
+	require.NoError(t, err)
+	assert.Equal(t, "9000", cfg.ServerPort, "file over default")
+	assert.Equal(t, time.Minute, cfg.CacheTTL, "env over file")
+	assert.Equal(t, "error", cfg.LogLevel, "flag over env")
+	assert.Equal(t, 5, cfg.RetryAttempts)
+	assert.Equal(t, SourceFlag, cfg.Source("LOG_LEVEL"))
+}
+
+func TestLoadConfigFileFromEnv(t *testing.T) {
+	required(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "cache_backend: tiered\n"))
+
+	cfg, err := Load(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "tiered", cfg.CacheBackend)
+}
+
+func TestParseReportsEveryBadValue(t *testing.T) {
+	required(t)
+	t.Setenv("CACHE_TTL", "soon")
+	t.Setenv("RETRY_ATTEMPTS", "three")
+	path := writeFile(t, "cache_tll: 1m\n")
+
+	_, err := Parse([]string{"--config", path})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `CACHE_TTL (from env): "soon" is not a duration`)
+	assert.Contains(t, err.Error(), `RETRY_ATTEMPTS (from env): "three" is not an integer`)
+	assert.Contains(t, err.Error(), `unknown key "cache_tll"`)
+
+	_, err = Parse([]string{"--no-such-flag=1"})
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	t.Setenv("POSTGRES_DSN", "")
+	t.Setenv("REDIS_ADDRESS", "localhost:6379")
+	t.Setenv("CACHE_BACKEND", "disk")
+	t.Setenv("TRACE_SAMPLE_RATIO", "1.5")
+	t.Setenv("RETRY_ATTEMPTS", "0")
+	t.Setenv("DB_MAX_IDLE_CONNS", "50")
+
+	_, err := Load(nil)
+	require.Error(t, err)
+	for _, want := range []string{
+		"POSTGRES_DSN: is required",
+		`CACHE_BACKEND: "disk" is not one of redis, memory, tiered`,
+		"TRACE_SAMPLE_RATIO: 1.5 is more than 1",
+		"RETRY_ATTEMPTS: 0 is less than 1",
+		"DB_MAX_IDLE_CONNS: 50 is more than DB_MAX_OPEN_CONNS (25)",
+	} {
+		assert.Contains(t, err.Error(), want)
+	}
+}
+
+func TestPrintRedactsSecretsAndReadsBack(t *testing.T) {
+	required(t)
+	t.Setenv("JWT_HS256_SECRET", "hunter2")
+	t.Setenv("CACHE_TTL", "90s")
+
+	cfg, err := Load(nil)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	require.NoError(t, cfg.Print(&out))
+
+	assert.NotContains(t, out.String(), "s3cret")
+	assert.NotContains(t, out.String(), "hunter2")
+	assert.Contains(t, out.String(), `jwt_hs256_secret: "[REDACTED]" # env`)
+	assert.Contains(t, out.String(), `cache_ttl: "1m30s" # env`)
+	assert.Contains(t, out.String(), `redis_password: "" # default`)
+
+	// The printed file loads back to the same values, secrets aside.
+	os.Unsetenv("CACHE_TTL")
+	again, err := Load([]string{"--config", writeFile(t, out.String())})
+	require.NoError(t, err)
+	assert.Equal(t, cfg.CacheTTL, again.CacheTTL)
+	assert.Equal(t, cfg.DBConnMaxLifetime, again.DBConnMaxLifetime)
+	assert.Equal(t, cfg.DuplicateThreshold, again.DuplicateThreshold)
+}
+
+func TestReloadAppliesOnlyReloadableFields(t *testing.T) {
+	required(t)
+	path := writeFile(t, "log_level: info\ncache_ttl: 1m\n")
+	cfg, err := Load([]string{"--config", path})
+	require.NoError(t, err)
+
+	r := NewReloader(cfg, logrus.New())
+	var applied *Config
+	r.OnReload(func(next *Config) error {
+		applied = next
+		return nil
+	})
+
+	require.NoError(t, os.WriteFile(path, []byte("log_level: debug\ncache_ttl: 2m\n"), 0o600))
+	changed, err := r.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"LOG_LEVEL"}, changed)
+	require.NotNil(t, applied)
+	assert.Equal(t, "debug", applied.LogLevel)
+	assert.Equal(t, time.Minute, applied.CacheTTL, "CACHE_TTL needs a restart")
+	assert.Equal(t, "info", cfg.LogLevel, "the loaded configuration is not modified")
+
+	require.NoError(t, os.WriteFile(path, []byte("log_level: loud\n"), 0o600))
+	_, err = r.Reload()
+	assert.Error(t, err)
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources a field can be set from, in increasing precedence.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceDotEnv  = ".env"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// envConfigFile names the YAML file to load when --config is not given.
+const envConfigFile = "CONFIG_FILE"
+
+// dotEnvFile is read for variables that are not set in the environment.
+const dotEnvFile = ".env"
+
+// field is a Config field that can be set from the sources.
+type field struct {
+	index int
+	env   string
+	tag   reflect.StructTag
+}
+
+// key is the name of the field in the YAML file.
+func (f field) key() string {
+	return strings.ToLower(f.env)
+}
+
+// flag is the name of the command line flag setting the field.
+func (f field) flag() string {
+	return strings.ReplaceAll(f.key(), "_", "-")
+}
+
+var fields = func() []field {
+	t := reflect.TypeOf(Config{})
+	var out []field
+	for i := 0; i < t.NumField(); i++ {
+		if env, ok := t.Field(i).Tag.Lookup("env"); ok {
+			out = append(out, field{index: i, env: env, tag: t.Field(i).Tag})
+		}
+	}
+	return out
+}()
+
+// Load reads the configuration and validates it. args are the command
+// line arguments after the program name; see Parse.
+func Load(args []string) (*Config, error) {
+	cfg, err := Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Parse reads the configuration without validating it. Each field starts
+// at its default and is then overridden by the YAML file named by
+// --config or CONFIG_FILE, by .env, by the environment and by its flag,
+// such as --postgres-dsn for POSTGRES_DSN. Values that cannot be parsed
+// into the field's type are all reported together.
+func Parse(args []string) (*Config, error) {
+	flags, configFile, err := parseFlags(args)
+	if err != nil {
+		return nil, err
+	}
+	if configFile == "" {
+		configFile = os.Getenv(envConfigFile)
+	}
+
+	cfg := &Config{args: args, sources: map[string]string{}}
+	var errs []error
+	set := func(f field, value, source string) {
+		if err := cfg.set(f, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s (from %s): %w", f.env, source, err))
+			return
+		}
+		cfg.sources[f.env] = source
+	}
+
+	for _, f := range fields {
+		set(f, f.tag.Get("default"), SourceDefault)
+	}
+
+	if configFile != "" {
+		values, err := readFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			if v, ok := values[f.key()]; ok {
+				set(f, v, SourceFile)
+			}
+			delete(values, f.key())
+		}
+		for key := range values {
+			errs = append(errs, fmt.Errorf("%s: unknown key %q", configFile, key))
+		}
+	}
+
+	dotEnv, err := godotenv.Read(dotEnvFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", dotEnvFile, err)
+	}
+	for _, f := range fields {
+		if v, ok := os.LookupEnv(f.env); ok {
+			set(f, v, SourceEnv)
+		} else if v, ok := dotEnv[f.env]; ok {
+			set(f, v, SourceDotEnv)
+		}
+		if v, ok := flags[f.flag()]; ok {
+			set(f, v, SourceFlag)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return cfg, nil
+}
+
+// parseFlags returns the value of every flag given, by flag name, and the
+// --config file.
+func parseFlags(args []string) (map[string]string, string, error) {
+	fs := flag.NewFlagSet("simple-product-api", flag.ContinueOnError)
+	values := map[string]string{}
+	configFile := fs.String("config", "", "YAML configuration file (default $"+envConfigFile+")")
+	for _, f := range fields {
+		name := f.flag()
+		fs.Func(name, "overrides $"+f.env, func(v string) error {
+			values[name] = v
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, "", err
+	}
+	if fs.NArg() > 0 {
+		return nil, "", fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	return values, *configFile, nil
+}
+
+// readFile reads a flat YAML mapping of keys to scalars.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	var doc map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	values := make(map[string]string, len(doc))
+	for key, node := range doc {
+		if node.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("%s: %s is not a single value", path, key)
+		}
+		values[key] = node.Value
+	}
+	return values, nil
+}
+
+// set parses value into the field's type.
+func (c *Config) set(f field, value string) error {
+	v := reflect.ValueOf(c).Elem().Field(f.index)
+	parsed, err := parse(v.Type(), value)
+	if err != nil {
+		return err
+	}
+	v.Set(parsed)
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func parse(t reflect.Type, value string) (reflect.Value, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case t == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%q is not a duration", value)
+		}
+		return reflect.ValueOf(d), nil
+	case t.Kind() == reflect.String:
+		return reflect.ValueOf(value), nil
+	case t.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%q is not an integer", value)
+		}
+		return reflect.ValueOf(n), nil
+	case t.Kind() == reflect.Float64:
+		x, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%q is not a number", value)
+		}
+		return reflect.ValueOf(x), nil
+	}
+	panic("config: unsupported field type " + t.String())
+}
+
+// Source reports where the field named by its environment variable was
+// set from.
+func (c *Config) Source(env string) string {
+	return c.sources[env]
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[REDACTED]"
+
+// Print writes the configuration as a YAML file that Parse can read back,
+// with the source of each value as a comment. Secrets that are set are
+// replaced by [REDACTED].
+func (c *Config) Print(w io.Writer) error {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	cfg := reflect.ValueOf(c).Elem()
+	for _, f := range fields {
+		value := fmt.Sprint(cfg.Field(f.index).Interface())
+		if f.tag.Get("secret") == "true" && value != "" {
+			value = redacted
+		}
+		doc.Content = append(doc.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: f.key()},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: value, Style: yaml.DoubleQuotedStyle, LineComment: c.Source(f.env)},
+		)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
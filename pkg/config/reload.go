@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Reloader loads the configuration again on SIGHUP. Only the fields tagged
+// reload are handed on; the others are read once at startup, and changes
+// to them are logged and ignored until the next restart.
+type Reloader struct {
+	log *logrus.Logger
+
+	mu      sync.Mutex
+	current *Config
+	apply   []func(*Config) error
+}
+
+func NewReloader(cfg *Config, log *logrus.Logger) *Reloader {
+	return &Reloader{log: log, current: cfg}
+}
+
+// OnReload registers fn to apply a reloaded configuration. fn should
+// check the whole configuration before applying any of it: an error stops
+// the reload, and the configuration is only replaced once every fn has
+// succeeded.
+func (r *Reloader) OnReload(fn func(*Config) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.apply = append(r.apply, fn)
+}
+
+// Reload loads the configuration from the same file, environment and
+// flags as before and applies the fields that can be reloaded. It returns
+// the names of the fields that changed, by environment variable.
+func (r *Reloader) Reload() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	loaded, err := Load(r.current.args)
+	if err != nil {
+		return nil, err
+	}
+
+	next := *r.current
+	next.sources = make(map[string]string, len(r.current.sources))
+	for env, source := range r.current.sources {
+		next.sources[env] = source
+	}
+	cur, dst, src := reflect.ValueOf(r.current).Elem(), reflect.ValueOf(&next).Elem(), reflect.ValueOf(loaded).Elem()
+	var changed []string
+	for _, f := range fields {
+		if reflect.DeepEqual(cur.Field(f.index).Interface(), src.Field(f.index).Interface()) {
+			continue
+		}
+		if f.tag.Get("reload") != "true" {
+			r.log.WithField("setting", f.env).Warn("setting changed but needs a restart to take effect")
+			continue
+		}
+		dst.Field(f.index).Set(src.Field(f.index))
+		next.sources[f.env] = loaded.sources[f.env]
+		changed = append(changed, f.env)
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	for _, fn := range r.apply {
+		if err := fn(&next); err != nil {
+			return nil, err
+		}
+	}
+	r.current = &next
+	return changed, nil
+}
+
+// Watch reloads on every SIGHUP until ctx is done.
+func (r *Reloader) Watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			changed, err := r.Reload()
+			if err != nil {
+				r.log.WithError(err).Error("failed to reload configuration, keeping the previous one")
+				continue
+			}
+			r.log.WithField("changed", changed).Info("configuration reloaded")
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Validate reports every required field left empty and every value
+// outside its oneof, min or max bounds.
+func (c *Config) Validate() error {
+	var errs []error
+	cfg := reflect.ValueOf(c).Elem()
+	for _, f := range fields {
+		v := cfg.Field(f.index)
+		if err := check(f, v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.env, err))
+		}
+	}
+	if c.DBMaxIdleConns > c.DBMaxOpenConns {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS: %d is more than DB_MAX_OPEN_CONNS (%d)", c.DBMaxIdleConns, c.DBMaxOpenConns))
+	}
+	if c.RetryBaseDelay > c.RetryMaxDelay {
+		errs = append(errs, fmt.Errorf("RETRY_BASE_DELAY: %v is longer than RETRY_MAX_DELAY (%v)", c.RetryBaseDelay, c.RetryMaxDelay))
+	}
+	return errors.Join(errs...)
+}
+
+func check(f field, v reflect.Value) error {
+	if v.IsZero() {
+		if f.tag.Get("required") == "true" {
+			return errors.New("is required")
+		}
+		if v.Kind() == reflect.String {
+			return nil
+		}
+	}
+
+	if oneof, ok := f.tag.Lookup("oneof"); ok {
+		allowed := strings.Fields(oneof)
+		if !slices.Contains(allowed, v.String()) {
+			return fmt.Errorf("%q is not one of %s", v.String(), strings.Join(allowed, ", "))
+		}
+	}
+	if lo, ok := f.tag.Lookup("min"); ok {
+		if bound, _ := parse(v.Type(), lo); less(v, bound) {
+			return fmt.Errorf("%v is less than %v", v, bound)
+		}
+	}
+	if hi, ok := f.tag.Lookup("max"); ok {
+		if bound, _ := parse(v.Type(), hi); less(bound, v) {
+			return fmt.Errorf("%v is more than %v", v, bound)
+		}
+	}
+	return nil
+}
+
+// less compares two numbers or durations of the same type.
+func less(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Float64 {
+		return a.Float() < b.Float()
+	}
+	return a.Int() < b.Int()
+}
@@ -1,25 +1,108 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"os"
+	"path/filepath"
+	"sort"
 )
 
+const migrationsDir = "migrations"
+
+// Migrate applies every script in migrationsDir that has not been recorded
+// in schema_migrations yet, in file name order.
 func Migrate(db *sql.DB) error {
-	var exists bool
-	err := db.QueryRow(`SELECT EXISTS (
-		SELECT 1 FROM information_schema.tables 
-		WHERE table_name = 'products'
-	)`).Scan(&exists)
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version TEXT PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`)
+	if err != nil {
+		return err
+	}
+
+	files, err := migrationFiles()
+	if err != nil {
+		return err
+	}
 
-	if err != nil || exists {
-		return nil
+	for _, file := range files {
+		version := filepath.Base(file)
+
+		var applied bool
+		err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+
+		script, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := apply(db, version, string(script)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pending returns the versions in migrationsDir that have not been applied.
+func Pending(ctx context.Context, db *sql.DB) ([]string, error) {
+	files, err := migrationFiles()
+	if err != nil {
+		return nil, err
 	}
 
-	script, err := os.ReadFile("migrations/001_create_products.sql")
+	rows, err := db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
 	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, file := range files {
+		if version := filepath.Base(file); !applied[version] {
+			pending = append(pending, version)
+		}
+	}
+	return pending, nil
+}
+
+func migrationFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(migrationsDir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func apply(db *sql.DB, version, script string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(script); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
 		return err
 	}
-	_, err = db.Exec(string(script))
-	return err
+	return tx.Commit()
 }
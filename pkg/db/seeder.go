@@ -6,8 +6,12 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"simple-product-api/pkg/fuzzy"
+	"simple-product-api/pkg/tenant"
 )
 
+// SeedProducts inserts the sample catalog into the default tenant, skipping products that already
+// exist so that restarting the service does not duplicate them.
 func SeedProducts(db *sql.DB) error {
 	products := []struct {
 		Name  string
@@ -22,9 +26,12 @@ func SeedProducts(db *sql.DB) error {
 
 	for _, p := range products {
 		_, err := db.ExecContext(context.Background(), `
-			INSERT INTO products (id, name, type, price, created_at)
-			VALUES ($1, $2, $3, $4, $5)
-		`, uuid.New().String(), p.Name, p.Type, p.Price, time.Now())
+			INSERT INTO products (id, name, type, price, status, created_at, tenant_id, search_name)
+			SELECT $1::uuid, $2::text, $3::text, $4::numeric, 'active', $5::timestamp, $6::text, $7::text
+			WHERE NOT EXISTS (
+				SELECT 1 FROM products WHERE search_name = $7::text AND LOWER(type) = LOWER($3::text) AND tenant_id = $6::text
+			)
+		`, uuid.Must(uuid.NewV7()).String(), p.Name, p.Type, p.Price, time.Now(), tenant.Default, fuzzy.Normalize(p.Name))
 		if err != nil {
 			return err
 		}
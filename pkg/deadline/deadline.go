@@ -0,0 +1,24 @@
+// Package deadline bounds single operations on a dependency so that one
+// slow call cannot use up the whole request deadline.
+package deadline
+
+import (
+	"context"
+	"time"
+)
+
+// With returns ctx limited to d, or limited only by ctx itself when d is
+// not positive. An earlier deadline already on ctx is kept.
+func With(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// Detach returns a context for work that must finish even when the request
+// that started it is canceled, such as cache writes and invalidation. It
+// keeps the values of ctx, drops its cancellation and is limited to d.
+func Detach(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return With(context.WithoutCancel(ctx), d)
+}
@@ -0,0 +1,60 @@
+package di
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	apikeyHttp "simple-product-api/internal/apikey/delivery/http"
+	apikeyUsecase "simple-product-api/internal/apikey/usecase"
+	productHttp "simple-product-api/internal/product/delivery/http"
+	productUsecase "simple-product-api/internal/product/usecase"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/health"
+	"simple-product-api/pkg/idempotency"
+	middleware "simple-product-api/pkg/midlleware"
+	"simple-product-api/pkg/ratelimit"
+	"simple-product-api/pkg/tracing"
+)
+
+// App holds everything main needs to mount the API and to shut it down.
+type App struct {
+	Products *productHttp.Handler
+	APIKeys  *apikeyHttp.Handler
+	Health   *health.Handler
+	Checker  *health.Checker
+	Keys     apikeyUsecase.APIKeyUsecase
+	Usecase  *productUsecase.Usecase
+	Warmer   *productUsecase.Warmer
+	Verifier *auth.Verifier
+	Limiter  *ratelimit.Limiter
+	Limits   *ratelimit.Rules
+	Idem     *idempotency.Store
+	Timeouts *middleware.Timeouts
+	Tracing  *tracing.Provider
+	Reloader *config.Reloader
+	DB       *sql.DB
+	Redis    *redis.Client
+	Log      *logrus.Logger
+}
+
+// Shutdown stops the background workers, waits for the cache writes left
+// by earlier requests, exports the remaining spans and then closes
+// Postgres and Redis. Call it once the server has stopped taking requests.
+func (a *App) Shutdown(ctx context.Context) error {
+	errs := []error{
+		a.Warmer.Close(),
+		a.Usecase.Known.Close(),
+		a.Usecase.Popular.Close(),
+		a.Usecase.Drain(ctx),
+	}
+	if c, ok := a.Usecase.Cache.(io.Closer); ok {
+		errs = append(errs, c.Close())
+	}
+	errs = append(errs, a.Tracing.Close(), a.DB.Close(), a.Redis.Close())
+	return errors.Join(errs...)
+}
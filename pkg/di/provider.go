@@ -1,13 +1,79 @@
 package di
 
 import (
+	"context"
 	"database/sql"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
+	"simple-product-api/pkg/breaker"
 	"simple-product-api/pkg/config"
 	"simple-product-api/pkg/db"
+	"simple-product-api/pkg/health"
+	"simple-product-api/pkg/idempotency"
+	"simple-product-api/pkg/metrics"
+	middleware "simple-product-api/pkg/midlleware"
+	"simple-product-api/pkg/ratelimit"
 )
 
 func ProvidePostgres(cfg *config.Config, log *logrus.Logger) (*sql.DB, error) {
 	log.Infof("Connecting to PostgreSQL: %s", cfg.PostgresDSN)
-	return db.NewPostgres(cfg)
+	conn, err := db.NewPostgres(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := metrics.RegisterDB("postgres", conn); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+func ProvideIdempotencyStore(cfg *config.Config, rdb *redis.Client) *idempotency.Store {
+	return idempotency.NewStore(rdb, cfg.IdempotencyTTL, cfg.IdempotencyLockTTL)
+}
+
+func ProvidePostgresBreaker(cfg *config.Config, log *logrus.Logger, breakers *breaker.Group) *breaker.Breaker {
+	settings := breaker.FromConfig(cfg, "postgres")
+	settings.OnStateChange = breaker.LogTransitions(log)
+	return breakers.Add(breaker.New(settings))
+}
+
+// ProvideHealth checks Postgres, its migrations and breaker as critical
+// dependencies. Redis is not critical: the cache, rate limiter and
+// idempotency store all fall back to running without it.
+func ProvideHealth(cfg *config.Config, conn *sql.DB, rdb *redis.Client, postgres *breaker.Breaker, breakers *breaker.Group) *health.Checker {
+	checker := health.New(cfg.HealthCheckTimeout)
+	checker.Add(health.Check{Name: "postgres", Critical: true, Run: conn.PingContext})
+	checker.Add(health.Check{Name: "migrations", Critical: true, Run: health.Migrations(conn)})
+	checker.Add(health.Check{Name: "redis", Run: func(ctx context.Context) error {
+		return rdb.Ping(ctx).Err()
+	}})
+	for _, b := range breakers.Breakers() {
+		checker.Add(health.Breaker(b, b == postgres))
+	}
+	return checker
+}
+
+// ProvideReloader applies reloaded log levels, rate limits and request
+// timeouts, once all of them have parsed.
+func ProvideReloader(cfg *config.Config, log *logrus.Logger, rules *ratelimit.Rules, timeouts *middleware.Timeouts) *config.Reloader {
+	r := config.NewReloader(cfg, log)
+	r.OnReload(func(next *config.Config) error {
+		level, err := logrus.ParseLevel(next.LogLevel)
+		if err != nil {
+			return err
+		}
+		nextRules, err := ratelimit.NewRules(next)
+		if err != nil {
+			return err
+		}
+		nextTimeouts, err := middleware.NewTimeouts(next)
+		if err != nil {
+			return err
+		}
+		log.SetLevel(level)
+		rules.Update(nextRules)
+		timeouts.Update(nextTimeouts)
+		return nil
+	})
+	return r
 }
@@ -6,29 +6,69 @@ package di
 import (
 	"github.com/google/wire"
 	_ "github.com/lib/pq"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/breaker"
+	"simple-product-api/pkg/cache"
+	"simple-product-api/pkg/health"
 	"simple-product-api/pkg/logger"
+	middleware "simple-product-api/pkg/midlleware"
+	"simple-product-api/pkg/policy"
+	"simple-product-api/pkg/ratelimit"
 	"simple-product-api/pkg/redis"
+	"simple-product-api/pkg/retry"
+	"simple-product-api/pkg/tracing"
 
+	apikeyHttp "simple-product-api/internal/apikey/delivery/http"
+	apikeyRepository "simple-product-api/internal/apikey/repository"
+	apikeyUsecase "simple-product-api/internal/apikey/usecase"
 	httpHandler "simple-product-api/internal/product/delivery/http"
 	"simple-product-api/internal/product/repository"
 	"simple-product-api/internal/product/usecase"
 	"simple-product-api/pkg/config"
 )
 
-func InitializeHandler(cfg *config.Config) (*httpHandler.Handler, error) {
+func InitializeApp(cfg *config.Config) (*App, error) {
 	wire.Build(
 		ProvidePostgres,
+		ProvideIdempotencyStore,
+		ProvidePostgresBreaker,
+		ProvideHealth,
+		ProvideReloader,
+		breaker.NewGroup,
+		health.NewHandler,
+		retry.FromConfig,
 
 		repository.NewPostgresRepo,
 		wire.Bind(new(repository.ProductRepository), new(*repository.RepositoryPostgre)),
 
+		usecase.NewKnownIDs,
+		usecase.NewPopularity,
+		usecase.NewWarmer,
+		usecase.NewCacheCodec,
 		usecase.NewUsecase,
-		wire.Bind(new(usecase.ProductUsecase), new(*usecase.Usecase)),
+		usecase.NewTraced,
 
 		httpHandler.NewHandler,
+
+		apikeyRepository.NewPostgresRepo,
+		wire.Bind(new(apikeyRepository.APIKeyRepository), new(*apikeyRepository.RepositoryPostgre)),
+
+		apikeyUsecase.NewUsecase,
+		wire.Bind(new(apikeyUsecase.APIKeyUsecase), new(*apikeyUsecase.Usecase)),
+
+		apikeyHttp.NewHandler,
+
+		auth.NewVerifier,
+		policy.Load,
+		ratelimit.NewLimiter,
+		ratelimit.NewRules,
+		middleware.NewTimeouts,
 		redis.NewRedis,
+		tracing.New,
+		cache.New,
 
 		logger.NewLogger,
+		wire.Struct(new(App), "*"),
 	)
-	return &httpHandler.Handler{}, nil
+	return &App{}, nil
 }
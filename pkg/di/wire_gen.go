@@ -7,12 +7,24 @@
 package di
 
 import (
+	http2 "simple-product-api/internal/apikey/delivery/http"
+	repository2 "simple-product-api/internal/apikey/repository"
+	usecase2 "simple-product-api/internal/apikey/usecase"
 	"simple-product-api/internal/product/delivery/http"
 	"simple-product-api/internal/product/repository"
 	"simple-product-api/internal/product/usecase"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/breaker"
+	"simple-product-api/pkg/cache"
 	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/health"
 	"simple-product-api/pkg/logger"
+	"simple-product-api/pkg/midlleware"
+	"simple-product-api/pkg/policy"
+	"simple-product-api/pkg/ratelimit"
 	"simple-product-api/pkg/redis"
+	"simple-product-api/pkg/retry"
+	"simple-product-api/pkg/tracing"
 )
 
 import (
@@ -21,15 +33,80 @@ import (
 
 // Injectors from wire.go:
 
-func InitializeHandler(cfg *config.Config) (*http.Handler, error) {
-	logrusLogger := logger.NewLogger()
+func InitializeApp(cfg *config.Config) (*App, error) {
+	logrusLogger, err := logger.NewLogger(cfg)
+	if err != nil {
+		return nil, err
+	}
 	db, err := ProvidePostgres(cfg, logrusLogger)
 	if err != nil {
 		return nil, err
 	}
-	repositoryPostgre := repository.NewPostgresRepo(db, logrusLogger)
-	client := redis.NewRedis(cfg)
-	usecaseUsecase := usecase.NewUsecase(repositoryPostgre, client, logrusLogger)
-	handler := http.NewHandler(usecaseUsecase, logrusLogger)
-	return handler, nil
+	retrier := retry.FromConfig(cfg)
+	group := breaker.NewGroup()
+	breakerBreaker := ProvidePostgresBreaker(cfg, logrusLogger, group)
+	repositoryPostgre := repository.NewPostgresRepo(db, logrusLogger, retrier, breakerBreaker, cfg)
+	client := redis.NewRedis(cfg, logrusLogger, group)
+	cacheCache, err := cache.New(cfg, client, logrusLogger)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := usecase.NewCacheCodec(cfg)
+	if err != nil {
+		return nil, err
+	}
+	knownIDs := usecase.NewKnownIDs(cfg, repositoryPostgre, client, logrusLogger)
+	popularity := usecase.NewPopularity(cfg, client, logrusLogger)
+	policyPolicy, err := policy.Load(cfg)
+	if err != nil {
+		return nil, err
+	}
+	usecaseUsecase := usecase.NewUsecase(repositoryPostgre, cacheCache, codec, knownIDs, popularity, logrusLogger, cfg, policyPolicy)
+	productUsecase := usecase.NewTraced(usecaseUsecase)
+	handler := http.NewHandler(productUsecase, logrusLogger)
+	repositoryRepositoryPostgre := repository2.NewPostgresRepo(db, logrusLogger, retrier, breakerBreaker, cfg)
+	usecase3 := usecase2.NewUsecase(repositoryRepositoryPostgre, logrusLogger)
+	httpHandler := http2.NewHandler(usecase3, logrusLogger)
+	checker := ProvideHealth(cfg, db, client, breakerBreaker, group)
+	healthHandler := health.NewHandler(checker)
+	warmer := usecase.NewWarmer(usecaseUsecase)
+	verifier, err := auth.NewVerifier(cfg)
+	if err != nil {
+		return nil, err
+	}
+	limiter := ratelimit.NewLimiter(client)
+	rules, err := ratelimit.NewRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	store := ProvideIdempotencyStore(cfg, client)
+	timeouts, err := middleware.NewTimeouts(cfg)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := tracing.New(cfg, logrusLogger)
+	if err != nil {
+		return nil, err
+	}
+	reloader := ProvideReloader(cfg, logrusLogger, rules, timeouts)
+	app := &App{
+		Products: handler,
+		APIKeys:  httpHandler,
+		Health:   healthHandler,
+		Checker:  checker,
+		Keys:     usecase3,
+		Usecase:  usecaseUsecase,
+		Warmer:   warmer,
+		Verifier: verifier,
+		Limiter:  limiter,
+		Limits:   rules,
+		Idem:     store,
+		Timeouts: timeouts,
+		Tracing:  provider,
+		Reloader: reloader,
+		DB:       db,
+		Redis:    client,
+		Log:      logrusLogger,
+	}
+	return app, nil
 }
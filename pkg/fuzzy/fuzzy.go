@@ -0,0 +1,102 @@
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize lowercases s, strips diacritics, trims it and collapses runs of
+// whitespace, so that "  Crème  Brûlée" and "creme brulee" compare equal.
+func Normalize(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	stripped, _, err := transform.String(t, s)
+	if err != nil {
+		stripped = s
+	}
+	return strings.Join(strings.Fields(strings.ToLower(stripped)), " ")
+}
+
+// Similarity scores two strings between 0 and 1 after normalizing them. It
+// takes the better of the trigram and edit-distance scores: trigrams are
+// forgiving of reordered words, edit distance of single-letter typos in
+// short names.
+func Similarity(a, b string) float64 {
+	a, b = Normalize(a), Normalize(b)
+	if a == b {
+		return 1
+	}
+	lev := LevenshteinRatio(a, b)
+	tri := TrigramSimilarity(a, b)
+	if tri > lev {
+		return tri
+	}
+	return lev
+}
+
+// LevenshteinRatio is 1 minus the edit distance divided by the length of
+// the longer string.
+func LevenshteinRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(Levenshtein(a, b))/float64(longest)
+}
+
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// TrigramSimilarity is the Jaccard index of the two strings' trigram sets,
+// padded the same way as PostgreSQL's pg_trgm.
+func TrigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 && len(tb) == 0 {
+		return 1
+	}
+
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(s) {
+		r := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			set[string(r[i:i+3])] = true
+		}
+	}
+	return set
+}
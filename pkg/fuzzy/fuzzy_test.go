@@ -0,0 +1,24 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "chicken breast", Normalize("  Chicken \t Breast "))
+	assert.Equal(t, "creme brulee", Normalize("Crème Brûlée"))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, Levenshtein("apple", "apple"))
+	assert.Equal(t, 1, Levenshtein("chicken", "chiken"))
+	assert.Equal(t, 3, Levenshtein("kitten", "sitting"))
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, float64(1), Similarity("Chicken Breast", "chicken  breast "))
+	assert.Greater(t, Similarity("Chicken Breast", "Chiken Breast "), 0.9)
+	assert.Less(t, Similarity("Chicken Breast", "Apple"), 0.3)
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"simple-product-api/pkg/common"
+)
+
+type Handler struct {
+	checker *Checker
+}
+
+func NewHandler(checker *Checker) *Handler {
+	return &Handler{checker: checker}
+}
+
+// Register mounts /healthz and /readyz on r.
+func (h *Handler) Register(r fiber.Router) {
+	r.Get("/healthz", h.Live)
+	r.Get("/readyz", h.Ready)
+}
+
+// Live godoc
+// @Summary Liveness probe
+// @Description Answers 200 while the process can serve HTTP, without checking dependencies
+// @Tags Health
+// @Success 200
+// @Router /healthz [get]
+func (h *Handler) Live(c *fiber.Ctx) error {
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// Ready godoc
+// @Summary Readiness probe
+// @Description Checks Postgres, Redis, pending migrations and circuit breakers. Answers 200 when ready or degraded and 503 when a critical check fails, with only the status text unless verbose is given
+// @Tags Health
+// @Produce  json
+// @Param verbose query bool false "Include the report of every check"
+// @Success 200 {object} common.Response{data=Report}
+// @Failure 503 {object} common.Response{data=Report}
+// @Router /readyz [get]
+func (h *Handler) Ready(c *fiber.Ctx) error {
+	report := h.checker.Run(c.UserContext())
+	code := fiber.StatusOK
+	if !report.Ready() {
+		code = fiber.StatusServiceUnavailable
+	}
+	if !c.Context().QueryArgs().Has("verbose") {
+		return c.SendStatus(code)
+	}
+	return c.Status(code).JSON(common.Response{
+		Code:    code,
+		Message: string(report.Status),
+		Data:    report,
+	})
+}
@@ -0,0 +1,161 @@
+// Package health reports whether the service is alive and whether the
+// dependencies it needs to serve requests are reachable.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"simple-product-api/pkg/breaker"
+	"simple-product-api/pkg/db"
+	"simple-product-api/pkg/logger"
+)
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+	// StatusDegraded is reported when only checks that are not critical
+	// fail: the service still serves requests, with less caching or
+	// protection.
+	StatusDegraded Status = "degraded"
+)
+
+// Check is one dependency check.
+type Check struct {
+	Name string
+	// Critical checks make the service not ready when they fail; the
+	// others only degrade it.
+	Critical bool
+	Run      func(ctx context.Context) error
+}
+
+type Result struct {
+	Name      string  `json:"name"`
+	Status    Status  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status    Status    `json:"status"`
+	CheckedAt time.Time `json:"checked_at"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Ready reports whether the service should receive traffic.
+func (r Report) Ready() bool {
+	return r.Status != StatusDown
+}
+
+// Checker runs the readiness checks concurrently, each bounded by its own
+// timeout.
+type Checker struct {
+	timeout  time.Duration
+	checks   []Check
+	draining atomic.Bool
+}
+
+func New(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+func (c *Checker) Add(check Check) {
+	c.checks = append(c.checks, check)
+}
+
+// Drain makes the service report itself not ready from now on, so that
+// load balancers stop sending it requests before it shuts down.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+func (c *Checker) Run(ctx context.Context) Report {
+	if c.draining.Load() {
+		return Report{
+			Status:    StatusDown,
+			CheckedAt: time.Now().UTC(),
+			Checks:    []Result{{Name: "shutdown", Status: StatusDown, Critical: true, Error: "shutting down"}},
+		}
+	}
+
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, CheckedAt: time.Now().UTC(), Checks: results}
+	for _, r := range results {
+		if r.Status == StatusUp {
+			continue
+		}
+		if r.Critical {
+			report.Status = StatusDown
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	result := Result{
+		Name:      check.Name,
+		Status:    StatusUp,
+		Critical:  check.Critical,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		// Driver errors can quote connection strings.
+		result.Error = logger.Redact(err.Error())
+	}
+	return result
+}
+
+// Migrations fails while migration scripts are waiting to be applied.
+func Migrations(conn *sql.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		pending, err := db.Pending(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d pending: %s", len(pending), strings.Join(pending, ", "))
+		}
+		return nil
+	}
+}
+
+// Breaker fails while b is open. A half-open breaker passes, since it is
+// already letting trial calls through.
+func Breaker(b *breaker.Breaker, critical bool) Check {
+	return Check{
+		Name:     "breaker:" + b.Name(),
+		Critical: critical,
+		Run: func(context.Context) error {
+			if b.State() == breaker.Open {
+				return breaker.ErrOpen
+			}
+			return nil
+		},
+	}
+}
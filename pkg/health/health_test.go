@@ -0,0 +1,133 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-product-api/pkg/breaker"
+)
+
+func up(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("connection refused") }
+
+func TestRunAggregatesChecks(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []Check
+		want   Status
+	}{
+		{"all up", []Check{{Name: "postgres", Critical: true, Run: up}, {Name: "redis", Run: up}}, StatusUp},
+		{"optional down", []Check{{Name: "postgres", Critical: true, Run: up}, {Name: "redis", Run: down}}, StatusDegraded},
+		{"critical down", []Check{{Name: "postgres", Critical: true, Run: down}, {Name: "redis", Run: down}}, StatusDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := New(time.Second)
+			for _, c := range tt.checks {
+				checker.Add(c)
+			}
+			report := checker.Run(context.Background())
+			assert.Equal(t, tt.want, report.Status)
+			require.Len(t, report.Checks, len(tt.checks))
+			assert.Equal(t, tt.checks[0].Name, report.Checks[0].Name)
+		})
+	}
+}
+
+func TestRunBoundsEachCheck(t *testing.T) {
+	checker := New(20 * time.Millisecond)
+	checker.Add(Check{Name: "slow", Critical: true, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	checker.Add(Check{Name: "fast", Run: up})
+
+	start := time.Now()
+	report := checker.Run(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+	assert.Equal(t, StatusUp, report.Checks[1].Status)
+}
+
+func TestDrainFailsReadiness(t *testing.T) {
+	checker := New(time.Second)
+	checker.Add(Check{Name: "postgres", Critical: true, Run: up})
+	assert.True(t, checker.Run(context.Background()).Ready())
+
+	checker.Drain()
+	report := checker.Run(context.Background())
+	assert.False(t, report.Ready())
+	assert.Equal(t, "shutdown", report.Checks[0].Name)
+}
+
+func TestRunRedactsErrors(t *testing.T) {
+	checker := New(time.Second)
+	checker.Add(Check{Name: "postgres", Run: func(context.Context) error {
+		return errors.New("dial postgres://app:s3cret@db:5432/app")
+	}})
+	report := checker.Run(context.Background())
+	assert.NotContains(t, report.Checks[0].Error, "s3cret")
+}
+
+func TestBreakerCheck(t *testing.T) {
+	b := breaker.New(breaker.Settings{Name: "postgres", FailureThreshold: 1, OpenTimeout: time.Minute})
+	check := Breaker(b, true)
+	assert.Equal(t, "breaker:postgres", check.Name)
+	assert.NoError(t, check.Run(context.Background()))
+
+	_ = b.Do(func(error) bool { return true }, func() error { return errors.New("timeout") })
+	assert.ErrorIs(t, check.Run(context.Background()), breaker.ErrOpen)
+}
+
+func TestHandler(t *testing.T) {
+	healthy := true
+	checker := New(time.Second)
+	checker.Add(Check{Name: "postgres", Critical: true, Run: func(context.Context) error {
+		if !healthy {
+			return errors.New("connection refused")
+		}
+		return nil
+	}})
+	app := fiber.New()
+	NewHandler(checker).Register(app)
+
+	get := func(path string) (int, string) {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get("/readyz")
+	assert.Equal(t, fiber.StatusOK, code)
+	assert.Equal(t, "OK", body)
+
+	healthy = false
+	code, body = get("/readyz")
+	assert.Equal(t, fiber.StatusServiceUnavailable, code)
+	assert.Equal(t, "Service Unavailable", body)
+
+	code, body = get("/readyz?verbose")
+	assert.Equal(t, fiber.StatusServiceUnavailable, code)
+	var resp struct {
+		Message string `json:"message"`
+		Data    Report `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	assert.Equal(t, string(StatusDown), resp.Message)
+	assert.Equal(t, "connection refused", resp.Data.Checks[0].Error)
+
+	code, _ = get("/healthz")
+	assert.Equal(t, fiber.StatusOK, code, "liveness does not depend on dependencies")
+}
@@ -0,0 +1,140 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	StateInFlight = "in_flight"
+	StateDone     = "done"
+)
+
+// ErrNotOwner is returned when a request completes or releases a key whose
+// claim expired and was taken over by a retry.
+var ErrNotOwner = errors.New("idempotency key is no longer claimed by this request")
+
+// completeScript replaces the record in KEYS[1] with ARGV[2] for ARGV[3]
+// milliseconds, and releaseScript deletes it, only while the record is the
+// claim made with token ARGV[1].
+var (
+	completeScript = redis.NewScript(`
+local raw = redis.call("GET", KEYS[1])
+if not raw or cjson.decode(raw).token ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1`)
+
+	releaseScript = redis.NewScript(`
+local raw = redis.call("GET", KEYS[1])
+if not raw or cjson.decode(raw).token ~= ARGV[1] then
+	return 0
+end
+return redis.call("DEL", KEYS[1])`)
+)
+
+// Record is what is kept per idempotency key: the fingerprint of the first
+// request, the token of its claim while it runs and, once it has finished,
+// the response to replay.
+type Record struct {
+	State       string `json:"state"`
+	Fingerprint string `json:"fingerprint"`
+	Token       string `json:"token,omitempty"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Store keeps idempotency records in Redis.
+type Store struct {
+	rdb     *redis.Client
+	ttl     time.Duration
+	lockTTL time.Duration
+}
+
+// NewStore keeps completed responses for ttl. An in-flight claim expires
+// after lockTTL so that a request whose replica died can be retried.
+func NewStore(rdb *redis.Client, ttl, lockTTL time.Duration) *Store {
+	return &Store{rdb: rdb, ttl: ttl, lockTTL: lockTTL}
+}
+
+// Claim marks key as in flight for the request with the given fingerprint
+// and returns the token that Complete and Release need. When the key is
+// already taken it returns an empty token with the existing record.
+func (s *Store) Claim(ctx context.Context, key, fingerprint string) (string, *Record, error) {
+	token := uuid.NewString()
+	data, err := json.Marshal(Record{State: StateInFlight, Fingerprint: fingerprint, Token: token})
+	if err != nil {
+		return "", nil, err
+	}
+
+	ok, err := s.rdb.SetNX(ctx, redisKey(key), data, s.lockTTL).Result()
+	if err != nil {
+		return "", nil, err
+	}
+	if ok {
+		return token, nil, nil
+	}
+
+	raw, err := s.rdb.Get(ctx, redisKey(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// The claim expired between SETNX and GET; try once more.
+		ok, err = s.rdb.SetNX(ctx, redisKey(key), data, s.lockTTL).Result()
+		if err != nil || !ok {
+			return "", &Record{State: StateInFlight, Fingerprint: fingerprint}, err
+		}
+		return token, nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	var rec Record
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return "", nil, err
+	}
+	rec.Token = ""
+	return "", &rec, nil
+}
+
+// Complete stores the response for replay, provided the claim with token
+// is still held. It returns ErrNotOwner when it is not.
+func (s *Store) Complete(ctx context.Context, key, token string, rec Record) error {
+	rec.State = StateDone
+	rec.Token = ""
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	n, err := completeScript.Run(ctx, s.rdb, []string{redisKey(key)}, token, data, s.ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// Release drops the claim with token so that the request can be retried.
+// It returns ErrNotOwner when the claim is no longer held.
+func (s *Store) Release(ctx context.Context, key, token string) error {
+	n, err := releaseScript.Run(ctx, s.rdb, []string{redisKey(key)}, token).Int()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+func redisKey(key string) string {
+	return "idempotency:" + key
+}
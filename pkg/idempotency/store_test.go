@@ -0,0 +1,56 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiredClaimCannotTouchRetry(t *testing.T) {
+	ctx := context.Background()
+	m := miniredis.RunT(t)
+	store := NewStore(redis.NewClient(&redis.Options{Addr: m.Addr()}), time.Hour, time.Minute)
+
+	first, _, err := store.Claim(ctx, "k1", "fp")
+	require.NoError(t, err)
+	require.NotEmpty(t, first)
+
+	// The first request outlives its claim and a retry takes the key over.
+	m.FastForward(2 * time.Minute)
+	second, _, err := store.Claim(ctx, "k1", "fp")
+	require.NoError(t, err)
+	require.NotEmpty(t, second)
+
+	assert.ErrorIs(t, store.Complete(ctx, "k1", first, Record{Fingerprint: "fp", Status: 201}), ErrNotOwner)
+	assert.ErrorIs(t, store.Release(ctx, "k1", first), ErrNotOwner)
+
+	token, rec, err := store.Claim(ctx, "k1", "fp")
+	require.NoError(t, err)
+	assert.Empty(t, token)
+	assert.Equal(t, StateInFlight, rec.State)
+	assert.Empty(t, rec.Token)
+
+	require.NoError(t, store.Complete(ctx, "k1", second, Record{Fingerprint: "fp", Status: 201}))
+	_, rec, err = store.Claim(ctx, "k1", "fp")
+	require.NoError(t, err)
+	assert.Equal(t, StateDone, rec.State)
+	assert.Equal(t, 201, rec.Status)
+}
+
+func TestReleaseFreesKey(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}), time.Hour, time.Minute)
+
+	token, _, err := store.Claim(ctx, "k1", "fp")
+	require.NoError(t, err)
+	require.NoError(t, store.Release(ctx, "k1", token))
+
+	token, _, err = store.Claim(ctx, "k1", "fp")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/tenant"
+)
+
+// Request describes the request a context belongs to.
+type Request struct {
+	ID string
+	// Route is the template of the matched route, such as
+	// "/api/v1/products/:id", or empty when no route matched.
+	Route string
+}
+
+type requestKey struct{}
+
+func WithRequest(ctx context.Context, r *Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// RequestFromContext returns the request of ctx, or nil outside requests.
+func RequestFromContext(ctx context.Context) *Request {
+	r, _ := ctx.Value(requestKey{}).(*Request)
+	return r
+}
+
+// ContextHook adds request_id, route, tenant and user to entries logged
+// with a request context.
+type ContextHook struct{}
+
+func (ContextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (ContextHook) Fire(e *logrus.Entry) error {
+	if e.Context == nil {
+		return nil
+	}
+	r := RequestFromContext(e.Context)
+	if r == nil {
+		return nil
+	}
+	e.Data["request_id"] = r.ID
+	if r.Route != "" {
+		e.Data["route"] = r.Route
+	}
+	e.Data["tenant"] = tenant.FromContext(e.Context)
+	if p := auth.FromContext(e.Context); p != nil {
+		e.Data["user"] = p.Subject
+	}
+	return nil
+}
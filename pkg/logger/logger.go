@@ -1,15 +1,44 @@
+// Package logger builds the service's logger. Entries logged with a
+// request context carry the request ID, route, tenant and user, and
+// sensitive values are redacted before anything is written.
 package logger
 
 import (
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/tracing"
 )
 
-func NewLogger() *logrus.Logger {
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+func NewLogger(cfg *config.Config) (*logrus.Logger, error) {
+	level, err := logrus.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		return nil, fmt.Errorf("LOG_LEVEL: %w", err)
+	}
+
 	log := logrus.New()
 	log.SetOutput(os.Stdout)
-	log.SetLevel(logrus.DebugLevel)
-	log.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
-	return log
+	log.SetLevel(level)
+	switch cfg.LogFormat {
+	case FormatJSON:
+		log.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	case FormatText:
+		log.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	default:
+		return nil, fmt.Errorf("LOG_FORMAT: unknown format %q", cfg.LogFormat)
+	}
+
+	log.AddHook(ContextHook{})
+	log.AddHook(tracing.LogHook{})
+	// Added last so that it also sees the fields added by the others.
+	log.AddHook(RedactHook{})
+	return log, nil
 }
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/tenant"
+)
+
+func newTestLogger(t *testing.T, cfg *config.Config) (*logrus.Logger, *bytes.Buffer) {
+	log, err := NewLogger(cfg)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	return log, &buf
+}
+
+func lastLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(lines[len(lines)-1], &out))
+	return out
+}
+
+func TestNewLoggerUsesConfig(t *testing.T) {
+	log, buf := newTestLogger(t, &config.Config{LogLevel: "warn", LogFormat: FormatJSON})
+
+	log.Info("hidden")
+	assert.Empty(t, buf.String())
+	log.Warn("shown")
+	assert.Equal(t, "shown", lastLine(t, buf)["msg"])
+
+	_, err := NewLogger(&config.Config{LogLevel: "loud", LogFormat: FormatJSON})
+	assert.Error(t, err)
+	_, err = NewLogger(&config.Config{LogLevel: "info", LogFormat: "xml"})
+	assert.Error(t, err)
+}
+
+func TestLogsCarryRequest(t *testing.T) {
+	log, buf := newTestLogger(t, &config.Config{LogLevel: "info", LogFormat: FormatJSON})
+
+	ctx := WithRequest(context.Background(), &Request{ID: "req-1", Route: "/api/v1/products/:id"})
+	ctx = tenant.WithID(ctx, "store-a")
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{Subject: "alice"})
+	log.WithContext(ctx).Info("fetched")
+
+	line := lastLine(t, buf)
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, "/api/v1/products/:id", line["route"])
+	assert.Equal(t, "store-a", line["tenant"])
+	assert.Equal(t, "alice", line["user"])
+
+	log.WithContext(context.Background()).Info("background")
+	assert.NotContains(t, lastLine(t, buf), "request_id")
+}
+
+func TestRedactsSecrets(t *testing.T) {
+	log, buf := newTestLogger(t, &config.Config{LogLevel: "info", LogFormat: FormatJSON})
+
+	log.WithFields(logrus.Fields{
+		"password":  "hunter2",
+		"key_hash":  "abc",
+		"cache_key": "products:id:1",
+	}).WithError(errors.New("dial postgres://app:s3cret@db:5432/app failed")).
+		Infof("connecting with host=db password=s3cret and Bearer eyJhbGciOi")
+
+	line := lastLine(t, buf)
+	assert.Equal(t, redacted, line["password"])
+	assert.Equal(t, redacted, line["key_hash"])
+	assert.Equal(t, "products:id:1", line["cache_key"])
+	assert.Equal(t, "dial postgres://app:[REDACTED]@db:5432/app failed", line["error"])
+	assert.Equal(t, "connecting with host=db password=[REDACTED] and Bearer [REDACTED]", line["msg"])
+	assert.NotContains(t, buf.String(), "s3cret")
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveFields are parts of field names whose values are never logged.
+var sensitiveFields = []string{"password", "secret", "token", "authorization", "api_key", "apikey", "cookie", "dsn", "hash"}
+
+// sensitiveText matches credentials written inline in messages and errors:
+// URL user info, key=value pairs and bearer tokens.
+var sensitiveText = []struct {
+	pattern *regexp.Regexp
+	repl    string
+}{
+	{regexp.MustCompile(`://([^:/@\s]+):[^@\s]+@`), "://$1:" + redacted + "@"},
+	{regexp.MustCompile(`(?i)\b(password|secret|token|api_key|sslpassword)=('[^']*'|\S+)`), "$1=" + redacted},
+	{regexp.MustCompile(`(?i)\bBearer\s+\S+`), "Bearer " + redacted},
+	// API keys, in case one is logged on its own.
+	{regexp.MustCompile(`\bspk_\S+`), redacted},
+}
+
+// RedactHook replaces the values of sensitive fields and scrubs
+// credentials from messages and string or error fields.
+type RedactHook struct{}
+
+func (RedactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (RedactHook) Fire(e *logrus.Entry) error {
+	e.Message = Redact(e.Message)
+	for k, v := range e.Data {
+		if sensitiveField(k) {
+			e.Data[k] = redacted
+			continue
+		}
+		switch v := v.(type) {
+		case string:
+			e.Data[k] = Redact(v)
+		case error:
+			if s := v.Error(); Redact(s) != s {
+				e.Data[k] = Redact(s)
+			}
+		}
+	}
+	return nil
+}
+
+// Redact scrubs inline credentials from s.
+func Redact(s string) string {
+	for _, t := range sensitiveText {
+		s = t.pattern.ReplaceAllString(s, t.repl)
+	}
+	return s
+}
+
+func sensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, f := range sensitiveFields {
+		if strings.Contains(name, f) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+// Package metrics collects Prometheus metrics for the service and serves
+// them in the text format. Every label takes values from a set fixed by the
+// code or configuration, such as route templates and command names, never
+// from IDs or other request data, so that the number of series stays
+// bounded.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "product_api"
+
+// Registry holds every metric of the service, along with the Go runtime
+// and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route template, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	dbQueries = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Repository call latency, retries included, by repository and operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"repository", "operation"})
+
+	redisCommands = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_command_duration_seconds",
+		Help:      "Redis round trip latency by command; pipelines are counted as \"pipeline\".",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 12),
+	}, []string{"command"})
+
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Cache lookups by key family and result (hit, miss or error).",
+	}, []string{"family", "result"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ratelimit_rejections_total",
+		Help:      "Requests rejected by the rate limiter, by bucket.",
+	}, []string{"bucket"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		dbQueries,
+		redisCommands,
+		cacheLookups,
+		rateLimited,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB exports the connection pool statistics of db under name.
+// Registering the same name again is a no-op.
+func RegisterDB(name string, db *sql.DB) error {
+	err := Registry.Register(collectors.NewDBStatsCollector(db, name))
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		return nil
+	}
+	return err
+}
+
+func ObserveRequest(route, method string, status int, d time.Duration) {
+	httpRequests.WithLabelValues(route, method, strconv.Itoa(status)).Observe(d.Seconds())
+}
+
+// ObserveQuery records a repository call that started at start. It is
+// meant to be deferred at the top of the call.
+func ObserveQuery(repository, operation string, start time.Time) {
+	dbQueries.WithLabelValues(repository, operation).Observe(time.Since(start).Seconds())
+}
+
+func ObserveRedis(command string, d time.Duration) {
+	redisCommands.WithLabelValues(command).Observe(d.Seconds())
+}
+
+// Cache lookup results.
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+)
+
+func CountCacheLookup(family, result string) {
+	cacheLookups.WithLabelValues(family, result).Inc()
+}
+
+func CountRateLimited(bucket string) {
+	rateLimited.WithLabelValues(bucket).Inc()
+}
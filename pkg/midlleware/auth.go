@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"simple-product-api/internal/apikey"
+	"simple-product-api/pkg/auth"
+)
+
+const (
+	principalLocal = "principal"
+	headerAPIKey   = "X-API-Key"
+)
+
+// KeyResolver looks up the principal behind an API key.
+type KeyResolver interface {
+	ResolveKey(ctx context.Context, key string) (*auth.Principal, error)
+}
+
+// Authenticate resolves the credentials sent with a request into the
+// request principal. API keys are accepted in X-API-Key or as a bearer
+// token starting with spk_; any other bearer token is verified as a JWT.
+// Requests without credentials continue anonymously so that public routes
+// stay public; RequireScopes guards the rest.
+func Authenticate(v *auth.Verifier, keys KeyResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(headerAPIKey)
+		header := c.Get(fiber.HeaderAuthorization)
+		if key == "" && header == "" {
+			return c.Next()
+		}
+
+		var raw string
+		if key == "" {
+			var ok bool
+			raw, ok = strings.CutPrefix(header, "Bearer ")
+			if !ok || raw == "" {
+				return fiber.NewError(fiber.StatusUnauthorized, "malformed authorization header")
+			}
+			if strings.HasPrefix(raw, apikey.KeyPrefix) {
+				key = raw
+			}
+		}
+
+		var (
+			p   *auth.Principal
+			err error
+		)
+		if key != "" {
+			if keys == nil {
+				return fiber.NewError(fiber.StatusUnauthorized, "api keys are not accepted")
+			}
+			p, err = keys.ResolveKey(c.UserContext(), key)
+		} else {
+			p, err = v.Verify(raw)
+		}
+		if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrNoKeys) {
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid or expired credentials")
+		}
+		if err != nil {
+			return err
+		}
+
+		c.Locals(principalLocal, p)
+		c.SetUserContext(auth.WithPrincipal(c.UserContext(), p))
+		return c.Next()
+	}
+}
+
+// RequireScopes rejects anonymous callers with 401 and callers missing any of
+// the scopes with 403.
+func RequireScopes(scopes ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		p := Principal(c)
+		if p == nil {
+			return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+		}
+		for _, s := range scopes {
+			if !p.HasScope(s) {
+				return fiber.NewError(fiber.StatusForbidden, "missing scope "+s)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// Principal returns the authenticated caller, or nil for anonymous requests.
+func Principal(c *fiber.Ctx) *auth.Principal {
+	p, _ := c.Locals(principalLocal).(*auth.Principal)
+	return p
+}
@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
+	"simple-product-api/pkg/breaker"
 	"simple-product-api/pkg/common"
 )
 
@@ -12,6 +15,8 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 	if e, ok := err.(*fiber.Error); ok {
 		code = e.Code
 		message = e.Message
+	} else if errors.Is(err, breaker.ErrOpen) {
+		code = fiber.StatusServiceUnavailable
 	}
 
 	return c.Status(code).JSON(common.Response{
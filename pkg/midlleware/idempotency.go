@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"simple-product-api/pkg/common"
+	"simple-product-api/pkg/deadline"
+	"simple-product-api/pkg/idempotency"
+	"simple-product-api/pkg/tenant"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	headerReplayed       = "Idempotent-Replayed"
+	maxIdempotencyKeyLen = 255
+)
+
+// Idempotency makes POST requests that carry an Idempotency-Key header safe
+// to retry. The first request with a key runs normally and its response is
+// stored; a retry with the same key and body gets the stored response back,
+// a retry with a different body is rejected with 422, and a retry that
+// arrives while the first request is still running gets 409. Server errors
+// are not stored so that they can be retried. Keys are scoped to the tenant
+// and caller. The response is stored, or the key released, within
+// writeTimeout even when the request deadline has already passed, so a
+// timed out request does not leave its key locked. A request that outlives
+// its claim cannot store over, or release, the claim of a retry that took
+// the key over. Must run after Authenticate and ResolveTenant.
+func Idempotency(store *idempotency.Store, writeTimeout time.Duration, log *logrus.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" || c.Method() != fiber.MethodPost {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			return fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key is too long")
+		}
+
+		ctx := c.UserContext()
+		caller := "ip:" + c.IP()
+		if p := Principal(c); p != nil {
+			caller = "sub:" + p.Subject
+		}
+		scoped := tenant.FromContext(ctx) + ":" + caller + ":" + c.Path() + ":" + key
+		fingerprint := fingerprint(c)
+
+		token, rec, err := store.Claim(ctx, scoped, fingerprint)
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Warn("idempotency store unavailable, running request without it")
+			return c.Next()
+		}
+		if token == "" {
+			return replay(c, rec, fingerprint)
+		}
+
+		if err := c.Next(); err != nil {
+			release(ctx, store, scoped, token, writeTimeout, log)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			release(ctx, store, scoped, token, writeTimeout, log)
+			return nil
+		}
+
+		writeCtx, cancel := deadline.Detach(ctx, writeTimeout)
+		defer cancel()
+		err = store.Complete(writeCtx, scoped, token, idempotency.Record{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        c.Response().Body(),
+		})
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Warn("failed to store idempotent response")
+		}
+		return nil
+	}
+}
+
+func replay(c *fiber.Ctx, rec *idempotency.Record, fingerprint string) error {
+	if rec.Fingerprint != fingerprint {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(common.Response{
+			Code:    fiber.StatusUnprocessableEntity,
+			Message: "Idempotency-Key was already used with a different request",
+		})
+	}
+	if rec.State != idempotency.StateDone {
+		c.Set(fiber.HeaderRetryAfter, "1")
+		return c.Status(fiber.StatusConflict).JSON(common.Response{
+			Code:    fiber.StatusConflict,
+			Message: "A request with this Idempotency-Key is still being processed",
+		})
+	}
+
+	c.Set(headerReplayed, "true")
+	if rec.ContentType != "" {
+		c.Set(fiber.HeaderContentType, rec.ContentType)
+	}
+	return c.Status(rec.Status).Send(rec.Body)
+}
+
+func release(ctx context.Context, store *idempotency.Store, key, token string, writeTimeout time.Duration, log *logrus.Logger) {
+	writeCtx, cancel := deadline.Detach(ctx, writeTimeout)
+	defer cancel()
+	if err := store.Release(writeCtx, key, token); err != nil {
+		log.WithContext(ctx).WithError(err).Warn("failed to release idempotency key")
+	}
+}
+
+// fingerprint identifies a request by method, path, query and body.
+func fingerprint(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method() + " " + c.OriginalURL() + "\n"))
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-product-api/pkg/idempotency"
+)
+
+func newIdempotentApp(t *testing.T, handler fiber.Handler) *fiber.App {
+	m := miniredis.RunT(t)
+	store := idempotency.NewStore(redis.NewClient(&redis.Options{Addr: m.Addr()}), time.Hour, time.Minute)
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Post("/products", Idempotency(store, time.Second, logrus.New()), handler)
+	return app
+}
+
+func post(t *testing.T, app *fiber.App, key, body string) (int, string, string) {
+	req := httptest.NewRequest(fiber.MethodPost, "/products", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data), resp.Header.Get(headerReplayed)
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	var calls int32
+	app := newIdempotentApp(t, func(c *fiber.Ctx) error {
+		n := atomic.AddInt32(&calls, 1)
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"call": n})
+	})
+
+	status, body, replayed := post(t, app, "k1", `{"name":"Banana"}`)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Empty(t, replayed)
+
+	status, replayedBody, replayed := post(t, app, "k1", `{"name":"Banana"}`)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, body, replayedBody)
+	assert.Equal(t, "true", replayed)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyRejectsDifferentBody(t *testing.T) {
+	app := newIdempotentApp(t, func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	status, _, _ := post(t, app, "k1", `{"name":"Banana"}`)
+	assert.Equal(t, fiber.StatusCreated, status)
+
+	status, _, _ = post(t, app, "k1", `{"name":"Apple"}`)
+	assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+}
+
+func TestIdempotencyConcurrentDuplicate(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	app := newIdempotentApp(t, func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	first := make(chan int)
+	go func() {
+		status, _, _ := post(t, app, "k1", `{}`)
+		first <- status
+	}()
+	<-started
+
+	status, _, _ := post(t, app, "k1", `{}`)
+	assert.Equal(t, fiber.StatusConflict, status)
+
+	close(release)
+	assert.Equal(t, fiber.StatusCreated, <-first)
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	var calls int32
+	app := newIdempotentApp(t, func(c *fiber.Ctx) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return fiber.NewError(fiber.StatusServiceUnavailable, "try again")
+		}
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	status, _, _ := post(t, app, "k1", `{}`)
+	assert.Equal(t, fiber.StatusServiceUnavailable, status)
+
+	status, _, replayed := post(t, app, "k1", `{}`)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Empty(t, replayed)
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	var calls int32
+	app := newIdempotentApp(t, func(c *fiber.Ctx) error {
+		atomic.AddInt32(&calls, 1)
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	post(t, app, "", `{}`)
+	post(t, app, "", `{}`)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyStoresResponseAfterRequestDeadline(t *testing.T) {
+	var calls int32
+	m := miniredis.RunT(t)
+	store := idempotency.NewStore(redis.NewClient(&redis.Options{Addr: m.Addr()}), time.Hour, time.Minute)
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Post("/products",
+		func(c *fiber.Ctx) error {
+			ctx, cancel := context.WithCancel(c.UserContext())
+			defer cancel()
+			c.SetUserContext(ctx)
+			c.Locals("cancel", cancel)
+			return c.Next()
+		},
+		Idempotency(store, time.Second, logrus.New()),
+		func(c *fiber.Ctx) error {
+			atomic.AddInt32(&calls, 1)
+			// The request deadline passes as the handler finishes.
+			c.Locals("cancel").(context.CancelFunc)()
+			return c.SendStatus(fiber.StatusCreated)
+		},
+	)
+
+	status, _, _ := post(t, app, "k1", `{}`)
+	assert.Equal(t, fiber.StatusCreated, status)
+
+	status, _, replayed := post(t, app, "k1", `{}`)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, "true", replayed)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"simple-product-api/pkg/metrics"
+)
+
+// Metrics records the latency of every request by route template, method
+// and status. Errors are rendered by the app's error handler here, so that
+// their status is the one sent. Requests that match no route are labelled
+// with the path of the last middleware they passed through, which keeps
+// unknown paths out of the labels.
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+		r := c.Route()
+		metrics.ObserveRequest(r.Path, r.Method, c.Response().StatusCode(), time.Since(start))
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-product-api/pkg/metrics"
+)
+
+func scrape(t *testing.T) string {
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(fiber.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetricsLabelsByRouteTemplate(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(Metrics())
+	app.Get("/things/:id", func(c *fiber.Ctx) error {
+		if c.Params("id") == "missing" {
+			return fiber.NewError(fiber.StatusNotFound, "not found")
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	assert.Equal(t, fiber.StatusOK, status(t, app, fiber.MethodGet, "/things/8f14e45f"))
+	assert.Equal(t, fiber.StatusNotFound, status(t, app, fiber.MethodGet, "/things/missing"))
+	assert.Equal(t, fiber.StatusNotFound, status(t, app, fiber.MethodGet, "/elsewhere/8f14e45f"))
+
+	out := scrape(t)
+	assert.Contains(t, out, `product_api_http_request_duration_seconds_count{method="GET",route="/things/:id",status="200"} 1`)
+	assert.Contains(t, out, `product_api_http_request_duration_seconds_count{method="GET",route="/things/:id",status="404"} 1`)
+	assert.Contains(t, out, `product_api_http_request_duration_seconds_count{method="GET",route="/",status="404"} 1`)
+	assert.NotContains(t, out, "8f14e45f")
+}
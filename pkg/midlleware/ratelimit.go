@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"simple-product-api/pkg/common"
+	"simple-product-api/pkg/metrics"
+	"simple-product-api/pkg/ratelimit"
+	"simple-product-api/pkg/tenant"
+)
+
+// RateLimit enforces the limits in rules per client: the authenticated
+// principal within its tenant when there is one, the client IP otherwise.
+// It sets the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers on every response and Retry-After on rejections. When Redis is
+// unavailable requests are let through rather than failing the API. Must
+// run after Authenticate and ResolveTenant.
+func RateLimit(l *ratelimit.Limiter, rules *ratelimit.Rules, log *logrus.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		client := "ip:" + c.IP()
+		plan := ""
+		if p := Principal(c); p != nil {
+			client = tenant.FromContext(c.UserContext()) + ":sub:" + p.Subject
+			plan = p.Plan
+		}
+
+		limit, bucket := rules.Match(c.Method(), c.Path(), plan)
+		return enforce(c, l, client, bucket, limit, log)
+	}
+}
+
+// RateLimitIP enforces the per IP limit in rules on every request. It runs
+// before Authenticate, so that requests with bad credentials and routes
+// outside the API are limited too, and otherwise behaves like RateLimit.
+func RateLimitIP(l *ratelimit.Limiter, rules *ratelimit.Rules, log *logrus.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		limit, ok := rules.PerIP()
+		if !ok {
+			return c.Next()
+		}
+		return enforce(c, l, "ip:"+c.IP(), "ip", limit, log)
+	}
+}
+
+// enforce counts the request against the client's bucket and rejects it
+// when that is over limit.
+func enforce(c *fiber.Ctx, l *ratelimit.Limiter, client, bucket string, limit ratelimit.Limit, log *logrus.Logger) error {
+	res, err := l.Allow(c.UserContext(), client+":"+bucket, limit)
+	if err != nil {
+		log.WithContext(c.UserContext()).WithError(err).Warn("rate limiter unavailable, allowing request")
+		return c.Next()
+	}
+
+	c.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	c.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Set("RateLimit-Reset", seconds(res.ResetAfter))
+
+	if !res.Allowed {
+		metrics.CountRateLimited(bucket)
+		c.Set(fiber.HeaderRetryAfter, seconds(res.RetryAfter))
+		return c.Status(fiber.StatusTooManyRequests).JSON(common.Response{
+			Code:    fiber.StatusTooManyRequests,
+			Message: "Too many requests. Please try again later.",
+		})
+	}
+	return c.Next()
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/ratelimit"
+	"simple-product-api/pkg/tenant"
+)
+
+func TestRateLimitIPCoversUnauthenticatedRoutes(t *testing.T) {
+	m := miniredis.RunT(t)
+	rules, err := ratelimit.NewRules(&config.Config{RateLimitDefault: "20/1m", RateLimitIP: "2/1m"})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(RateLimitIP(ratelimit.NewLimiter(redis.NewClient(&redis.Options{Addr: m.Addr()})), rules, logrus.New()))
+	app.Get("/healthz", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	app.Get("/api/v1/products", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusUnauthorized) })
+
+	var statuses []int
+	for _, path := range []string{"/api/v1/products", "/healthz", "/healthz"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		require.NoError(t, err)
+		statuses = append(statuses, resp.StatusCode)
+	}
+
+	assert.Equal(t, []int{fiber.StatusUnauthorized, fiber.StatusOK, fiber.StatusTooManyRequests}, statuses)
+}
+
+func TestRateLimitSeparatesTenants(t *testing.T) {
+	m := miniredis.RunT(t)
+	rules, err := ratelimit.NewRules(&config.Config{RateLimitDefault: "1/1m"})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(principalLocal, &auth.Principal{Subject: "u1"})
+		c.SetUserContext(tenant.WithID(c.UserContext(), c.Get(HeaderTenantID)))
+		return c.Next()
+	})
+	app.Use(RateLimit(ratelimit.NewLimiter(redis.NewClient(&redis.Options{Addr: m.Addr()})), rules, logrus.New()))
+	app.Get("/api/v1/products", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	var statuses []int
+	for _, id := range []string{"store-1", "store-2", "store-1"} {
+		req := httptest.NewRequest(fiber.MethodGet, "/api/v1/products", nil)
+		req.Header.Set(HeaderTenantID, id)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		statuses = append(statuses, resp.StatusCode)
+	}
+
+	assert.Equal(t, []int{fiber.StatusOK, fiber.StatusOK, fiber.StatusTooManyRequests}, statuses)
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"simple-product-api/pkg/logger"
+	"simple-product-api/pkg/route"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+// requestIDPattern limits the IDs accepted from callers to ones that are
+// safe to log and echo back.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID takes the request ID from the X-Request-ID header, or generates
+// one when the header is missing or malformed, and returns it in the same
+// header. The ID and the template of the route the request will match are
+// put in the user context for the logger. Must run first, so that every
+// later log line carries them.
+func RequestID() fiber.Handler {
+	var routes routeIndex
+	return func(c *fiber.Ctx) error {
+		id := c.Get(HeaderRequestID)
+		if requestIDPattern.MatchString(id) {
+			id = strings.Clone(id)
+		} else {
+			id = uuid.NewString()
+		}
+		c.Set(HeaderRequestID, id)
+
+		req := &logger.Request{ID: id, Route: routes.match(c)}
+		c.SetUserContext(logger.WithRequest(c.UserContext(), req))
+		return c.Next()
+	}
+}
+
+// routeIndex finds the route a request will match before Fiber routes it,
+// since middleware only sees its own route. Routes are tried in the order
+// they were registered, which is the order Fiber tries them in.
+type routeIndex struct {
+	once     sync.Once
+	patterns []route.Pattern
+}
+
+func (x *routeIndex) match(c *fiber.Ctx) string {
+	x.once.Do(func() {
+		for _, r := range c.App().GetRoutes(true) {
+			x.patterns = append(x.patterns, route.Pattern{Method: r.Method, Path: r.Path})
+		}
+	})
+	for _, p := range x.patterns {
+		if p.Match(c.Method(), c.Path()) {
+			return p.Path
+		}
+	}
+	return ""
+}
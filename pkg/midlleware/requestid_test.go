@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-product-api/pkg/logger"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen *logger.Request
+	app := fiber.New()
+	app.Use(RequestID())
+	api := app.Group("/api/v1", func(c *fiber.Ctx) error { return c.Next() })
+	api.Get("/products/by-barcode/:code", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	api.Get("/products/:id", func(c *fiber.Ctx) error {
+		seen = logger.RequestFromContext(c.UserContext())
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/api/v1/products/42", nil)
+	req.Header.Set(HeaderRequestID, "abc-123")
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, "abc-123", resp.Header.Get(HeaderRequestID))
+	require.NotNil(t, seen)
+	assert.Equal(t, "abc-123", seen.ID)
+	assert.Equal(t, "/api/v1/products/:id", seen.Route)
+
+	req = httptest.NewRequest(fiber.MethodGet, "/api/v1/products/42", nil)
+	req.Header.Set(HeaderRequestID, "bad id\nwith newline")
+	resp, err = app.Test(req, -1)
+	require.NoError(t, err)
+	assert.NoError(t, uuid.Validate(resp.Header.Get(HeaderRequestID)), "malformed IDs are replaced")
+	assert.Equal(t, resp.Header.Get(HeaderRequestID), seen.ID)
+}
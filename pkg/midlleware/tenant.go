@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"simple-product-api/pkg/tenant"
+)
+
+const HeaderTenantID = "X-Tenant-ID"
+
+// ResolveTenant puts the tenant of the request into the user context.
+// Authenticated callers belong to the tenant of their credentials, or to
+// the default tenant when the credentials carry none; the X-Tenant-ID
+// header may repeat it but not contradict it. Only anonymous callers, such
+// as storefront reads, pick a tenant with the header, falling back to the
+// default tenant. Must run after Authenticate.
+func ResolveTenant() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(HeaderTenantID)
+		if id != "" && !tenant.Valid(id) {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid tenant id")
+		}
+
+		if p := Principal(c); p != nil {
+			own := p.TenantID
+			if own == "" {
+				own = tenant.Default
+			}
+			if id != "" && id != own {
+				return fiber.NewError(fiber.StatusForbidden, "credentials do not belong to tenant "+id)
+			}
+			id = own
+		}
+		if id == "" {
+			id = tenant.Default
+		}
+
+		c.SetUserContext(tenant.WithID(c.UserContext(), id))
+		return c.Next()
+	}
+}
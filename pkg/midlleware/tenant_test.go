@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/tenant"
+)
+
+// tenantApp authenticates every request as p, or anonymously when p is
+// nil, and answers with the resolved tenant.
+func tenantApp(p *auth.Principal) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(func(c *fiber.Ctx) error {
+		if p != nil {
+			c.Locals(principalLocal, p)
+		}
+		return c.Next()
+	}, ResolveTenant())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(tenant.FromContext(c.UserContext()))
+	})
+	return app
+}
+
+func resolve(t *testing.T, app *fiber.App, header string) (int, string) {
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set(HeaderTenantID, header)
+	}
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestResolveTenant(t *testing.T) {
+	anonymous := tenantApp(nil)
+	code, id := resolve(t, anonymous, "store-b")
+	assert.Equal(t, fiber.StatusOK, code)
+	assert.Equal(t, "store-b", id, "anonymous callers pick a tenant")
+	_, id = resolve(t, anonymous, "")
+	assert.Equal(t, tenant.Default, id)
+
+	pinned := tenantApp(&auth.Principal{Subject: "alice", TenantID: "store-a"})
+	_, id = resolve(t, pinned, "")
+	assert.Equal(t, "store-a", id)
+	code, _ = resolve(t, pinned, "store-b")
+	assert.Equal(t, fiber.StatusForbidden, code)
+}
+
+func TestResolveTenantPinsJWTWithoutTenantClaim(t *testing.T) {
+	v, err := auth.NewVerifier(&config.Config{JWTSecret: "secret"})
+	require.NoError(t, err)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "bob", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		Roles:            []string{"admin"},
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(Authenticate(v, nil), ResolveTenant())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(tenant.FromContext(c.UserContext()))
+	})
+	request := func(header string) (int, string) {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		if header != "" {
+			req.Header.Set(HeaderTenantID, header)
+		}
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	code, _ := request("store-b")
+	assert.Equal(t, fiber.StatusForbidden, code, "a token without a tenant cannot pick another one")
+
+	code, id := request("")
+	assert.Equal(t, fiber.StatusOK, code)
+	assert.Equal(t, tenant.Default, id)
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/route"
+)
+
+// RouteTimeout overrides the request timeout for matching routes.
+type RouteTimeout struct {
+	Route   route.Pattern
+	Timeout time.Duration
+}
+
+type Timeouts struct {
+	mu      sync.RWMutex
+	Default time.Duration
+	Routes  []RouteTimeout
+}
+
+func NewTimeouts(cfg *config.Config) (*Timeouts, error) {
+	if cfg.RequestTimeout <= 0 {
+		return nil, fmt.Errorf("REQUEST_TIMEOUT: %v is not positive", cfg.RequestTimeout)
+	}
+	t := &Timeouts{Default: cfg.RequestTimeout}
+
+	for _, entry := range route.SplitList(cfg.RouteTimeouts) {
+		pattern, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("ROUTE_TIMEOUTS: %q is not \"METHOD /path=duration\"", entry)
+		}
+		rt, err := route.Parse(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("ROUTE_TIMEOUTS: %w", err)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(spec))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("ROUTE_TIMEOUTS: %q has an invalid duration", entry)
+		}
+		t.Routes = append(t.Routes, RouteTimeout{Route: rt, Timeout: d})
+	}
+	return t, nil
+}
+
+func (t *Timeouts) Match(method, path string) time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, r := range t.Routes {
+		if r.Route.Match(method, path) {
+			return r.Timeout
+		}
+	}
+	return t.Default
+}
+
+// Update replaces the timeouts with next, which is left unused, when the
+// configuration is reloaded.
+func (t *Timeouts) Update(next *Timeouts) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Default, t.Routes = next.Default, next.Routes
+}
+
+// Timeout puts a deadline on the request's user context. Handlers and the
+// calls they make give up once it passes; the handler is run once and its
+// error is returned as it is, except that a bare deadline error becomes a
+// 504 instead of a 500.
+func Timeout(t *Timeouts) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), t.Match(c.Method(), c.Path()))
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		var fe *fiber.Error
+		if err != nil && !errors.As(err, &fe) && errors.Is(err, context.DeadlineExceeded) {
+			return fiber.NewError(fiber.StatusGatewayTimeout, "request timed out")
+		}
+		return err
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-product-api/pkg/config"
+)
+
+func newTimeoutApp(t *testing.T, routes string, handler fiber.Handler) *fiber.App {
+	timeouts, err := NewTimeouts(&config.Config{RequestTimeout: time.Second, RouteTimeouts: routes})
+	require.NoError(t, err)
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(Timeout(timeouts))
+	app.All("/*", handler)
+	return app
+}
+
+func status(t *testing.T, app *fiber.App, method, path string) int {
+	resp, err := app.Test(httptest.NewRequest(method, path, nil), -1)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestTimeoutKeepsHandlerStatus(t *testing.T) {
+	var calls int
+	app := newTimeoutApp(t, "", func(c *fiber.Ctx) error {
+		calls++
+		return fiber.NewError(fiber.StatusConflict, "duplicate")
+	})
+
+	assert.Equal(t, fiber.StatusConflict, status(t, app, fiber.MethodPost, "/products"))
+	assert.Equal(t, 1, calls, "handlers must not be re-run")
+}
+
+func TestTimeoutSetsDeadline(t *testing.T) {
+	app := newTimeoutApp(t, "GET /products/:id=50ms", func(c *fiber.Ctx) error {
+		<-c.UserContext().Done()
+		return c.UserContext().Err()
+	})
+
+	start := time.Now()
+	assert.Equal(t, fiber.StatusGatewayTimeout, status(t, app, fiber.MethodGet, "/products/1"))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestTimeoutMatchesRoutes(t *testing.T) {
+	timeouts, err := NewTimeouts(&config.Config{RequestTimeout: 3 * time.Second, RouteTimeouts: "POST /api/v1/products/merge=10s, GET /api/v1/products/:id=500ms"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 10*time.Second, timeouts.Match(fiber.MethodPost, "/api/v1/products/merge"))
+	assert.Equal(t, 500*time.Millisecond, timeouts.Match(fiber.MethodGet, "/api/v1/products/42"))
+	assert.Equal(t, 3*time.Second, timeouts.Match(fiber.MethodGet, "/api/v1/products"))
+
+	_, err = NewTimeouts(&config.Config{RequestTimeout: time.Second, RouteTimeouts: "GET /x=soon"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"simple-product-api/pkg/tracing"
+)
+
+var tracer = otel.Tracer("simple-product-api/pkg/midlleware")
+
+// headerCarrier reads and writes W3C trace headers on a Fiber request.
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+// Get copies the value, since Fiber reuses request buffers and spans
+// outlive the request.
+func (h headerCarrier) Get(key string) string { return strings.Clone(h.c.Get(key)) }
+func (h headerCarrier) Set(key, value string) { h.c.Request().Header.Set(key, value) }
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.c.Request().Header.VisitAll(func(k, _ []byte) {
+		keys = append(keys, string(k))
+	})
+	return keys
+}
+
+var _ propagation.TextMapCarrier = headerCarrier{}
+
+// Tracing starts a server span for every request, continuing the trace of
+// an incoming traceparent header, and puts it in the user context. The span
+// is named after the route template once the route is known. It must run
+// before any middleware that renders errors, so that it sees the status
+// that was sent.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := tracing.Propagator.Extract(c.UserContext(), headerCarrier{c})
+		method := strings.Clone(c.Method())
+		ctx, span := tracer.Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(strings.Clone(c.Path())),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		r := c.Route()
+		code := c.Response().StatusCode()
+		span.SetName(method + " " + r.Path)
+		span.SetAttributes(semconv.HTTPRoute(r.Path), semconv.HTTPResponseStatusCode(code))
+		if err != nil {
+			span.RecordError(err)
+		}
+		if err != nil || code >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprint(code))
+		}
+		return err
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingContinuesTraceparent(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	var handlerTrace trace.TraceID
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(Tracing())
+	app.Use(Metrics())
+	app.Get("/things/:id", func(c *fiber.Ctx) error {
+		handlerTrace = trace.SpanContextFromContext(c.UserContext()).TraceID()
+		return fiber.NewError(fiber.StatusServiceUnavailable, "down")
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/things/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	span := ended[0]
+	assert.Equal(t, "GET /things/:id", span.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Equal(t, span.SpanContext().TraceID(), handlerTrace)
+	assert.Equal(t, codes.Error, span.Status().Code)
+}
@@ -0,0 +1,171 @@
+package policy
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"simple-product-api/pkg/auth"
+	"simple-product-api/pkg/config"
+)
+
+const (
+	ActionCreate       = "product:create"
+	ActionUpdate       = "product:update"
+	ActionDelete       = "product:delete"
+	ActionChangeStatus = "product:change_status"
+	ActionMerge        = "product:merge"
+
+	wildcard = "*"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+//go:embed default.yaml
+var defaultPolicy []byte
+
+// Condition restricts a rule to resources with matching attributes. Empty
+// lists match anything.
+type Condition struct {
+	Status []string `yaml:"status"`
+	// TargetStatus restricts product:change_status to the listed new
+	// statuses.
+	TargetStatus []string `yaml:"target_status"`
+}
+
+type Rule struct {
+	Actions []string  `yaml:"actions"`
+	Fields  []string  `yaml:"fields"`
+	When    Condition `yaml:"when"`
+}
+
+type Role struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+type Policy struct {
+	Roles map[string]Role `yaml:"roles"`
+}
+
+// Subject is who is asking.
+type Subject struct {
+	ID    string
+	Roles []string
+}
+
+// Resource holds the attributes of the product being acted on. It is the
+// zero value for products that do not exist yet.
+type Resource struct {
+	Status string
+	// TargetStatus is the status a product:change_status request moves the
+	// product to.
+	TargetStatus string
+}
+
+type Decision struct {
+	Allowed      bool
+	Reason       string
+	DeniedFields []string
+}
+
+func SubjectFrom(p *auth.Principal) Subject {
+	if p == nil {
+		return Subject{}
+	}
+	return Subject{ID: p.Subject, Roles: p.Roles}
+}
+
+// Parse reads a YAML (or JSON) policy document.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse policy: %w", err)
+	}
+	if len(p.Roles) == 0 {
+		return nil, errors.New("parse policy: no roles defined")
+	}
+	return &p, nil
+}
+
+// Default returns the policy shipped with the service.
+func Default() *Policy {
+	p, err := Parse(defaultPolicy)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Load reads the policy file named in the config, falling back to the
+// default policy when none is set.
+func Load(cfg *config.Config) (*Policy, error) {
+	if cfg.PolicyFile == "" {
+		return Default(), nil
+	}
+	data, err := os.ReadFile(cfg.PolicyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	return Parse(data)
+}
+
+// Decide reports whether sub may perform action on res while writing the
+// given fields.
+func (p *Policy) Decide(sub Subject, action string, res Resource, fields []string) Decision {
+	if len(sub.Roles) == 0 {
+		return Decision{Reason: "no roles"}
+	}
+
+	var rules []Rule
+	for _, name := range sub.Roles {
+		for _, r := range p.Roles[name].Rules {
+			if r.allows(action) && r.When.matches(res) {
+				rules = append(rules, r)
+			}
+		}
+	}
+	if len(rules) == 0 {
+		return Decision{Reason: fmt.Sprintf("%s not permitted for roles %s", action, strings.Join(sub.Roles, ", "))}
+	}
+
+	var denied []string
+	for _, f := range fields {
+		if !slices.ContainsFunc(rules, func(r Rule) bool { return r.covers(f) }) {
+			denied = append(denied, f)
+		}
+	}
+	if len(denied) > 0 {
+		sort.Strings(denied)
+		return Decision{
+			Reason:       fmt.Sprintf("%s of fields %s not permitted", action, strings.Join(denied, ", ")),
+			DeniedFields: denied,
+		}
+	}
+	return Decision{Allowed: true}
+}
+
+// Authorize is Decide returning ErrForbidden on denial.
+func (p *Policy) Authorize(sub Subject, action string, res Resource, fields ...string) error {
+	if d := p.Decide(sub, action, res, fields); !d.Allowed {
+		return fmt.Errorf("%w: %s", ErrForbidden, d.Reason)
+	}
+	return nil
+}
+
+func (r Rule) allows(action string) bool {
+	return slices.Contains(r.Actions, wildcard) || slices.Contains(r.Actions, action)
+}
+
+func (r Rule) covers(field string) bool {
+	return slices.Contains(r.Fields, wildcard) || slices.Contains(r.Fields, field)
+}
+
+func (c Condition) matches(res Resource) bool {
+	return (len(c.Status) == 0 || slices.Contains(c.Status, res.Status)) &&
+		(len(c.TargetStatus) == 0 || slices.Contains(c.TargetStatus, res.TargetStatus))
+}
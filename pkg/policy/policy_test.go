@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func subject(roles ...string) Subject {
+	return Subject{ID: "u1", Roles: roles}
+}
+
+func TestDefaultFieldPermissions(t *testing.T) {
+	p := Default()
+	active := Resource{Status: "active"}
+
+	assert.True(t, p.Decide(subject("editor"), ActionUpdate, active, []string{"name", "type"}).Allowed)
+	assert.True(t, p.Decide(subject("pricing-manager"), ActionUpdate, active, []string{"price"}).Allowed)
+
+	d := p.Decide(subject("editor"), ActionUpdate, active, []string{"name", "price"})
+	assert.False(t, d.Allowed)
+	assert.Equal(t, []string{"price"}, d.DeniedFields)
+
+	d = p.Decide(subject("pricing-manager"), ActionUpdate, active, []string{"name"})
+	assert.False(t, d.Allowed)
+
+	// Roles combine: each field only needs one role that covers it.
+	assert.True(t, p.Decide(subject("editor", "pricing-manager"), ActionUpdate, active, []string{"name", "price"}).Allowed)
+}
+
+func TestDefaultActions(t *testing.T) {
+	p := Default()
+
+	assert.False(t, p.Decide(subject(), ActionCreate, Resource{}, []string{"name"}).Allowed)
+	assert.False(t, p.Decide(subject("viewer"), ActionCreate, Resource{}, []string{"name"}).Allowed)
+	assert.True(t, p.Decide(subject("editor"), ActionCreate, Resource{}, []string{"name", "type", "barcode", "components"}).Allowed)
+	assert.False(t, p.Decide(subject("editor"), ActionDelete, Resource{Status: "draft"}, nil).Allowed)
+	assert.True(t, p.Decide(subject("admin"), ActionMerge, Resource{Status: "active"}, nil).Allowed)
+}
+
+func TestDefaultCreateNeedsPricingForPrices(t *testing.T) {
+	p := Default()
+
+	for _, field := range []string{"price", "discount"} {
+		d := p.Decide(subject("editor"), ActionCreate, Resource{}, []string{"name", "type", field})
+		assert.False(t, d.Allowed, field)
+		assert.Equal(t, []string{field}, d.DeniedFields)
+	}
+	assert.False(t, p.Decide(subject("pricing-manager"), ActionCreate, Resource{}, []string{"name", "type", "price"}).Allowed)
+	assert.True(t, p.Decide(subject("editor", "pricing-manager"), ActionCreate, Resource{}, []string{"name", "type", "price", "discount"}).Allowed)
+}
+
+func TestConditions(t *testing.T) {
+	p := Default()
+
+	assert.True(t, p.Decide(subject("editor"), ActionChangeStatus, Resource{Status: "draft", TargetStatus: "pending_review"}, nil).Allowed)
+	assert.False(t, p.Decide(subject("editor"), ActionChangeStatus, Resource{Status: "active"}, nil).Allowed)
+}
+
+func TestEditorsCannotPublish(t *testing.T) {
+	p := Default()
+
+	submit := Resource{Status: "draft", TargetStatus: "pending_review"}
+	assert.NoError(t, p.Authorize(subject("editor"), ActionChangeStatus, submit))
+
+	publish := Resource{Status: "pending_review", TargetStatus: "active"}
+	assert.ErrorIs(t, p.Authorize(subject("editor"), ActionChangeStatus, publish), ErrForbidden)
+	assert.NoError(t, p.Authorize(subject("admin"), ActionChangeStatus, publish))
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(`
+roles:
+  stocker:
+    rules:
+      - actions: [product:update]
+        fields: [barcode]
+        when:
+          status: [active]
+`))
+	require.NoError(t, err)
+
+	assert.True(t, p.Decide(subject("stocker"), ActionUpdate, Resource{Status: "active"}, []string{"barcode"}).Allowed)
+	assert.False(t, p.Decide(subject("stocker"), ActionUpdate, Resource{Status: "draft"}, []string{"barcode"}).Allowed)
+
+	_, err = Parse([]byte(`roles: {}`))
+	assert.Error(t, err)
+}
+
+func TestAuthorize(t *testing.T) {
+	err := Default().Authorize(subject("viewer"), ActionDelete, Resource{Status: "active"})
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// gcra implements the generic cell rate algorithm. The key holds the
+// theoretical arrival time (TAT) of the next request in milliseconds; a
+// request is allowed while the TAT stays within burst emission intervals
+// of now. Redis' clock is used so that every replica agrees on time.
+//
+// Returns {allowed, remaining, retry_after_ms, reset_after_ms}.
+var gcra = redis.NewScript(`
+local key = KEYS[1]
+local burst = tonumber(ARGV[1])
+local emission = tonumber(ARGV[2])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local tat = tonumber(redis.call("GET", key))
+if tat == nil or tat < now then
+  tat = now
+end
+
+local new_tat = tat + emission
+local allow_at = new_tat - burst * emission
+local diff = now - allow_at
+
+if diff < 0 then
+  return {0, 0, -diff, tat - now}
+end
+
+local reset_after = new_tat - now
+redis.call("SET", key, new_tat, "PX", reset_after)
+return {1, math.floor(diff / emission), 0, reset_after}
+`)
+
+type Limit struct {
+	// Requests may be made in a burst, after which they are admitted at a
+	// steady Requests/Period pace.
+	Requests int
+	Period   time.Duration
+}
+
+func (l Limit) emission() int64 {
+	return l.Period.Milliseconds() / int64(l.Requests)
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+// Limiter counts requests in Redis so that all replicas share one budget
+// per client.
+type Limiter struct {
+	rdb *redis.Client
+}
+
+func NewLimiter(rdb *redis.Client) *Limiter {
+	return &Limiter{rdb: rdb}
+}
+
+// Allow records one request against key and reports whether it fits in
+// limit.
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	res, err := gcra.Run(ctx, l.rdb, []string{"ratelimit:" + key}, limit.Requests, limit.emission()).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    res[0] == 1,
+		Limit:      limit.Requests,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+		ResetAfter: time.Duration(res[3]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(t *testing.T) (*Limiter, *miniredis.Miniredis) {
+	m := miniredis.RunT(t)
+	m.SetTime(time.Unix(1_700_000_000, 0))
+	return NewLimiter(redis.NewClient(&redis.Options{Addr: m.Addr()})), m
+}
+
+func TestAllowBurstThenReject(t *testing.T) {
+	l, _ := newTestLimiter(t)
+	ctx := context.Background()
+	limit := Limit{Requests: 3, Period: time.Minute}
+
+	for want := 2; want >= 0; want-- {
+		res, err := l.Allow(ctx, "ip:1.2.3.4", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, want, res.Remaining)
+		assert.Equal(t, 3, res.Limit)
+	}
+
+	res, err := l.Allow(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, 20*time.Second, res.RetryAfter)
+	assert.Equal(t, time.Minute, res.ResetAfter)
+}
+
+func TestAllowRefillsOverTime(t *testing.T) {
+	l, m := newTestLimiter(t)
+	ctx := context.Background()
+	limit := Limit{Requests: 2, Period: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		_, err := l.Allow(ctx, "k", limit)
+		require.NoError(t, err)
+	}
+	res, _ := l.Allow(ctx, "k", limit)
+	assert.False(t, res.Allowed)
+
+	m.SetTime(time.Unix(1_700_000_000, 0).Add(30 * time.Second))
+	res, err := l.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+}
+
+func TestAllowKeysAreIndependent(t *testing.T) {
+	l, _ := newTestLimiter(t)
+	ctx := context.Background()
+	limit := Limit{Requests: 1, Period: time.Minute}
+
+	res, _ := l.Allow(ctx, "a", limit)
+	assert.True(t, res.Allowed)
+	res, _ = l.Allow(ctx, "b", limit)
+	assert.True(t, res.Allowed)
+	res, _ = l.Allow(ctx, "a", limit)
+	assert.False(t, res.Allowed)
+}
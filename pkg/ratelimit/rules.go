@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/route"
+)
+
+// Rule limits requests matching a route pattern.
+type Rule struct {
+	Route route.Pattern
+	Limit Limit
+}
+
+// Rules picks the limit for a request: a matching route rule first, then
+// the caller's plan, then the default. IP, when set, is a separate limit
+// on every request from one address.
+type Rules struct {
+	mu      sync.RWMutex
+	Default Limit
+	Plans   map[string]Limit
+	Routes  []Rule
+	IP      Limit
+}
+
+func NewRules(cfg *config.Config) (*Rules, error) {
+	def, err := ParseLimit(cfg.RateLimitDefault)
+	if err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_DEFAULT: %w", err)
+	}
+	r := &Rules{Default: def, Plans: map[string]Limit{}}
+
+	if cfg.RateLimitIP != "" {
+		if r.IP, err = ParseLimit(cfg.RateLimitIP); err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_IP: %w", err)
+		}
+	}
+
+	for _, entry := range route.SplitList(cfg.RateLimitPlans) {
+		name, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("RATE_LIMIT_PLANS: %q is not plan=limit", entry)
+		}
+		limit, err := ParseLimit(spec)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_PLANS: %w", err)
+		}
+		r.Plans[strings.TrimSpace(name)] = limit
+	}
+
+	for _, entry := range route.SplitList(cfg.RateLimitRoutes) {
+		pattern, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("RATE_LIMIT_ROUTES: %q is not \"METHOD /path=limit\"", entry)
+		}
+		rt, err := route.Parse(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_ROUTES: %w", err)
+		}
+		limit, err := ParseLimit(spec)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_ROUTES: %w", err)
+		}
+		r.Routes = append(r.Routes, Rule{Route: rt, Limit: limit})
+	}
+	return r, nil
+}
+
+// ParseLimit reads limits written as "<requests>/<period>", such as
+// "20/1m" or "5/s".
+func ParseLimit(s string) (Limit, error) {
+	n, per, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("limit %q is not requests/period", s)
+	}
+	requests, err := strconv.Atoi(n)
+	if err != nil || requests <= 0 {
+		return Limit{}, fmt.Errorf("limit %q needs a positive request count", s)
+	}
+	if per != "" && (per[0] < '0' || per[0] > '9') {
+		per = "1" + per
+	}
+	period, err := time.ParseDuration(per)
+	if err != nil || period < time.Duration(requests)*time.Millisecond {
+		return Limit{}, fmt.Errorf("limit %q has an invalid period", s)
+	}
+	return Limit{Requests: requests, Period: period}, nil
+}
+
+// Match returns the limit for a request and the bucket it is counted in.
+// Route rules get a bucket of their own so that a tight limit on one
+// endpoint does not eat into the caller's general budget.
+func (r *Rules) Match(method, path, plan string) (Limit, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, rule := range r.Routes {
+		if rule.Route.Match(method, path) {
+			return rule.Limit, rule.Route.String()
+		}
+	}
+	if limit, ok := r.Plans[plan]; ok {
+		return limit, "plan:" + plan
+	}
+	return r.Default, "default"
+}
+
+// PerIP returns the limit on all requests from one address, if there is
+// one.
+func (r *Rules) PerIP() (Limit, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.IP, r.IP.Requests > 0
+}
+
+// Update replaces the rules with next, which is left unused, when the
+// configuration is reloaded.
+func (r *Rules) Update(next *Rules) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Default, r.Plans, r.Routes, r.IP = next.Default, next.Plans, next.Routes, next.IP
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-product-api/pkg/config"
+)
+
+func TestParseLimit(t *testing.T) {
+	l, err := ParseLimit("20/1m")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 20, Period: time.Minute}, l)
+
+	l, err = ParseLimit("5/s")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 5, Period: time.Second}, l)
+
+	for _, bad := range []string{"", "20", "0/1m", "x/1m", "20/forever", "5000/1s"} {
+		_, err := ParseLimit(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRulesMatch(t *testing.T) {
+	r, err := NewRules(&config.Config{
+		RateLimitDefault: "20/1m",
+		RateLimitPlans:   "pro=200/1m, free=10/1m",
+		RateLimitRoutes:  "POST /api/v1/products=5/1m, GET /api/v1/products/:id/barcode=2/1s",
+	})
+	require.NoError(t, err)
+
+	limit, bucket := r.Match("POST", "/api/v1/products", "pro")
+	assert.Equal(t, 5, limit.Requests)
+	assert.Equal(t, "POST /api/v1/products", bucket)
+
+	limit, _ = r.Match("GET", "/api/v1/products/abc/barcode", "")
+	assert.Equal(t, 2, limit.Requests)
+
+	limit, bucket = r.Match("GET", "/api/v1/products/abc", "pro")
+	assert.Equal(t, 200, limit.Requests)
+	assert.Equal(t, "plan:pro", bucket)
+
+	limit, bucket = r.Match("GET", "/api/v1/products/abc", "unknown")
+	assert.Equal(t, 20, limit.Requests)
+	assert.Equal(t, "default", bucket)
+}
+
+func TestNewRulesRejectsBadConfig(t *testing.T) {
+	_, err := NewRules(&config.Config{RateLimitDefault: "20/1m", RateLimitRoutes: "/api/v1/products=5/1m"})
+	assert.Error(t, err)
+
+	_, err = NewRules(&config.Config{RateLimitDefault: "lots"})
+	assert.Error(t, err)
+}
+
+func TestRulesUpdate(t *testing.T) {
+	r, err := NewRules(&config.Config{RateLimitDefault: "20/1m", RateLimitPlans: "pro=200/1m"})
+	require.NoError(t, err)
+	next, err := NewRules(&config.Config{RateLimitDefault: "50/1m"})
+	require.NoError(t, err)
+
+	r.Update(next)
+
+	limit, bucket := r.Match("GET", "/api/v1/products", "pro")
+	assert.Equal(t, 50, limit.Requests)
+	assert.Equal(t, "default", bucket)
+}
+
+func TestRulesPerIP(t *testing.T) {
+	r, err := NewRules(&config.Config{RateLimitDefault: "20/1m"})
+	require.NoError(t, err)
+	_, ok := r.PerIP()
+	assert.False(t, ok)
+
+	next, err := NewRules(&config.Config{RateLimitDefault: "20/1m", RateLimitIP: "600/1m"})
+	require.NoError(t, err)
+	r.Update(next)
+	limit, ok := r.PerIP()
+	assert.True(t, ok)
+	assert.Equal(t, Limit{Requests: 600, Period: time.Minute}, limit)
+
+	_, err = NewRules(&config.Config{RateLimitDefault: "20/1m", RateLimitIP: "lots"})
+	assert.ErrorContains(t, err, "RATE_LIMIT_IP")
+}
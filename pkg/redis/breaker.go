@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"simple-product-api/pkg/breaker"
+	"simple-product-api/pkg/retry"
+)
+
+// breakerHook fails commands with breaker.ErrOpen while Redis is known to be
+// down or too slow to answer. It is added before retryHook so that a
+// command and its retries count as one call.
+type breakerHook struct {
+	breaker *breaker.Breaker
+}
+
+func (h breakerHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h breakerHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := h.breaker.Do(retry.Outage(ctx, retry.Redis), func() error {
+			return next(ctx, cmd)
+		})
+		if err == breaker.ErrOpen {
+			cmd.SetErr(err)
+		}
+		return err
+	}
+}
+
+func (h breakerHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := h.breaker.Do(retry.Outage(ctx, retry.Redis), func() error {
+			return next(ctx, cmds)
+		})
+		if err == breaker.ErrOpen {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+		}
+		return err
+	}
+}
@@ -0,0 +1,36 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"simple-product-api/pkg/metrics"
+)
+
+// metricsHook records the latency of each command. It is added after
+// retryHook so that every attempt is timed on its own, and commands
+// rejected by the breaker are not timed at all.
+type metricsHook struct{}
+
+func (metricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (metricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		metrics.ObserveRedis(cmd.Name(), time.Since(start))
+		return err
+	}
+}
+
+func (metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		metrics.ObserveRedis("pipeline", time.Since(start))
+		return err
+	}
+}
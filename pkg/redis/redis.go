@@ -3,13 +3,33 @@ package redis
 import (
 	"context"
 	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"simple-product-api/pkg/breaker"
 	"simple-product-api/pkg/config"
+	"simple-product-api/pkg/retry"
 )
 
-func NewRedis(cfg *config.Config) *redis.Client {
-	return redis.NewClient(&redis.Options{
-		Addr: cfg.RedisAddress,
+func NewRedis(cfg *config.Config, log *logrus.Logger, breakers *breaker.Group) *redis.Client {
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddress,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+		PoolSize: cfg.RedisPoolSize,
+		// Without this, commands wait out ReadTimeout whatever the
+		// deadline of their context, and CACHE_TIMEOUT and the request
+		// timeout never cut a slow Redis short.
+		ContextTimeoutEnabled: true,
+		// Retries are done by retryHook so that they follow the shared
+		// classification and budget.
+		MaxRetries: -1,
 	})
+	settings := breaker.FromConfig(cfg, "redis")
+	settings.OnStateChange = breaker.LogTransitions(log)
+	rdb.AddHook(breakerHook{breaker: breakers.Add(breaker.New(settings))})
+	rdb.AddHook(retryHook{retrier: retry.FromConfig(cfg)})
+	rdb.AddHook(metricsHook{})
+	rdb.AddHook(tracingHook{})
+	return rdb
 }
 
 func PingRedis(rdb *redis.Client) error {
@@ -0,0 +1,36 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-product-api/pkg/breaker"
+	"simple-product-api/pkg/config"
+)
+
+func TestCommandsHonourContextDeadline(t *testing.T) {
+	m := miniredis.RunT(t)
+	rdb := NewRedis(&config.Config{RedisAddress: m.Addr(), RetryAttempts: 1, BreakerFailures: 5}, logrus.New(), breaker.NewGroup())
+	t.Cleanup(func() { rdb.Close() })
+	require.NoError(t, rdb.Ping(context.Background()).Err())
+
+	// Holding the server's lock leaves every command unanswered.
+	m.Lock()
+	defer m.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := rdb.Get(ctx, "k").Err()
+
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+	assert.Less(t, time.Since(start), time.Second, "the command gave up at the caller's deadline, not at ReadTimeout")
+}
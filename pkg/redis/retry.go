@@ -0,0 +1,43 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"simple-product-api/pkg/retry"
+)
+
+// readOnly lists the commands that may be repeated after a dropped
+// connection. Anything else may already have run, and only errors where
+// Redis refused the command are retried.
+var readOnly = map[string]bool{
+	"get": true, "mget": true, "exists": true, "ttl": true, "pttl": true,
+	"hget": true, "hgetall": true, "smembers": true, "scan": true, "ping": true,
+}
+
+// retryHook retries commands that failed with a transient error, using the
+// shared retry policy and budget instead of go-redis' built-in retries.
+type retryHook struct {
+	retrier *retry.Retrier
+}
+
+func (h retryHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h retryHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		classify := retry.RedisWrite
+		if readOnly[cmd.Name()] {
+			classify = retry.Redis
+		}
+		return h.retrier.Do(ctx, classify, func() error {
+			cmd.SetErr(nil)
+			return next(ctx, cmd)
+		})
+	}
+}
+
+func (h retryHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
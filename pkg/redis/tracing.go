@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("simple-product-api/pkg/redis")
+
+// tracingHook records a span for each command attempt, named after the
+// command. Arguments are left out; they hold keys and cached values.
+type tracingHook struct{}
+
+func (tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		op := strings.ToUpper(cmd.Name())
+		ctx, span := tracer.Start(ctx, op,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperationName(op)),
+		)
+		defer span.End()
+
+		err := next(ctx, cmd)
+		endRedisSpan(span, err)
+		return err
+	}
+}
+
+func (tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := tracer.Start(ctx, "PIPELINE",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemRedis, attribute.Int("db.redis.commands", len(cmds))),
+		)
+		defer span.End()
+
+		err := next(ctx, cmds)
+		endRedisSpan(span, err)
+		return err
+	}
+}
+
+// endRedisSpan marks span failed on errors other than a missing key.
+func endRedisSpan(span trace.Span, err error) {
+	if err != nil && err != redis.Nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,42 @@
+package retry
+
+import "sync"
+
+// Budget throttles retries the way gRPC does: every success earns ratio
+// tokens, every retry spends one, and retries stop while fewer than half
+// the tokens are left. With ratio 0.1, roughly one retry per ten
+// successful calls is allowed once a dependency starts failing.
+type Budget struct {
+	mu     sync.Mutex
+	max    float64
+	ratio  float64
+	tokens float64
+}
+
+// NewBudget returns a full budget. A nil Budget allows every retry.
+func NewBudget(maxTokens int, ratio float64) *Budget {
+	return &Budget{max: float64(maxTokens), ratio: ratio, tokens: float64(maxTokens)}
+}
+
+func (b *Budget) Deposit() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.max, b.tokens+b.ratio)
+}
+
+// Withdraw takes a token for one retry and reports whether it may go ahead.
+func (b *Budget) Withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens <= b.max/2 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,110 @@
+package retry
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+)
+
+// Postgres error codes after which the statement is known not to have
+// taken effect: the transaction was rolled back or never started.
+var pgRetryable = map[pq.ErrorCode]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"53300": true, // too_many_connections
+	"57P03": true, // cannot_connect_now
+	"08001": true, // sqlclient_unable_to_establish_sqlconnection
+	"08004": true, // sqlserver_rejected_establishment_of_sqlconnection
+}
+
+// PostgresWrite reports errors after which a write, or a whole transaction,
+// can be repeated without risk of applying it twice.
+func PostgresWrite(err error) bool {
+	if isContext(err) {
+		return false
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pgRetryable[pqErr.Code]
+	}
+	return errors.Is(err, driver.ErrBadConn) || isDial(err)
+}
+
+// PostgresRead also retries dropped connections and timeouts, which are
+// ambiguous for writes but harmless for reads.
+func PostgresRead(err error) bool {
+	if PostgresWrite(err) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code.Class() == "08" || pqErr.Code == "57P01" // connection_exception, admin_shutdown
+	}
+	return !isContext(err) && isNetwork(err)
+}
+
+// Redis reports transient failures of read commands. Cache misses and
+// command errors such as WRONGTYPE are not retried.
+func Redis(err error) bool {
+	return RedisWrite(err) || (!isContext(err) && !errors.Is(err, redis.Nil) && isNetwork(err))
+}
+
+// RedisWrite reports failures after which Redis is known not to have run
+// the command: it refused it, or the connection was never made.
+func RedisWrite(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || isContext(err) {
+		return false
+	}
+	for _, prefix := range []string{"LOADING ", "READONLY ", "CLUSTERDOWN ", "TRYAGAIN ", "MASTERDOWN "} {
+		if strings.HasPrefix(err.Error(), prefix) {
+			return true
+		}
+	}
+	return isDial(err)
+}
+
+// Outage returns the circuit breaker classifier for a call made with ctx.
+// Besides the transient errors, it counts calls that ran out of time, since
+// a dependency that stops answering is as much down as one that refuses
+// connections. Drivers report a timed out call in their own words, such as
+// "canceling statement due to user request", so the deadline is read from
+// ctx as well as err. Calls canceled by their caller say nothing about the
+// dependency and are not counted.
+func Outage(ctx context.Context, transient Classifier) Classifier {
+	return func(err error) bool {
+		if errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+			return false
+		}
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return true
+		}
+		return transient(err)
+	}
+}
+
+func isContext(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func isDial(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+func isNetwork(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || isDial(err)
+}
@@ -0,0 +1,90 @@
+package retry
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"simple-product-api/pkg/config"
+)
+
+// Classifier reports whether a failed call may be tried again.
+type Classifier func(error) bool
+
+type Policy struct {
+	// Attempts is the total number of calls, including the first.
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// Retrier retries calls to one dependency. All calls share a budget so
+// that retries cannot multiply load on a dependency that is already
+// failing. A nil Retrier calls fn exactly once.
+type Retrier struct {
+	policy Policy
+	budget *Budget
+}
+
+func New(p Policy, b *Budget) *Retrier {
+	if p.Attempts < 1 {
+		p.Attempts = 1
+	}
+	return &Retrier{policy: p, budget: b}
+}
+
+// FromConfig builds a retrier with its own budget from the RETRY_* settings.
+func FromConfig(cfg *config.Config) *Retrier {
+	return New(Policy{
+		Attempts:  cfg.RetryAttempts,
+		BaseDelay: cfg.RetryBaseDelay,
+		MaxDelay:  cfg.RetryMaxDelay,
+	}, NewBudget(cfg.RetryBudgetTokens, cfg.RetryBudgetRatio))
+}
+
+// Do calls fn until it succeeds, fails with an error classify rejects, the
+// attempts or budget run out, or ctx is done. The last error is returned.
+func (r *Retrier) Do(ctx context.Context, classify Classifier, fn func() error) error {
+	if r == nil {
+		return fn()
+	}
+
+	var err error
+	for attempt := 0; attempt < r.policy.Attempts; attempt++ {
+		if attempt > 0 {
+			if !r.budget.Withdraw() {
+				return err
+			}
+			t := time.NewTimer(r.backoff(attempt))
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return err
+			case <-t.C:
+			}
+		}
+
+		err = fn()
+		if err == nil {
+			r.budget.Deposit()
+			return nil
+		}
+		if ctx.Err() != nil || !classify(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// backoff is "full jitter": a random delay up to an exponentially growing
+// cap, which spreads out retries from many clients failing at once.
+func (r *Retrier) backoff(attempt int) time.Duration {
+	ceiling := r.policy.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > r.policy.MaxDelay {
+		ceiling = r.policy.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
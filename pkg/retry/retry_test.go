@@ -0,0 +1,146 @@
+package retry
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTransient = errors.New("transient")
+
+func transient(err error) bool { return errors.Is(err, errTransient) }
+
+func TestDoRetriesTransientErrors(t *testing.T) {
+	r := New(Policy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, nil)
+
+	calls := 0
+	err := r.Do(context.Background(), transient, func() error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestDoStopsOnPermanentError(t *testing.T) {
+	r := New(Policy{Attempts: 3}, nil)
+	permanent := errors.New("permanent")
+
+	calls := 0
+	err := r.Do(context.Background(), transient, func() error {
+		calls++
+		return permanent
+	})
+	assert.ErrorIs(t, err, permanent)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDoGivesUpAfterAttempts(t *testing.T) {
+	r := New(Policy{Attempts: 2}, nil)
+
+	calls := 0
+	err := r.Do(context.Background(), transient, func() error {
+		calls++
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 2, calls)
+}
+
+func TestDoStopsWhenContextIsDone(t *testing.T) {
+	r := New(Policy{Attempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	err := r.Do(ctx, transient, func() error {
+		calls++
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 1, calls)
+}
+
+func TestNilRetrierCallsOnce(t *testing.T) {
+	var r *Retrier
+
+	calls := 0
+	err := r.Do(context.Background(), transient, func() error {
+		calls++
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 1, calls)
+}
+
+func TestBudgetLimitsRetries(t *testing.T) {
+	b := NewBudget(4, 0.5)
+	r := New(Policy{Attempts: 2}, b)
+
+	calls := 0
+	for i := 0; i < 5; i++ {
+		_ = r.Do(context.Background(), transient, func() error {
+			calls++
+			return errTransient
+		})
+	}
+	// Two retries take the budget from 4 to its floor of 2.
+	assert.Equal(t, 5+2, calls)
+
+	b.Deposit()
+	assert.True(t, b.Withdraw(), "successes earn retries back")
+	assert.False(t, b.Withdraw())
+}
+
+func TestClassifiers(t *testing.T) {
+	serialization := &pq.Error{Code: "40001"}
+	unique := &pq.Error{Code: "23505"}
+	shutdown := &pq.Error{Code: "57P01"}
+
+	assert.True(t, PostgresWrite(serialization))
+	assert.True(t, PostgresWrite(driver.ErrBadConn))
+	assert.False(t, PostgresWrite(unique))
+	assert.False(t, PostgresWrite(shutdown), "the statement may have run")
+	assert.False(t, PostgresWrite(io.ErrUnexpectedEOF))
+	assert.False(t, PostgresWrite(context.DeadlineExceeded))
+
+	assert.True(t, PostgresRead(shutdown))
+	assert.True(t, PostgresRead(io.ErrUnexpectedEOF))
+	assert.False(t, PostgresRead(unique))
+
+	assert.True(t, Redis(errors.New("LOADING Redis is loading the dataset in memory")))
+	assert.True(t, Redis(io.EOF))
+	assert.False(t, Redis(redis.Nil))
+	assert.False(t, Redis(errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")))
+	assert.False(t, Redis(context.Canceled))
+	assert.True(t, RedisWrite(errors.New("READONLY You can't write against a read only replica.")))
+	assert.False(t, RedisWrite(io.EOF), "the command may have run")
+}
+
+func TestOutage(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, Outage(ctx, PostgresRead)(&pq.Error{Code: "57P01"}))
+	assert.True(t, Outage(ctx, PostgresRead)(context.DeadlineExceeded))
+	assert.False(t, Outage(ctx, PostgresRead)(context.Canceled))
+	assert.False(t, Outage(ctx, PostgresRead)(&pq.Error{Code: "23505"}))
+
+	// Drivers report a timed out statement as a cancellation of their own.
+	canceledStatement := &pq.Error{Code: "57014"}
+	expired, cancel := context.WithDeadline(ctx, time.Now())
+	defer cancel()
+	assert.True(t, Outage(expired, PostgresRead)(canceledStatement))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, Outage(canceled, PostgresRead)(canceledStatement))
+}
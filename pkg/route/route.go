@@ -0,0 +1,58 @@
+package route
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pattern matches requests by method and path. Path segments starting with
+// ':' match any single segment and a trailing '*' matches the rest.
+type Pattern struct {
+	Method string
+	Path   string
+}
+
+// Parse reads a pattern written as "METHOD /path".
+func Parse(s string) (Pattern, error) {
+	method, path, ok := strings.Cut(strings.TrimSpace(s), " ")
+	path = strings.TrimSpace(path)
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		return Pattern{}, fmt.Errorf("route %q is not \"METHOD /path\"", s)
+	}
+	return Pattern{Method: strings.ToUpper(method), Path: path}, nil
+}
+
+func (p Pattern) String() string {
+	return p.Method + " " + p.Path
+}
+
+func (p Pattern) Match(method, path string) bool {
+	if p.Method != method {
+		return false
+	}
+	ps := strings.Split(strings.Trim(p.Path, "/"), "/")
+	xs := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range ps {
+		if seg == "*" && i == len(ps)-1 {
+			return true
+		}
+		if i >= len(xs) {
+			return false
+		}
+		if !strings.HasPrefix(seg, ":") && seg != xs[i] {
+			return false
+		}
+	}
+	return len(ps) == len(xs)
+}
+
+// SplitList splits a comma separated config value, dropping blanks.
+func SplitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,29 @@
+package route
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	must := func(s string) Pattern {
+		p, err := Parse(s)
+		require.NoError(t, err)
+		return p
+	}
+
+	assert.True(t, must("GET /api/v1/products/*").Match("GET", "/api/v1/products/1/status"))
+	assert.True(t, must("get /api/v1/products/:id").Match("GET", "/api/v1/products/1"))
+	assert.False(t, must("GET /api/v1/products/:id").Match("GET", "/api/v1/products/1/status"))
+	assert.False(t, must("GET /api/v1/products/:id").Match("POST", "/api/v1/products/1"))
+	assert.False(t, must("GET /api/v1/products").Match("GET", "/api/v1/orders"))
+}
+
+func TestParse(t *testing.T) {
+	for _, bad := range []string{"", "/api/v1/products", "GET api"} {
+		_, err := Parse(bad)
+		assert.Error(t, err, bad)
+	}
+}
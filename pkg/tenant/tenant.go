@@ -0,0 +1,31 @@
+package tenant
+
+import (
+	"context"
+	"regexp"
+)
+
+// Default is the tenant that owns rows created before catalogs were split
+// per store, and the one used when a request names no tenant.
+const Default = "default"
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Valid reports whether id is a well formed tenant ID.
+func Valid(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+type tenantKey struct{}
+
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext returns the tenant of ctx, or Default when none was set.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(tenantKey{}).(string); ok && id != "" {
+		return id
+	}
+	return Default
+}
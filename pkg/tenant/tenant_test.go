@@ -0,0 +1,22 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, Default, FromContext(context.Background()))
+	assert.Equal(t, "store-1", FromContext(WithID(context.Background(), "store-1")))
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid("store-1"))
+	assert.True(t, Valid("default"))
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("Store 1"))
+	assert.False(t, Valid("-store"))
+	assert.False(t, Valid("store:1"))
+}
@@ -0,0 +1,28 @@
+package tracing
+
+import (
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogHook adds the trace_id and span_id of the span in an entry's context
+// to the entry, so that log lines written with WithContext can be matched
+// to their trace.
+type LogHook struct{}
+
+func (LogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (LogHook) Fire(e *logrus.Entry) error {
+	if e.Context == nil {
+		return nil
+	}
+	sc := trace.SpanContextFromContext(e.Context)
+	if !sc.IsValid() {
+		return nil
+	}
+	e.Data["trace_id"] = sc.TraceID().String()
+	e.Data["span_id"] = sc.SpanID().String()
+	return nil
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	tracer = otel.Tracer("simple-product-api/pkg/tracing")
+
+	sqlString = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// sqlNumber matches numbers that are not part of a name or a $n
+	// placeholder, keeping the character before them in group 1.
+	sqlNumber = regexp.MustCompile(`(^|[^\w$.])\d+(?:\.\d+)?`)
+)
+
+// Sanitize returns query with its string and number literals replaced by
+// "?" and its whitespace collapsed, so that it can be recorded without the
+// values that were built into it.
+func Sanitize(query string) string {
+	query = sqlString.ReplaceAllString(query, "?")
+	query = sqlNumber.ReplaceAllString(query, "${1}?")
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// Query starts a span for one SQL statement, named after its operation,
+// such as SELECT, and recording the sanitized statement.
+func Query(ctx context.Context, query string) (context.Context, trace.Span) {
+	text := Sanitize(query)
+	op, _, _ := strings.Cut(text, " ")
+	op = strings.ToUpper(op)
+	return tracer.Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(op), semconv.DBQueryText(text)),
+	)
+}
+
+// End ends span, marking it failed when err is an error other than
+// sql.ErrNoRows.
+func End(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
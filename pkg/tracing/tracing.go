@@ -0,0 +1,108 @@
+// Package tracing sets up OpenTelemetry tracing: W3C traceparent
+// propagation, the exporter named by TRACE_EXPORTER, and helpers for the
+// spans the service records around SQL statements.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"simple-product-api/pkg/config"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// shutdownTimeout bounds how long Close waits for spans to be exported.
+const shutdownTimeout = 5 * time.Second
+
+// Propagator reads and writes W3C traceparent and baggage headers. It is
+// installed globally even with tracing off, so that the trace IDs of
+// callers still reach the logs.
+var Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+func init() {
+	otel.SetTextMapPropagator(Propagator)
+}
+
+// Provider exports the spans recorded by the service.
+type Provider struct {
+	tp   *sdktrace.TracerProvider
+	file io.Closer
+}
+
+// New installs the exporter named by TRACE_EXPORTER as the global tracer
+// provider and returns nil, which records nothing, when it is empty.
+func New(cfg *config.Config, log *logrus.Logger) (*Provider, error) {
+	if cfg.TraceExporter == "" {
+		return nil, nil
+	}
+
+	p := &Provider{}
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.TraceExporter {
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(cfg.TraceEndpoint))
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		f, ferr := os.OpenFile(cfg.TraceFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if ferr != nil {
+			return nil, fmt.Errorf("TRACE_FILE: %w", ferr)
+		}
+		p.file = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("TRACE_EXPORTER: unknown exporter %q", cfg.TraceExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("TRACE_EXPORTER: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.TraceServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	p.tp = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TraceSampleRatio))),
+	)
+	otel.SetTracerProvider(p.tp)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.WithError(err).Warn("failed to export traces")
+	}))
+	log.WithField("exporter", cfg.TraceExporter).Info("tracing enabled")
+	return p, nil
+}
+
+// Close exports the spans still buffered.
+func (p *Provider) Close() error {
+	if p == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := p.tp.Shutdown(ctx)
+	if p.file != nil {
+		if cerr := p.file.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSanitizeDropsLiterals(t *testing.T) {
+	q := `SELECT id, name FROM products
+	      WHERE tenant_id = $1 AND status = 'active' AND name = 'O''Brien'
+	      ORDER BY created_at DESC LIMIT 10 OFFSET 20`
+
+	assert.Equal(t,
+		`SELECT id, name FROM products WHERE tenant_id = $1 AND status = ? AND name = ? ORDER BY created_at DESC LIMIT ? OFFSET ?`,
+		Sanitize(q))
+	assert.Equal(t, `UPDATE t2 SET price = ?, v = $12 WHERE x = ?`, Sanitize(`UPDATE t2 SET price = 12.5, v = $12 WHERE x = 3`))
+}
+
+func TestLogHookAddsTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&buf)
+	log.AddHook(LogHook{})
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	})
+	log.WithContext(trace.ContextWithSpanContext(context.Background(), sc)).Info("traced")
+	log.WithContext(context.Background()).Info("untraced")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), "trace_id=4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Contains(t, string(lines[0]), "span_id=00f067aa0ba902b7")
+	assert.NotContains(t, string(lines[1]), "trace_id")
+}
@@ -1,5 +1,16 @@
 package validator
 
-import validator "github.com/go-playground/validator/v10"
+import (
+	validator "github.com/go-playground/validator/v10"
+	"simple-product-api/pkg/barcode"
+)
 
-var Validate = validator.New()
+var Validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	_ = v.RegisterValidation("gtin", func(fl validator.FieldLevel) bool {
+		return barcode.IsValidGTIN(barcode.Normalize(fl.Field().String()))
+	})
+	return v
+}